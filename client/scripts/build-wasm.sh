@@ -0,0 +1,14 @@
+#!/bin/sh
+# Builds the Go rules engine for the browser into public/
+set -e
+
+cd "$(dirname "$0")/../../server"
+GOOS=js GOARCH=wasm go build -o ../client/public/rules.wasm ./cmd/wasm
+
+# wasm_exec.js moved from misc/wasm to lib/wasm in Go 1.24
+GOROOT="$(go env GOROOT)"
+if [ -f "$GOROOT/lib/wasm/wasm_exec.js" ]; then
+  cp "$GOROOT/lib/wasm/wasm_exec.js" ../client/public/
+else
+  cp "$GOROOT/misc/wasm/wasm_exec.js" ../client/public/
+fi
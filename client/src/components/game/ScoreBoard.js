@@ -8,7 +8,8 @@ import PropTypes from 'prop-types';
 function ScoreBoard({ roundResult, onNextRound, isHost }) {
   if (!roundResult) return null;
 
-  const { winner, players, roundNumber } = roundResult;
+  const { winner, players, roundNumber, scoring, reason, history = [] } = roundResult;
+  const winnerId = winner?.id;
 
   // Sort players by total score (ascending - lowest wins)
   const sortedPlayers = [...players].sort((a, b) => a.totalScore - b.totalScore);
@@ -21,9 +22,22 @@ function ScoreBoard({ roundResult, onNextRound, isHost }) {
           <h2 className="text-3xl font-bold text-gray-800 mb-2">
             Round {roundNumber} Complete!
           </h2>
-          <p className="text-xl text-green-600 font-semibold">
-            {winner.name} wins the round! 🎉
-          </p>
+          {winner ? (
+            <p className="text-xl text-green-600 font-semibold">
+              {winner.name} wins the round! 🎉
+            </p>
+          ) : (
+            <p className="text-xl text-amber-600 font-semibold">
+              {reason === 'repeated'
+                ? 'The table kept repeating itself, so the round was scored as it stands'
+                : 'No progress was being made, so the round was scored as it stands'}
+            </p>
+          )}
+          {scoring && (
+            <p className="text-sm text-gray-500 mt-1">
+              Scored with the {scoring.name} profile
+            </p>
+          )}
         </div>
 
         {/* Scores Table */}
@@ -41,13 +55,13 @@ function ScoreBoard({ roundResult, onNextRound, isHost }) {
                 <tr
                   key={player.id}
                   className={`border-b border-gray-200 ${
-                    player.id === winner.id ? 'bg-green-50' : ''
+                    player.id === winnerId ? 'bg-green-50' : ''
                   } ${index === 0 ? 'font-semibold' : ''}`}
                 >
                   <td className="py-3 px-4">
                     <div className="flex items-center gap-2">
                       {player.name}
-                      {player.id === winner.id && (
+                      {player.id === winnerId && (
                         <span className="text-yellow-500">👑</span>
                       )}
                       {index === 0 && roundNumber > 1 && (
@@ -55,11 +69,23 @@ function ScoreBoard({ roundResult, onNextRound, isHost }) {
                           Leading
                         </span>
                       )}
+                      {player.forfeited && (
+                        <span className="text-xs bg-gray-100 text-gray-600 px-2 py-1 rounded">
+                          Forfeited
+                        </span>
+                      )}
                     </div>
+                    {player.id !== winnerId && player.handPoints !== undefined && (
+                      <div className="text-xs text-gray-500 mt-1">
+                        Hand {player.handPoints} · Up {player.faceUpPoints} · Down{' '}
+                        {player.faceDownPoints}
+                        {player.tensPenalty > 0 && ` · Tens ${player.tensPenalty}`}
+                      </div>
+                    )}
                   </td>
                   <td className="text-right py-3 px-4">
-                    {player.id === winner.id ? (
-                      <span className="text-green-600 font-semibold">0</span>
+                    {player.id === winnerId ? (
+                      <span className="text-green-600 font-semibold">{player.roundScore}</span>
                     ) : (
                       <span className="text-gray-600">{player.roundScore}</span>
                     )}
@@ -73,6 +99,37 @@ function ScoreBoard({ roundResult, onNextRound, isHost }) {
           </table>
         </div>
 
+        {/* Score Sheet */}
+        {history.length > 1 && (
+          <div className="mb-6 overflow-x-auto">
+            <h3 className="text-sm font-semibold text-gray-700 mb-2">Score Sheet</h3>
+            <table className="w-full text-sm">
+              <thead>
+                <tr className="border-b border-gray-300">
+                  <th className="text-left py-1 px-2 text-gray-600">Player</th>
+                  {history.map((entry) => (
+                    <th key={entry.round} className="text-right py-1 px-2 text-gray-600">
+                      R{entry.round}
+                    </th>
+                  ))}
+                </tr>
+              </thead>
+              <tbody>
+                {sortedPlayers.map((player) => (
+                  <tr key={player.id} className="border-b border-gray-100">
+                    <td className="py-1 px-2">{player.name}</td>
+                    {history.map((entry) => (
+                      <td key={entry.round} className="text-right py-1 px-2 text-gray-600">
+                        {entry.scores?.[player.id]?.roundScore ?? '–'}
+                      </td>
+                    ))}
+                  </tr>
+                ))}
+              </tbody>
+            </table>
+          </div>
+        )}
+
         {/* Info Text */}
         <div className="text-center text-sm text-gray-600 mb-6">
           <p>Lowest total score wins the game</p>
@@ -106,16 +163,31 @@ ScoreBoard.propTypes = {
     winner: PropTypes.shape({
       id: PropTypes.string.isRequired,
       name: PropTypes.string.isRequired,
-    }).isRequired,
+    }),
+    reason: PropTypes.string,
     players: PropTypes.arrayOf(
       PropTypes.shape({
         id: PropTypes.string.isRequired,
         name: PropTypes.string.isRequired,
         roundScore: PropTypes.number.isRequired,
         totalScore: PropTypes.number.isRequired,
+        handPoints: PropTypes.number,
+        faceUpPoints: PropTypes.number,
+        faceDownPoints: PropTypes.number,
+        tensPenalty: PropTypes.number,
+        forfeited: PropTypes.bool,
       })
     ).isRequired,
     roundNumber: PropTypes.number.isRequired,
+    scoring: PropTypes.shape({
+      name: PropTypes.string.isRequired,
+    }),
+    history: PropTypes.arrayOf(
+      PropTypes.shape({
+        round: PropTypes.number.isRequired,
+        scores: PropTypes.object,
+      })
+    ),
   }),
   onNextRound: PropTypes.func.isRequired,
   isHost: PropTypes.bool.isRequired,
@@ -98,4 +98,43 @@ describe('ScoreBoard', () => {
     // Alice should have the "Leading" badge since she has lowest score and it's round 2
     expect(screen.getByText('Leading')).toBeInTheDocument();
   });
+
+  it('shows the score breakdown, scoring profile and score sheet', () => {
+    const detailed = {
+      ...mockRoundResult,
+      players: [
+        { id: 'p1', name: 'Alice', roundScore: 0, totalScore: 15 },
+        {
+          id: 'p2',
+          name: 'Bob',
+          roundScore: 23,
+          totalScore: 45,
+          handPoints: 20,
+          faceUpPoints: 3,
+          faceDownPoints: 0,
+          tensPenalty: 20,
+        },
+      ],
+      scoring: { name: 'classic' },
+      history: [
+        { round: 1, scores: { p1: { roundScore: 15 }, p2: { roundScore: 22 } } },
+        { round: 2, scores: { p1: { roundScore: 0 }, p2: { roundScore: 23 } } },
+      ],
+    };
+    render(<ScoreBoard roundResult={detailed} onNextRound={mockOnNextRound} isHost={true} />);
+
+    expect(screen.getByText(/Hand 20 · Up 3 · Down 0 · Tens 20/)).toBeInTheDocument();
+    expect(screen.getByText(/Scored with the classic profile/)).toBeInTheDocument();
+    expect(screen.getByText('Score Sheet')).toBeInTheDocument();
+    expect(screen.getByText('R1')).toBeInTheDocument();
+    expect(screen.getByText('22')).toBeInTheDocument();
+  });
+
+  it('explains a round that ended in a stalemate', () => {
+    const stalemate = { ...mockRoundResult, winner: null, reason: 'stalemate' };
+    render(<ScoreBoard roundResult={stalemate} onNextRound={mockOnNextRound} isHost={true} />);
+
+    expect(screen.getByText(/No progress was being made/i)).toBeInTheDocument();
+    expect(screen.queryByText(/wins the round/i)).not.toBeInTheDocument();
+  });
 });
@@ -1,10 +1,12 @@
 import React from 'react';
+import { GAME_CONFIG } from '../../utils/constants';
 
 function Lobby({ room, playerId, isHost, onStartGame, onLeaveRoom }) {
   if (!room) return null;
 
   const playerCount = room.players?.length || 0;
-  const canStartGame = isHost && playerCount >= 3 && playerCount <= 10;
+  const canStartGame =
+    isHost && playerCount >= GAME_CONFIG.MIN_PLAYERS && playerCount <= GAME_CONFIG.MAX_PLAYERS;
 
   return (
     <div className="max-w-2xl mx-auto p-6 bg-white rounded-lg shadow-lg">
@@ -28,7 +30,7 @@ function Lobby({ room, playerId, isHost, onStartGame, onLeaveRoom }) {
 
       <div className="mb-6">
         <h3 className="text-xl font-semibold text-gray-800 mb-3">
-          Players ({playerCount}/10)
+          Players ({playerCount}/{GAME_CONFIG.MAX_PLAYERS})
         </h3>
         <ul className="space-y-2">
           {room.players?.map((player) => (
@@ -56,9 +58,9 @@ function Lobby({ room, playerId, isHost, onStartGame, onLeaveRoom }) {
         </ul>
       </div>
 
-      {playerCount < 3 && (
+      {playerCount < GAME_CONFIG.MIN_PLAYERS && (
         <div className="mb-4 p-3 bg-yellow-100 border border-yellow-400 text-yellow-800 rounded">
-          Waiting for more players... (Need at least 3 players to start)
+          Waiting for more players... (Need at least {GAME_CONFIG.MIN_PLAYERS} players to start)
         </div>
       )}
 
@@ -46,7 +46,7 @@ describe('Lobby Component', () => {
     expect(screen.getByText('Host Player')).toBeInTheDocument();
     expect(screen.getByText('Player 2')).toBeInTheDocument();
     expect(screen.getByText('Player 3')).toBeInTheDocument();
-    expect(screen.getByText('Players (3/10)')).toBeInTheDocument();
+    expect(screen.getByText('Players (3/16)')).toBeInTheDocument();
   });
 
   test('highlights current player', () => {
@@ -94,13 +94,10 @@ describe('Lobby Component', () => {
     expect(button).not.toBeDisabled();
   });
 
-  test('disables start game button when less than 3 players', () => {
+  test('disables start game button when less than 2 players', () => {
     const smallRoom = {
       ...mockRoom,
-      players: [
-        { id: 'player-1', name: 'Host Player' },
-        { id: 'player-2', name: 'Player 2' },
-      ],
+      players: [{ id: 'player-1', name: 'Host Player' }],
     };
 
     render(
@@ -115,7 +112,29 @@ describe('Lobby Component', () => {
     
     const button = screen.getByRole('button', { name: /Waiting for players.../i });
     expect(button).toBeDisabled();
-    expect(screen.getByText(/Need at least 3 players to start/i)).toBeInTheDocument();
+    expect(screen.getByText(/Need at least 2 players to start/i)).toBeInTheDocument();
+  });
+
+  test('enables start game button for a two-player game', () => {
+    const twoPlayerRoom = {
+      ...mockRoom,
+      players: [
+        { id: 'player-1', name: 'Host Player' },
+        { id: 'player-2', name: 'Player 2' },
+      ],
+    };
+
+    render(
+      <Lobby
+        room={twoPlayerRoom}
+        playerId="player-1"
+        isHost={true}
+        onStartGame={jest.fn()}
+        onLeaveRoom={jest.fn()}
+      />
+    );
+
+    expect(screen.getByRole('button', { name: /Start Game/i })).not.toBeDisabled();
   });
 
   test('shows waiting message for non-host players', () => {
@@ -1,8 +1,9 @@
 import { useState, useCallback, useMemo, useEffect } from 'react';
 import useWebSocket from './useWebSocket';
 import { canFlipFaceDown } from '../utils/gameLogic';
+import { getWebSocketUrl } from '../utils/runtimeConfig';
 
-const WS_URL = process.env.REACT_APP_WS_URL || 'ws://localhost:8080/ws';
+const WS_URL = getWebSocketUrl();
 
 const normalizePlayer = (player) => ({
   id: player?.id || player?.ID || '',
@@ -83,8 +84,11 @@ function useGameState() {
           ...prev,
           roundResult: {
             winner: data.winner,
+            reason: data.reason,
             players: data.scores,
             roundNumber: data.round,
+            scoring: data.scoring,
+            history: data.history || [],
           },
           game: data.game,
         }));
@@ -98,6 +102,32 @@ function useGameState() {
         }));
         break;
 
+      case 'ROOM_CLOSED':
+        setGameState((prev) => ({
+          ...prev,
+          playerId: null,
+          roomCode: null,
+          room: null,
+          game: null,
+          isHost: false,
+          gameStarted: false,
+          roundResult: null,
+          error:
+            data.reason === 'idle'
+              ? 'The room was closed after being idle for too long'
+              : 'The room has been closed',
+        }));
+        break;
+
+      case 'SERVER_SHUTTING_DOWN':
+        setGameState((prev) => ({
+          ...prev,
+          error: data.saving
+            ? `The server is restarting in ${Math.round(data.seconds)} seconds. Your game will be saved; rejoin once it is back.`
+            : `The server is restarting in ${Math.round(data.seconds)} seconds.`,
+        }));
+        break;
+
       case 'ERROR':
         console.error('Server error received:', data.message);
         setGameState((prev) => ({
@@ -111,6 +111,7 @@ describe('useGameState', () => {
         { id: 'p2', roundScore: 10 },
       ],
       roundNumber: 2,
+      history: [],
     });
     expect(result.current.game).toEqual({ round: 2 });
 
@@ -120,6 +121,18 @@ describe('useGameState', () => {
     expect(result.current.game).toEqual({ round: 3 });
   });
 
+  test('warns about a server shutdown', () => {
+    const { result } = renderHook(() => useGameState());
+
+    openSocket();
+
+    sendServerMessage({ type: 'SERVER_SHUTTING_DOWN', seconds: 10, saving: true });
+
+    expect(result.current.error).toBe(
+      'The server is restarting in 10 seconds. Your game will be saved; rejoin once it is back.'
+    );
+  });
+
   test('derives turn state and player-facing fields from game updates', async () => {
     const { result } = renderHook(() => useGameState());
 
@@ -2,6 +2,10 @@ import React from 'react';
 import ReactDOM from 'react-dom/client';
 import './index.css';
 import App from './App';
+import { loadRulesEngine } from './utils/rulesEngine';
+
+// Start loading the server's rules engine; previews use the JavaScript rules until it is ready
+loadRulesEngine();
 
 const root = ReactDOM.createRoot(document.getElementById('root'));
 root.render(
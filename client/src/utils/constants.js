@@ -70,8 +70,8 @@ export const CARD_POINTS = {
 
 // Game configuration
 export const GAME_CONFIG = {
-  MIN_PLAYERS: 3,
-  MAX_PLAYERS: 10,
+  MIN_PLAYERS: 2,
+  MAX_PLAYERS: 16,
   HAND_SIZE: 12,
   TABLE_CARDS_UP: 4,
   TABLE_CARDS_DOWN: 4,
@@ -80,6 +80,7 @@ export const GAME_CONFIG = {
 
 // Deck configuration based on player count
 export const DECK_CONFIG = {
+  2: 1,  // 2 players = 1 deck (two-player variant)
   3: 2,  // 3-5 players = 2 decks
   4: 2,
   5: 2,
@@ -88,6 +89,13 @@ export const DECK_CONFIG = {
   8: 4,  // 8-10 players = 4 decks
   9: 4,
   10: 4,
+  // 11-16 players use the large-table variant (4/4/8 deal) with 5-6 decks
+  11: 5,
+  12: 5,
+  13: 5,
+  14: 6,
+  15: 6,
+  16: 6,
 };
 
 // WebSocket message types
@@ -0,0 +1,110 @@
+/**
+ * Bridge to the server's rules engine compiled to WebAssembly
+ *
+ * Build it with `npm run build:wasm`. Until it has loaded (or when it is missing)
+ * previews fall back to the JavaScript rules in gameLogic.js.
+ */
+
+import { isValidPlay, calculatePoints } from './gameLogic';
+
+let loading = null;
+
+/**
+ * Get the loaded engine, if any
+ * @returns {Object|null} The clearTheDeck engine exposed by the WebAssembly module
+ */
+export const getRulesEngine = () => {
+  if (typeof window === 'undefined' || !window.clearTheDeck) return null;
+  return window.clearTheDeck;
+};
+
+const loadScript = (src) =>
+  new Promise((resolve, reject) => {
+    const script = document.createElement('script');
+    script.src = src;
+    script.onload = resolve;
+    script.onerror = () => reject(new Error(`Failed to load ${src}`));
+    document.head.appendChild(script);
+  });
+
+/**
+ * Load the WebAssembly rules engine once
+ * @param {Object} options - Where to find rules.wasm and Go's wasm_exec.js
+ * @returns {Promise<Object|null>} The engine, or null when it cannot be loaded
+ */
+export const loadRulesEngine = ({ wasmUrl = '/rules.wasm', execUrl = '/wasm_exec.js' } = {}) => {
+  if (getRulesEngine()) return Promise.resolve(getRulesEngine());
+  if (loading) return loading;
+
+  if (typeof WebAssembly === 'undefined' || typeof fetch === 'undefined') {
+    return Promise.resolve(null);
+  }
+
+  loading = (window.Go ? Promise.resolve() : loadScript(execUrl))
+    .then(() => {
+      const go = new window.Go();
+      return WebAssembly.instantiateStreaming(fetch(wasmUrl), go.importObject).then(({ instance }) => {
+        go.run(instance);
+        return getRulesEngine();
+      });
+    })
+    .catch(() => {
+      loading = null;
+      return null;
+    });
+  return loading;
+};
+
+/**
+ * Preview a play with the server's rules
+ * @param {Object} game - Game state as received in GAME_UPDATE
+ * @param {string} playerId - Player making the play
+ * @param {Array} cards - Cards to play
+ * @param {boolean} afterPickup - Whether this is after picking up the pile
+ * @returns {Object} { valid, reason, events } where events describe what the play would do
+ */
+export const previewPlay = (game, playerId, cards, afterPickup = false) => {
+  const engine = getRulesEngine();
+  if (!engine || !game) {
+    const { valid, reason } = isValidPlay(cards, game ? game.centerPile : [], afterPickup);
+    return { valid, reason, events: [] };
+  }
+
+  const result = engine.validatePlay(game, playerId, (cards || []).map((card) => card.id), afterPickup);
+  if (result.error) {
+    return { valid: false, reason: result.error, events: [] };
+  }
+  return { valid: result.valid, reason: result.reason || '', events: result.events || [] };
+};
+
+/**
+ * List the moves a player could make now
+ * @param {Object} game - Game state as received in GAME_UPDATE
+ * @param {string} playerId - Player to list moves for
+ * @returns {Array|null} Moves as { type, cardIds }, or null without the engine
+ */
+export const legalMoves = (game, playerId) => {
+  const engine = getRulesEngine();
+  if (!engine || !game) return null;
+
+  const result = engine.legalMoves(game, playerId);
+  return result.error ? null : result.moves;
+};
+
+/**
+ * Score a player's remaining cards with the game's scoring profile
+ * @param {Object} game - Game state as received in GAME_UPDATE
+ * @param {string} playerId - Player to score
+ * @returns {number} Points the player's cards are worth
+ */
+export const scorePlayer = (game, playerId) => {
+  const player = game && (game.players || []).find((p) => p.id === playerId);
+  if (!player) return 0;
+
+  const engine = getRulesEngine();
+  if (engine) {
+    const result = engine.score(game, playerId);
+    if (!result.error) return result.score.total;
+  }
+  return calculatePoints([...(player.hand || []), ...(player.tableCardsUp || [])]);
+};
@@ -0,0 +1,68 @@
+import { previewPlay, legalMoves, scorePlayer, getRulesEngine } from './rulesEngine';
+
+const game = {
+  players: [
+    {
+      id: 'p1',
+      hand: [{ id: 'h1', value: '7', suit: 'Hearts' }, { id: 'h2', value: 'K', suit: 'Spades' }],
+      tableCardsUp: [],
+      tableCardsDown: [],
+    },
+  ],
+  centerPile: [{ id: 'c1', value: '9', suit: 'Clubs' }],
+  currentPlayerIndex: 0,
+};
+
+afterEach(() => {
+  delete window.clearTheDeck;
+});
+
+describe('without the WebAssembly engine', () => {
+  test('is not loaded', () => {
+    expect(getRulesEngine()).toBeNull();
+  });
+
+  test('previews plays with the JavaScript rules', () => {
+    expect(previewPlay(game, 'p1', [])).toEqual({ valid: false, reason: 'No cards selected', events: [] });
+    expect(previewPlay(game, 'p1', [game.players[0].hand[0]])).toEqual({ valid: true, reason: '', events: [] });
+  });
+
+  test('has no legal move list', () => {
+    expect(legalMoves(game, 'p1')).toBeNull();
+  });
+
+  test('scores with classic points', () => {
+    expect(scorePlayer(game, 'p1')).toBe(20);
+  });
+});
+
+describe('with the WebAssembly engine', () => {
+  beforeEach(() => {
+    window.clearTheDeck = {
+      validatePlay: jest.fn(() => ({ valid: false, reason: 'invalid play: must play equal or lower' })),
+      legalMoves: jest.fn(() => ({ moves: [{ type: 'pickup', playerId: 'p1' }] })),
+      score: jest.fn(() => ({ score: { total: 42 } })),
+    };
+  });
+
+  test('previews plays with the server rules', () => {
+    const result = previewPlay(game, 'p1', [game.players[0].hand[1]], true);
+
+    expect(window.clearTheDeck.validatePlay).toHaveBeenCalledWith(game, 'p1', ['h2'], true);
+    expect(result).toEqual({ valid: false, reason: 'invalid play: must play equal or lower', events: [] });
+  });
+
+  test('reports engine errors as invalid plays', () => {
+    window.clearTheDeck.validatePlay.mockReturnValue({ error: 'invalid game: no players' });
+    expect(previewPlay(game, 'p1', [])).toEqual({ valid: false, reason: 'invalid game: no players', events: [] });
+  });
+
+  test('lists legal moves', () => {
+    expect(legalMoves(game, 'p1')).toEqual([{ type: 'pickup', playerId: 'p1' }]);
+  });
+
+  test('scores with the game scoring profile', () => {
+    expect(scorePlayer(game, 'p1')).toBe(42);
+    expect(scorePlayer(game, 'nobody')).toBe(0);
+  });
+});
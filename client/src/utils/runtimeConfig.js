@@ -0,0 +1,26 @@
+/**
+ * Settings the Go server injects into index.html when it serves the client itself
+ */
+
+export const RUNTIME_CONFIG_GLOBAL = '__CLEAR_THE_DECK_CONFIG__';
+
+const DEFAULT_WS_URL = 'ws://localhost:8080/ws';
+
+/**
+ * Get the WebSocket URL to connect to
+ * A URL injected by the server wins, then REACT_APP_WS_URL. A served client with no URL
+ * configured connects to /ws on the host it was loaded from.
+ * @param {Object} win - Window to read the config and location from
+ * @returns {string} WebSocket URL
+ */
+export const getWebSocketUrl = (win = typeof window !== 'undefined' ? window : undefined) => {
+  const config = win && win[RUNTIME_CONFIG_GLOBAL];
+  if (config && config.wsUrl) return config.wsUrl;
+  if (process.env.REACT_APP_WS_URL) return process.env.REACT_APP_WS_URL;
+
+  if (config && win.location) {
+    const protocol = win.location.protocol === 'https:' ? 'wss:' : 'ws:';
+    return `${protocol}//${win.location.host}/ws`;
+  }
+  return DEFAULT_WS_URL;
+};
@@ -0,0 +1,33 @@
+import { getWebSocketUrl, RUNTIME_CONFIG_GLOBAL } from './runtimeConfig';
+
+describe('getWebSocketUrl', () => {
+  const originalEnv = process.env.REACT_APP_WS_URL;
+
+  afterEach(() => {
+    if (originalEnv === undefined) {
+      delete process.env.REACT_APP_WS_URL;
+    } else {
+      process.env.REACT_APP_WS_URL = originalEnv;
+    }
+  });
+
+  test('uses the URL injected by the server', () => {
+    process.env.REACT_APP_WS_URL = 'ws://build-time/ws';
+    const win = { [RUNTIME_CONFIG_GLOBAL]: { wsUrl: 'wss://cards.example/ws' } };
+    expect(getWebSocketUrl(win)).toBe('wss://cards.example/ws');
+  });
+
+  test('derives the URL from the page when served without one', () => {
+    delete process.env.REACT_APP_WS_URL;
+    const win = {
+      [RUNTIME_CONFIG_GLOBAL]: { wsUrl: '' },
+      location: { protocol: 'https:', host: 'cards.example:8443' },
+    };
+    expect(getWebSocketUrl(win)).toBe('wss://cards.example:8443/ws');
+  });
+
+  test('falls back to the development server', () => {
+    delete process.env.REACT_APP_WS_URL;
+    expect(getWebSocketUrl({})).toBe('ws://localhost:8080/ws');
+  });
+});
@@ -1,40 +1,180 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/joho/godotenv"
+	"github.com/thben/clearthedeck/internal/accounts"
+	"github.com/thben/clearthedeck/internal/config"
 	"github.com/thben/clearthedeck/internal/handlers"
+	"github.com/thben/clearthedeck/internal/metrics"
+	"github.com/thben/clearthedeck/internal/stats"
+	"github.com/thben/clearthedeck/internal/storage"
+	"github.com/thben/clearthedeck/internal/web"
 )
 
 func main() {
 	// Load .env file if it exists (ignore error in production)
 	godotenv.Load()
 
-	// Get port from environment or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Structured logs; anything still using the log package goes through the same handler
+	logger := cfg.Logger(os.Stderr)
+	slog.SetDefault(logger)
+
+	// Accounts and statistics are kept in the data directory; without one they last until the process exits
+	var store storage.Store
+	if cfg.DataDir != "" {
+		fileStore, err := storage.NewFileStore(cfg.DataDir)
+		if err != nil {
+			fatal("data directory is unusable", err)
+		}
+		store = fileStore
+	}
+	accountStore := store
+	if accountStore == nil {
+		accountStore = storage.NewMemoryStore()
+		logger.Warn("no data directory: accounts and statistics are forgotten when the server stops")
+	}
+	if cfg.AuthSecret == "" {
+		logger.Warn("no auth secret: players are signed out when the server restarts")
 	}
 
 	// Create room handler (replaces old WebSocket handler)
-	roomHandler := handlers.NewRoomHandler()
+	opts := handlerOptions(cfg)
+	opts.Logger = logger
+	opts.Accounts = accounts.NewService(accountStore, []byte(cfg.AuthSecret), cfg.TokenTTL)
+	opts.Stats = stats.NewStore(accountStore)
+	roomHandler := handlers.NewRoomHandlerWithOptions(opts)
+
+	// Pick up games saved by the last shutdown
+	if store != nil {
+		restored, err := roomHandler.RestoreTables(store)
+		if err != nil {
+			logger.Error("some saved games could not be restored", "error", err)
+		}
+		if restored > 0 {
+			logger.Info("restored saved games", "count", restored)
+		}
+	}
+
+	// Close idle rooms and drop finished games
+	stopLifecycle := roomHandler.StartLifecycle(handlers.LifecycleConfig{
+		LobbyTTL:        cfg.LobbyTTL,
+		FinishedGameTTL: cfg.FinishedGameTTL,
+		IdleGameTTL:     cfg.IdleGameTTL,
+		SweepInterval:   cfg.SweepInterval,
+	})
+	defer stopLifecycle()
 
 	// Set up routes
-	http.HandleFunc("/ws", roomHandler.HandleWebSocket)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", roomHandler.HandleWebSocket)
+	mux.HandleFunc("/api/register", roomHandler.HandleRegister)
+	mux.HandleFunc("/api/login", roomHandler.HandleLogin)
+	mux.HandleFunc("/api/players/", roomHandler.HandlePlayerStats)
+	mux.HandleFunc("/api/matches/", roomHandler.HandleMatch)
 
 	// Health check endpoint
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
 
+	// Prometheus scrape endpoint
+	roomHandler.RegisterMetrics(metrics.Default)
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", metrics.ContentType)
+		metrics.Default.Write(w)
+	})
+
+	// Serve the client when this binary was built with it embedded (-tags embedclient)
+	if files := web.Client(); files != nil {
+		client, err := web.Handler(files, web.Options{WebSocketURL: cfg.PublicWSURL})
+		if err != nil {
+			fatal("embedded client is unusable", err)
+		}
+		mux.Handle("/", client)
+		logger.Info("serving embedded client")
+	}
+
+	// WebSockets manage their own deadlines once upgraded, so these only bound plain HTTP requests
+	server := &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           mux,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+
 	// Start server
-	addr := ":" + port
-	log.Printf("Server starting on port %s", addr)
-	if err := http.ListenAndServe(addr, nil); err != nil {
-		log.Fatal("Server failed to start:", err)
+	errs := make(chan error, 1)
+	go func() {
+		logger.Info("server starting", "listen", cfg.Listen, "env", cfg.Env, "tls", cfg.TLS(), "logLevel", cfg.LogLevel)
+		if cfg.TLS() {
+			errs <- server.ListenAndServeTLS(cfg.TLSCert, cfg.TLSKey)
+		} else {
+			errs <- server.ListenAndServe()
+		}
+	}()
+
+	stop, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	select {
+	case err := <-errs:
+		fatal("server failed to start", err)
+	case <-stop.Done():
+	}
+	// A second signal ends the process straight away
+	cancel()
+
+	// Warn players, save their games and close every connection, all within the deadline
+	logger.Info("shutting down", "countdown", cfg.ShutdownCountdown)
+	ctx, done := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer done()
+
+	if err := roomHandler.Shutdown(ctx, cfg.ShutdownCountdown, store); err != nil {
+		logger.Error("some games could not be saved", "error", err)
+	}
+	if err := server.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("server did not shut down cleanly", "error", err)
+	}
+	logger.Info("server stopped")
+}
+
+// fatal logs an error that stops the server from running and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// handlerOptions picks the room handler's options out of the server configuration
+func handlerOptions(cfg config.Config) handlers.Options {
+	opts := handlers.Options{
+		MaxRooms:       cfg.MaxRooms,
+		MaxConnsPerIP:  cfg.MaxConnsPerIP,
+		MaxLoginsPerIP: cfg.MaxLoginsPerIP,
+		TurnTimeout:    cfg.TurnTimeout,
+		UndoVoteWindow: cfg.UndoVoteWindow,
+		SeatHold:       cfg.SeatHold,
+		DefaultRules:   cfg.Rules(),
+	}
+	// Any origin may connect in development
+	if cfg.Env != config.EnvDevelopment {
+		opts.AllowedOrigins = cfg.Origins
 	}
+	return opts
 }
@@ -0,0 +1,132 @@
+//go:build js && wasm
+
+// Command wasm exposes the rules engine to the browser
+//
+// Build it with GOOS=js GOARCH=wasm. Once loaded it registers a global clearTheDeck object whose
+// functions take the game exactly as the server sends it in GAME_UPDATE:
+//
+//	clearTheDeck.validatePlay(game, playerId, cardIds, afterPickup) -> { valid, reason, events, game }
+//	clearTheDeck.legalMoves(game, playerId) -> { moves }
+//	clearTheDeck.score(game, playerId) -> { score }
+//
+// Every result carries an error field instead when the input cannot be read.
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/thben/clearthedeck/engine"
+)
+
+func main() {
+	js.Global().Set("clearTheDeck", js.ValueOf(map[string]interface{}{
+		"version":      "1",
+		"validatePlay": js.FuncOf(validatePlay),
+		"legalMoves":   js.FuncOf(legalMoves),
+		"score":        js.FuncOf(score),
+	}))
+
+	// Keep the exported functions alive
+	select {}
+}
+
+// validatePlay previews a play: whether the server would accept it and what it would do
+func validatePlay(this js.Value, args []js.Value) interface{} {
+	if len(args) < 3 {
+		return errorResult("validatePlay needs a game, a player ID and card IDs")
+	}
+	state, err := decodeState(args[0])
+	if err != nil {
+		return errorResult(err.Error())
+	}
+
+	action := engine.Action{
+		Type:     engine.ActionPlay,
+		PlayerID: args[1].String(),
+		CardIDs:  stringSlice(args[2]),
+	}
+	if len(args) > 3 {
+		action.AfterPickup = args[3].Truthy()
+	}
+
+	next, events, err := engine.Apply(state, action)
+	if err != nil {
+		return result(map[string]interface{}{"valid": false, "reason": err.Error()})
+	}
+	return result(map[string]interface{}{"valid": true, "events": events, "game": next.Game()})
+}
+
+// legalMoves lists the moves the player could make now
+func legalMoves(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return errorResult("legalMoves needs a game and a player ID")
+	}
+	state, err := decodeState(args[0])
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	moves := engine.LegalActions(state, args[1].String())
+	if moves == nil {
+		moves = []engine.Action{}
+	}
+	return result(map[string]interface{}{"moves": moves})
+}
+
+// score returns the points the player's remaining cards are worth
+func score(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return errorResult("score needs a game and a player ID")
+	}
+	state, err := decodeState(args[0])
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	breakdown, err := engine.Score(state, args[1].String())
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	return result(map[string]interface{}{
+		"score": map[string]interface{}{
+			"handPoints":     breakdown.HandPoints,
+			"faceUpPoints":   breakdown.FaceUpPoints,
+			"faceDownPoints": breakdown.FaceDownPoints,
+			"tensPenalty":    breakdown.TensPenalty,
+			"bonus":          breakdown.Bonus,
+			"total":          breakdown.Total(),
+		},
+	})
+}
+
+// decodeState reads a game passed from JS, either as an object or as a JSON string
+func decodeState(v js.Value) (engine.State, error) {
+	data := v.String()
+	if v.Type() == js.TypeObject {
+		data = js.Global().Get("JSON").Call("stringify", v).String()
+	}
+	return engine.DecodeState([]byte(data))
+}
+
+func stringSlice(v js.Value) []string {
+	if v.Type() != js.TypeObject {
+		return nil
+	}
+	ids := make([]string, v.Length())
+	for i := range ids {
+		ids[i] = v.Index(i).String()
+	}
+	return ids
+}
+
+// result hands a Go value to JS by way of JSON, so field names match the server's messages
+func result(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	return js.Global().Get("JSON").Call("parse", string(data))
+}
+
+func errorResult(msg string) interface{} {
+	return js.ValueOf(map[string]interface{}{"error": msg})
+}
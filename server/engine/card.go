@@ -1,4 +1,4 @@
-package models
+package engine
 
 // Card represents a playing card in the game
 type Card struct {
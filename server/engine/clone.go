@@ -0,0 +1,101 @@
+package engine
+
+// Clone returns a deep copy of the game that shares nothing mutable with the original
+// Cards are never modified once dealt, so the copies point at the same cards
+func (g *Game) Clone() *Game {
+
+	c := &Game{
+		ID:                 g.ID,
+		RoomCode:           g.RoomCode,
+		Players:            clonePlayers(g.Players),
+		Forfeited:          clonePlayers(g.Forfeited),
+		Deal:               g.Deal,
+		Rules:              g.Rules,
+		Scoring:            g.Scoring,
+		History:            make([]RoundResult, len(g.History)),
+		Progress:           g.Progress.clone(),
+		StalemateActions:   g.StalemateActions,
+		DiscardPile:        copyCards(g.DiscardPile),
+		Undealt:            g.Undealt,
+		CenterPile:         copyCards(g.CenterPile),
+		AfterPickup:        g.AfterPickup,
+		LastClearMessage:   g.LastClearMessage,
+		CurrentPlayerIndex: g.CurrentPlayerIndex,
+		DealerIndex:        g.DealerIndex,
+		Round:              g.Round,
+		IsStarted:          g.IsStarted,
+		IsFinished:         g.IsFinished,
+		CreatedAt:          g.CreatedAt,
+	}
+	c.Rules.WildRanks = append([]string(nil), g.Rules.WildRanks...)
+	if g.Scoring.CardPoints != nil {
+		c.Scoring.CardPoints = make(map[string]int, len(g.Scoring.CardPoints))
+		for value, points := range g.Scoring.CardPoints {
+			c.Scoring.CardPoints[value] = points
+		}
+	}
+	for i, result := range g.History {
+		result.Players = append([]PlayerResult(nil), result.Players...)
+		c.History[i] = result
+	}
+	return c
+}
+
+// Adopt replaces the game's state with a copy of another game's
+// Existing player objects are updated in place rather than replaced, so a room sharing them stays in step
+func (g *Game) Adopt(other *Game) {
+	next := other.Clone()
+
+	existing := make(map[string]*Player, len(g.Players)+len(g.Forfeited))
+	for _, p := range append(append([]*Player(nil), g.Players...), g.Forfeited...) {
+		existing[p.ID] = p
+	}
+	keep := func(players []*Player) []*Player {
+		out := make([]*Player, 0, len(players))
+		for _, np := range players {
+			if p, ok := existing[np.ID]; ok {
+				*p = *np
+				np = p
+			}
+			out = append(out, np)
+		}
+		return out
+	}
+
+	g.ID = next.ID
+	g.RoomCode = next.RoomCode
+	g.Players = keep(next.Players)
+	g.Forfeited = keep(next.Forfeited)
+	g.Deal = next.Deal
+	g.Rules = next.Rules
+	g.Scoring = next.Scoring
+	g.History = next.History
+	g.Progress = next.Progress
+	g.StalemateActions = next.StalemateActions
+	g.DiscardPile = next.DiscardPile
+	g.Undealt = next.Undealt
+	g.CenterPile = next.CenterPile
+	g.AfterPickup = next.AfterPickup
+	g.LastClearMessage = next.LastClearMessage
+	g.CurrentPlayerIndex = next.CurrentPlayerIndex
+	g.DealerIndex = next.DealerIndex
+	g.Round = next.Round
+	g.IsStarted = next.IsStarted
+	g.IsFinished = next.IsFinished
+	g.CreatedAt = next.CreatedAt
+}
+
+func clonePlayers(players []*Player) []*Player {
+	if players == nil {
+		return nil
+	}
+	out := make([]*Player, len(players))
+	for i, p := range players {
+		c := *p
+		c.Hand = copyCards(p.Hand)
+		c.TableCardsUp = copyCards(p.TableCardsUp)
+		c.TableCardsDown = copyCards(p.TableCardsDown)
+		out[i] = &c
+	}
+	return out
+}
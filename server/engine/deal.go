@@ -0,0 +1,37 @@
+package engine
+
+// TableVariant selects the deck count and deal sizes for a table
+type TableVariant string
+
+const (
+	// VariantAuto picks the variant that fits the number of players
+	VariantAuto TableVariant = "auto"
+	// VariantStandard is the classic 3-10 player game
+	VariantStandard TableVariant = "standard"
+	// VariantTwoPlayer is a head-to-head game from a single deck
+	VariantTwoPlayer TableVariant = "twoPlayer"
+	// VariantLargeTable seats 11-16 players with a smaller hand
+	VariantLargeTable TableVariant = "largeTable"
+)
+
+// IsValid reports whether the variant is one of the known table variants
+func (v TableVariant) IsValid() bool {
+	switch v {
+	case VariantAuto, VariantStandard, VariantTwoPlayer, VariantLargeTable:
+		return true
+	}
+	return false
+}
+
+// DealConfig describes how many decks are used and how many cards each player gets per area
+type DealConfig struct {
+	Decks    int `json:"decks"`
+	FaceDown int `json:"faceDown"`
+	FaceUp   int `json:"faceUp"`
+	Hand     int `json:"hand"`
+}
+
+// CardsPerPlayer returns the number of cards dealt to each player
+func (c DealConfig) CardsPerPlayer() int {
+	return c.FaceDown + c.FaceUp + c.Hand
+}
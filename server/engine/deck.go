@@ -0,0 +1,218 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Errors for deals that cannot be made; details are wrapped around them
+var (
+	ErrInvalidPlayerCount = errors.New("invalid player count")
+	ErrUnknownVariant     = errors.New("unknown table variant")
+	ErrInvalidDeal        = errors.New("invalid deal")
+)
+
+// Initialize random seed
+func init() {
+	rand.Seed(time.Now().UnixNano())
+}
+
+// CardValues are the values in a deck, lowest first
+var CardValues = []string{"A", "2", "3", "4", "5", "6", "7", "8", "9", "10", "J", "Q", "K"}
+
+// cardsPerDeck is the number of cards in a standard deck without jokers
+const cardsPerDeck = 52
+
+// StandardDeal is the classic deal: 4 face-down, 4 face-up and 12 hand cards per player
+var StandardDeal = DealConfig{FaceDown: 4, FaceUp: 4, Hand: 12}
+
+// LargeTableDeal trims the hand so 11-16 players can share a reasonable number of decks
+var LargeTableDeal = DealConfig{FaceDown: 4, FaceUp: 4, Hand: 8}
+
+// DealConfigFor returns the decks and deal sizes of a table variant for the number of players
+// 2 players (two-player): 1 deck
+// 3-5 players: 2 decks (104 cards)
+// 6-7 players: 3 decks (156 cards)
+// 8-10 players: 4 decks (208 cards)
+// 11-16 players (large table): enough decks for the smaller deal plus one spare
+func DealConfigFor(variant TableVariant, playerCount int) (DealConfig, error) {
+	if variant == "" || variant == VariantAuto {
+		switch {
+		case playerCount == 2:
+			variant = VariantTwoPlayer
+		case playerCount > 10:
+			variant = VariantLargeTable
+		default:
+			variant = VariantStandard
+		}
+	}
+
+	cfg := StandardDeal
+	switch variant {
+	case VariantTwoPlayer:
+		if playerCount != 2 {
+			return DealConfig{}, fmt.Errorf("%w: %d. Two-player games need exactly 2", ErrInvalidPlayerCount, playerCount)
+		}
+		cfg.Decks = 1
+	case VariantStandard:
+		switch {
+		case playerCount >= 3 && playerCount <= 5:
+			cfg.Decks = 2
+		case playerCount >= 6 && playerCount <= 7:
+			cfg.Decks = 3
+		case playerCount >= 8 && playerCount <= 10:
+			cfg.Decks = 4
+		default:
+			return DealConfig{}, fmt.Errorf("%w: %d. Must be between 3 and 10", ErrInvalidPlayerCount, playerCount)
+		}
+	case VariantLargeTable:
+		if playerCount < 11 || playerCount > 16 {
+			return DealConfig{}, fmt.Errorf("%w: %d. Large tables seat 11 to 16", ErrInvalidPlayerCount, playerCount)
+		}
+		cfg = LargeTableDeal
+		cfg.Decks = (playerCount*cfg.CardsPerPlayer()+cardsPerDeck-1)/cardsPerDeck + 1
+	default:
+		return DealConfig{}, fmt.Errorf("%w: %s", ErrUnknownVariant, variant)
+	}
+
+	return cfg, nil
+}
+
+// ResolveDealConfig applies the non-zero overrides to the variant's deal and validates the result
+func ResolveDealConfig(variant TableVariant, overrides DealConfig, playerCount int) (DealConfig, error) {
+	cfg, err := DealConfigFor(variant, playerCount)
+	if err != nil {
+		return DealConfig{}, err
+	}
+
+	if overrides.Decks > 0 {
+		cfg.Decks = overrides.Decks
+	}
+	if overrides.FaceDown > 0 {
+		cfg.FaceDown = overrides.FaceDown
+	}
+	if overrides.FaceUp > 0 {
+		cfg.FaceUp = overrides.FaceUp
+	}
+	if overrides.Hand > 0 {
+		cfg.Hand = overrides.Hand
+	}
+
+	if err := ValidateDeal(cfg, playerCount); err != nil {
+		return DealConfig{}, err
+	}
+	return cfg, nil
+}
+
+// ValidateDeal checks that the decks actually cover the deal for the number of players
+func ValidateDeal(cfg DealConfig, playerCount int) error {
+	if playerCount < 1 {
+		return fmt.Errorf("%w: %d", ErrInvalidPlayerCount, playerCount)
+	}
+	if cfg.Decks < 1 {
+		return fmt.Errorf("%w: at least one deck is required", ErrInvalidDeal)
+	}
+	if cfg.FaceDown < 0 || cfg.FaceUp < 0 || cfg.Hand < 1 {
+		return fmt.Errorf("%w: each player needs at least one hand card", ErrInvalidDeal)
+	}
+
+	needed := playerCount * cfg.CardsPerPlayer()
+	available := cfg.Decks * cardsPerDeck
+	if needed > available {
+		return fmt.Errorf("%w: %d deck(s) hold %d cards but dealing %d players needs %d", ErrInvalidDeal, cfg.Decks, available, playerCount, needed)
+	}
+	return nil
+}
+
+// CreateDeck creates a deck of cards sized for the number of players
+// See DealConfigFor for how many decks each table size uses
+func CreateDeck(playerCount int) ([]*Card, error) {
+	cfg, err := DealConfigFor(VariantAuto, playerCount)
+	if err != nil {
+		return nil, err
+	}
+	return CreateDecks(cfg.Decks), nil
+}
+
+// CreateDecks creates the given number of standard 52-card decks shuffled together
+func CreateDecks(numDecks int) []*Card {
+	suits := []string{"Hearts", "Diamonds", "Clubs", "Spades"}
+	deck := make([]*Card, 0, numDecks*cardsPerDeck)
+	cardID := 0
+
+	for d := 0; d < numDecks; d++ {
+		for _, suit := range suits {
+			for _, value := range CardValues {
+				card := NewCard(fmt.Sprintf("card-%d", cardID), suit, value)
+				deck = append(deck, card)
+				cardID++
+			}
+		}
+	}
+
+	return deck
+}
+
+// ShuffleDeck shuffles a deck of cards using the Fisher-Yates algorithm
+func ShuffleDeck(deck []*Card) {
+	ShuffleDeckWith(deck, nil)
+}
+
+// ShuffleDeckWith shuffles a deck with the given source of randomness, so a seeded source gives a repeatable deal
+// A nil source uses the package-wide one
+func ShuffleDeckWith(deck []*Card, rng *rand.Rand) {
+	intn := rand.Intn
+	if rng != nil {
+		intn = rng.Intn
+	}
+	n := len(deck)
+	for i := n - 1; i > 0; i-- {
+		j := intn(i + 1)
+		deck[i], deck[j] = deck[j], deck[i]
+	}
+}
+
+// DealCards deals cards to players following the game rules:
+// 4 face-down cards, then 4 face-up cards, then 12 hand cards per player
+// Returns the remaining cards as the discard pile
+func DealCards(deck []*Card, players []*Player) []*Card {
+	return DealCardsWithConfig(deck, players, StandardDeal)
+}
+
+// DealCardsWithConfig deals face-down, then face-up, then hand cards one at a time around the table
+// using the per-area counts of the config. Returns the remaining cards as the discard pile
+func DealCardsWithConfig(deck []*Card, players []*Player, cfg DealConfig) []*Card {
+	cardIndex := 0
+
+	deal := func(count int, give func(player *Player, card *Card)) {
+		for round := 0; round < count; round++ {
+			for _, player := range players {
+				if cardIndex < len(deck) {
+					give(player, deck[cardIndex])
+					cardIndex++
+				}
+			}
+		}
+	}
+
+	// Deal face-down cards to each player
+	deal(cfg.FaceDown, func(player *Player, card *Card) {
+		player.TableCardsDown = append(player.TableCardsDown, card)
+	})
+
+	// Deal face-up cards to each player
+	deal(cfg.FaceUp, func(player *Player, card *Card) {
+		player.TableCardsUp = append(player.TableCardsUp, card)
+	})
+
+	// Deal hand cards to each player
+	deal(cfg.Hand, func(player *Player, card *Card) {
+		player.Hand = append(player.Hand, card)
+	})
+
+	// Remaining cards become the discard pile
+	discardPile := deck[cardIndex:]
+	return discardPile
+}
@@ -1,15 +1,10 @@
-package utils
+package engine
 
 import (
 	"fmt"
 	"testing"
-
-	"github.com/thben/clearthedeck/internal/models"
 )
 
-// Alias for convenience in tests
-type Card = models.Card
-
 func TestCreateDeck(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -28,7 +23,10 @@ func TestCreateDeck(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			deck := CreateDeck(tt.playerCount)
+			deck, err := CreateDeck(tt.playerCount)
+			if err != nil {
+				t.Fatalf("CreateDeck(%d) returned error: %v", tt.playerCount, err)
+			}
 
 			if len(deck) != tt.expectedLen {
 				t.Errorf("CreateDeck(%d) returned %d cards, expected %d", tt.playerCount, len(deck), tt.expectedLen)
@@ -57,27 +55,88 @@ func TestCreateDeckInvalidPlayerCount(t *testing.T) {
 		name        string
 		playerCount int
 	}{
-		{"Too few players", 2},
-		{"Too many players", 11},
+		{"Too few players", 1},
+		{"Too many players", 17},
 		{"Zero players", 0},
 		{"Negative players", -1},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			defer func() {
-				if r := recover(); r == nil {
-					t.Errorf("CreateDeck(%d) should panic but did not", tt.playerCount)
+			deck, err := CreateDeck(tt.playerCount)
+			if err == nil {
+				t.Errorf("CreateDeck(%d) should return an error but did not", tt.playerCount)
+			}
+			if deck != nil {
+				t.Errorf("CreateDeck(%d) should not return a deck", tt.playerCount)
+			}
+		})
+	}
+}
+
+func TestDealConfigFor(t *testing.T) {
+	tests := []struct {
+		name        string
+		variant     TableVariant
+		playerCount int
+		expected    DealConfig
+		expectErr   bool
+	}{
+		{"Auto picks two-player", VariantAuto, 2, DealConfig{Decks: 1, FaceDown: 4, FaceUp: 4, Hand: 12}, false},
+		{"Auto picks standard", VariantAuto, 6, DealConfig{Decks: 3, FaceDown: 4, FaceUp: 4, Hand: 12}, false},
+		{"Auto picks large table", VariantAuto, 13, DealConfig{Decks: 5, FaceDown: 4, FaceUp: 4, Hand: 8}, false},
+		{"Large table at 16 players", VariantLargeTable, 16, DealConfig{Decks: 6, FaceDown: 4, FaceUp: 4, Hand: 8}, false},
+		{"Standard rejects 2 players", VariantStandard, 2, DealConfig{}, true},
+		{"Two-player rejects 3 players", VariantTwoPlayer, 3, DealConfig{}, true},
+		{"Large table rejects 10 players", VariantLargeTable, 10, DealConfig{}, true},
+		{"Unknown variant", TableVariant("huge"), 4, DealConfig{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := DealConfigFor(tt.variant, tt.playerCount)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("DealConfigFor(%s, %d) should return an error", tt.variant, tt.playerCount)
 				}
-			}()
-			CreateDeck(tt.playerCount)
+				return
+			}
+			if err != nil {
+				t.Fatalf("DealConfigFor(%s, %d) returned error: %v", tt.variant, tt.playerCount, err)
+			}
+			if cfg != tt.expected {
+				t.Errorf("DealConfigFor(%s, %d) = %+v, expected %+v", tt.variant, tt.playerCount, cfg, tt.expected)
+			}
+			if err := ValidateDeal(cfg, tt.playerCount); err != nil {
+				t.Errorf("Variant deal should cover the table: %v", err)
+			}
 		})
 	}
 }
 
+func TestResolveDealConfig(t *testing.T) {
+	t.Run("Overrides replace variant defaults", func(t *testing.T) {
+		cfg, err := ResolveDealConfig(VariantStandard, DealConfig{Decks: 3, Hand: 10}, 5)
+		if err != nil {
+			t.Fatalf("ResolveDealConfig returned error: %v", err)
+		}
+		expected := DealConfig{Decks: 3, FaceDown: 4, FaceUp: 4, Hand: 10}
+		if cfg != expected {
+			t.Errorf("ResolveDealConfig = %+v, expected %+v", cfg, expected)
+		}
+	})
+
+	t.Run("Rejects overrides the decks cannot cover", func(t *testing.T) {
+		_, err := ResolveDealConfig(VariantStandard, DealConfig{Decks: 1}, 5)
+		if err == nil {
+			t.Error("Expected error when 1 deck must deal 5 players 20 cards each")
+		}
+	})
+}
+
 func TestShuffleDeck(t *testing.T) {
 	// Create a deck for testing
-	deck := CreateDeck(4)
+	deck := CreateDecks(2)
 	originalOrder := make([]*Card, len(deck))
 	copy(originalOrder, deck)
 
@@ -115,7 +174,7 @@ func TestShuffleDeck(t *testing.T) {
 
 func TestShuffleDeckMultipleTimes(t *testing.T) {
 	// Test that shuffling produces different results each time
-	deck := CreateDeck(4)
+	deck := CreateDecks(2)
 
 	// Shuffle multiple times and collect orders
 	orders := make([]string, 5)
@@ -157,18 +216,21 @@ func TestDealCards(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create and shuffle deck
-			deck := CreateDeck(tt.playerCount)
+			deck, err := CreateDeck(tt.playerCount)
+			if err != nil {
+				t.Fatalf("CreateDeck(%d) returned error: %v", tt.playerCount, err)
+			}
 			ShuffleDeck(deck)
 
 			// Create players
-			players := make([]*models.Player, tt.playerCount)
+			players := make([]*Player, tt.playerCount)
 			for i := 0; i < tt.playerCount; i++ {
-				players[i] = &models.Player{
+				players[i] = &Player{
 					ID:             fmt.Sprintf("player-%d", i),
 					Name:           fmt.Sprintf("Player %d", i),
-					Hand:           []*models.Card{},
-					TableCardsUp:   []*models.Card{},
-					TableCardsDown: []*models.Card{},
+					Hand:           []*Card{},
+					TableCardsUp:   []*Card{},
+					TableCardsDown: []*Card{},
 				}
 			}
 
@@ -0,0 +1,230 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors Apply returns, alongside those of the rules; compare with errors.Is
+var (
+	ErrNoGame        = errors.New("no game")
+	ErrUnknownAction = errors.New("unknown action")
+	ErrFlipOneCard   = errors.New("flip needs exactly one card")
+	ErrPileEmpty     = errors.New("center pile is empty")
+	ErrGameOver      = errors.New("game is over")
+	ErrRoundNotOver  = errors.New("round is not over")
+)
+
+// ActionType identifies a move
+type ActionType string
+
+const (
+	ActionPlay      ActionType = "play"      // Play one or more cards of the same value from hand or face-up
+	ActionFlip      ActionType = "flip"      // Flip a face-down card
+	ActionPickup    ActionType = "pickup"    // Pick up the center pile
+	ActionNextRound ActionType = "nextRound" // Deal the next round once the current one is scored
+)
+
+// Action is a move made by a player
+type Action struct {
+	Type     ActionType `json:"type"`
+	PlayerID string     `json:"playerId,omitempty"`
+	CardIDs  []string   `json:"cardIds,omitempty"` // Cards to play, or the single face-down card to flip
+	// AfterPickup marks a play made straight after picking up the pile
+	AfterPickup bool  `json:"afterPickup,omitempty"`
+	Seed        int64 `json:"seed,omitempty"` // Non-zero seeds give a repeatable deal for ActionNextRound
+}
+
+// EventType identifies something that happened while applying an action
+type EventType string
+
+const (
+	EventCardsPlayed  EventType = "cardsPlayed"  // Cards went onto the center pile
+	EventCardFlipped  EventType = "cardFlipped"  // A face-down card was revealed
+	EventFlipMissed   EventType = "flipMissed"   // The flipped card could not be played and was taken into hand
+	EventPileCleared  EventType = "pileCleared"  // The center pile was cleared by a wild or a set
+	EventPilePickedUp EventType = "pilePickedUp" // Cards from the center pile went into a player's hand
+	EventTurnPassed   EventType = "turnPassed"   // The turn moved to another player
+	EventRoundEnded   EventType = "roundEnded"   // The round was scored
+	EventRoundStarted EventType = "roundStarted" // A new round was dealt
+)
+
+// Event describes one consequence of an action
+type Event struct {
+	Type     EventType    `json:"type"`
+	PlayerID string       `json:"playerId,omitempty"`
+	Cards    []*Card      `json:"cards,omitempty"`
+	Count    int          `json:"count,omitempty"`   // Cards picked up
+	Message  string       `json:"message,omitempty"` // Clear message shown to players
+	Reason   string       `json:"reason,omitempty"`  // Why a round ended
+	Result   *RoundResult `json:"result,omitempty"`  // Scores of an ended round
+}
+
+// Apply applies an action to a state and returns the resulting state and what happened
+// The given state is never changed; on error it is returned as is
+func Apply(s State, a Action) (State, []Event, error) {
+	if s.game == nil {
+		return s, nil, ErrNoGame
+	}
+
+	next := State{game: s.game.Clone()}
+	var events []Event
+	var err error
+	switch a.Type {
+	case ActionPlay:
+		events, err = next.play(a)
+	case ActionFlip:
+		events, err = next.flip(a)
+	case ActionPickup:
+		events, err = next.pickup(a)
+	case ActionNextRound:
+		events, err = next.nextRound(a)
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnknownAction, a.Type)
+	}
+	if err != nil {
+		return s, nil, err
+	}
+
+	if a.Type != ActionNextRound {
+		events = append(events, next.finishRound()...)
+	}
+	return next, events, nil
+}
+
+func (s State) play(a Action) ([]Event, error) {
+	player, err := s.player(a.PlayerID)
+	if err != nil {
+		return nil, err
+	}
+	cards := findCards(player, a.CardIDs)
+	before := s.snapshot(player)
+
+	if err := PlayCards(s.game, a.PlayerID, a.CardIDs, a.AfterPickup); err != nil {
+		return nil, err
+	}
+
+	events := []Event{{Type: EventCardsPlayed, PlayerID: a.PlayerID, Cards: cards}}
+	return append(events, s.outcome(player, before)...), nil
+}
+
+func (s State) flip(a Action) ([]Event, error) {
+	if len(a.CardIDs) != 1 {
+		return nil, ErrFlipOneCard
+	}
+	player, err := s.player(a.PlayerID)
+	if err != nil {
+		return nil, err
+	}
+	var card *Card
+	for _, c := range player.TableCardsDown {
+		if c.ID == a.CardIDs[0] {
+			card = c
+		}
+	}
+	before := s.snapshot(player)
+	misses := player.FlipMisses
+
+	if err := FlipFaceDown(s.game, a.PlayerID, a.CardIDs[0]); err != nil {
+		return nil, err
+	}
+
+	events := []Event{{Type: EventCardFlipped, PlayerID: a.PlayerID, Cards: []*Card{card}}}
+	if player.FlipMisses > misses {
+		events = append(events, Event{Type: EventFlipMissed, PlayerID: a.PlayerID, Cards: []*Card{card}})
+		// The flipped card itself is not part of the pile picked up
+		before.hand++
+	} else {
+		events = append(events, Event{Type: EventCardsPlayed, PlayerID: a.PlayerID, Cards: []*Card{card}})
+	}
+	return append(events, s.outcome(player, before)...), nil
+}
+
+func (s State) pickup(a Action) ([]Event, error) {
+	player, err := s.player(a.PlayerID)
+	if err != nil {
+		return nil, err
+	}
+	if s.CurrentPlayerID() != a.PlayerID {
+		return nil, ErrNotYourTurn
+	}
+	if len(s.game.CenterPile) == 0 {
+		return nil, ErrPileEmpty
+	}
+	before := s.snapshot(player)
+
+	if err := PickupPile(s.game, a.PlayerID); err != nil {
+		return nil, err
+	}
+	return s.outcome(player, before), nil
+}
+
+func (s State) nextRound(a Action) ([]Event, error) {
+	if s.game.IsFinished {
+		return nil, ErrGameOver
+	}
+	if !s.game.RoundOver() {
+		return nil, ErrRoundNotOver
+	}
+	if err := StartNextRoundWithRand(s.game, seeded(a.Seed)); err != nil {
+		return nil, err
+	}
+	return []Event{{Type: EventRoundStarted, PlayerID: s.CurrentPlayerID()}}, nil
+}
+
+// finishRound scores the round if a player has gone out or the round has stopped making progress
+func (s State) finishRound() []Event {
+	if s.game.RoundOver() {
+		return nil
+	}
+	for _, p := range s.game.Players {
+		if CheckWinCondition(p) {
+			EndRound(s.game, p.ID)
+			return []Event{{Type: EventRoundEnded, PlayerID: p.ID, Reason: RoundEndWon, Result: s.game.LastResult()}}
+		}
+	}
+	if stuck, reason := CheckStalemate(s.game); stuck {
+		EndRoundInStalemate(s.game, reason)
+		return []Event{{Type: EventRoundEnded, Reason: reason, Result: s.game.LastResult()}}
+	}
+	return nil
+}
+
+// before is what an action is compared against to tell what it did
+type before struct {
+	hand    int
+	current string
+}
+
+func (s State) snapshot(player *Player) before {
+	return before{hand: len(player.Hand), current: s.CurrentPlayerID()}
+}
+
+// outcome reports clears, pickups and turn changes caused by an action
+func (s State) outcome(player *Player, b before) []Event {
+	var events []Event
+	if msg := s.game.GetLastClearMessage(); msg != "" {
+		events = append(events, Event{Type: EventPileCleared, PlayerID: player.ID, Message: msg})
+	}
+	if picked := len(player.Hand) - b.hand; picked > 0 {
+		events = append(events, Event{Type: EventPilePickedUp, PlayerID: player.ID, Count: picked})
+	}
+	if current := s.CurrentPlayerID(); current != b.current {
+		events = append(events, Event{Type: EventTurnPassed, PlayerID: current})
+	}
+	return events
+}
+
+func findCards(player *Player, ids []string) []*Card {
+	cards := make([]*Card, 0, len(ids))
+	for _, id := range ids {
+		for _, area := range [][]*Card{player.Hand, player.TableCardsUp} {
+			for _, c := range area {
+				if c.ID == id {
+					cards = append(cards, c)
+				}
+			}
+		}
+	}
+	return cards
+}
@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestState(hand, pile []*Card) State {
+	players := []*Player{
+		{ID: "p1", Name: "Player 1", Hand: hand},
+		{ID: "p2", Name: "Player 2", Hand: []*Card{{ID: "x1", Value: "2"}, {ID: "x2", Value: "3"}}},
+	}
+	game := NewGame("game-1", "ABCD", players)
+	game.IsStarted = true
+	game.CenterPile = pile
+	game.ResetProgress()
+	return FromGame(game)
+}
+
+func eventTypes(events []Event) []EventType {
+	types := make([]EventType, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+func TestNewState(t *testing.T) {
+	seats := []Seat{{ID: "p1", Name: "Player 1"}, {ID: "p2", Name: "Player 2"}}
+
+	t.Run("the same seed deals the same game", func(t *testing.T) {
+		a, err := NewState(seats, Config{Seed: 42})
+		require.NoError(t, err)
+		b, err := NewState(seats, Config{Seed: 42})
+		require.NoError(t, err)
+
+		assert.Equal(t, a.Game().Players[0].Hand, b.Game().Players[0].Hand)
+		assert.Equal(t, a.CurrentPlayerID(), b.CurrentPlayerID())
+		assert.Equal(t, 1, a.Round())
+	})
+
+	t.Run("uses the configured rules", func(t *testing.T) {
+		rules, _ := LookupRuleSet(RulesStrict)
+		s, err := NewState(seats, Config{Rules: rules})
+		require.NoError(t, err)
+		assert.Equal(t, RulesStrict, s.Rules().Name)
+	})
+}
+
+func TestApply(t *testing.T) {
+	t.Run("never changes the given state", func(t *testing.T) {
+		s := newTestState([]*Card{{ID: "h1", Value: "7"}, {ID: "h2", Value: "9"}}, nil)
+
+		next, _, err := Apply(s, Action{Type: ActionPlay, PlayerID: "p1", CardIDs: []string{"h1"}})
+
+		require.NoError(t, err)
+		assert.Len(t, s.Game().Players[0].Hand, 2)
+		assert.Empty(t, s.Game().CenterPile)
+		assert.Len(t, next.Game().Players[0].Hand, 1)
+		assert.Equal(t, "p1", s.CurrentPlayerID())
+		assert.Equal(t, "p2", next.CurrentPlayerID())
+	})
+
+	t.Run("reports a play and the turn passing", func(t *testing.T) {
+		s := newTestState([]*Card{{ID: "h1", Value: "7"}, {ID: "h2", Value: "9"}}, nil)
+
+		_, events, err := Apply(s, Action{Type: ActionPlay, PlayerID: "p1", CardIDs: []string{"h1"}})
+
+		require.NoError(t, err)
+		assert.Equal(t, []EventType{EventCardsPlayed, EventTurnPassed}, eventTypes(events))
+		assert.Equal(t, "h1", events[0].Cards[0].ID)
+		assert.Equal(t, "p2", events[1].PlayerID)
+	})
+
+	t.Run("reports a clear", func(t *testing.T) {
+		s := newTestState([]*Card{{ID: "h1", Value: "10"}, {ID: "h2", Value: "9"}}, []*Card{{ID: "c1", Value: "K"}})
+
+		_, events, err := Apply(s, Action{Type: ActionPlay, PlayerID: "p1", CardIDs: []string{"h1"}})
+
+		require.NoError(t, err)
+		assert.Contains(t, eventTypes(events), EventPileCleared)
+	})
+
+	t.Run("reports a pickup, after which the player keeps the turn", func(t *testing.T) {
+		s := newTestState([]*Card{{ID: "h1", Value: "4"}}, []*Card{{ID: "c1", Value: "K"}, {ID: "c2", Value: "Q"}})
+
+		_, events, err := Apply(s, Action{Type: ActionPickup, PlayerID: "p1"})
+
+		require.NoError(t, err)
+		require.Equal(t, []EventType{EventPilePickedUp}, eventTypes(events))
+		assert.Equal(t, 2, events[0].Count)
+	})
+
+	t.Run("ends the round when a player goes out", func(t *testing.T) {
+		s := newTestState([]*Card{{ID: "h1", Value: "7"}}, nil)
+
+		next, events, err := Apply(s, Action{Type: ActionPlay, PlayerID: "p1", CardIDs: []string{"h1"}})
+
+		require.NoError(t, err)
+		last := events[len(events)-1]
+		assert.Equal(t, EventRoundEnded, last.Type)
+		assert.Equal(t, "p1", last.PlayerID)
+		require.NotNil(t, last.Result)
+		assert.Equal(t, "p1", last.Result.WinnerID)
+		assert.True(t, next.RoundOver())
+		assert.False(t, s.RoundOver())
+	})
+
+	t.Run("returns the given state on error", func(t *testing.T) {
+		s := newTestState([]*Card{{ID: "h1", Value: "7"}}, nil)
+
+		next, events, err := Apply(s, Action{Type: ActionPlay, PlayerID: "p2", CardIDs: []string{"x1"}})
+
+		assert.Error(t, err)
+		assert.Nil(t, events)
+		assert.Equal(t, s, next)
+	})
+
+	t.Run("rejects picking up an empty pile", func(t *testing.T) {
+		s := newTestState([]*Card{{ID: "h1", Value: "7"}}, nil)
+
+		_, _, err := Apply(s, Action{Type: ActionPickup, PlayerID: "p1"})
+
+		assert.EqualError(t, err, "center pile is empty")
+	})
+
+	t.Run("deals the next round only once the round is over", func(t *testing.T) {
+		s := newTestState([]*Card{{ID: "h1", Value: "7"}}, nil)
+
+		_, _, err := Apply(s, Action{Type: ActionNextRound})
+		assert.EqualError(t, err, "round is not over")
+
+		ended, _, err := Apply(s, Action{Type: ActionPlay, PlayerID: "p1", CardIDs: []string{"h1"}})
+		require.NoError(t, err)
+		next, events, err := Apply(ended, Action{Type: ActionNextRound, Seed: 7})
+
+		require.NoError(t, err)
+		assert.Equal(t, []EventType{EventRoundStarted}, eventTypes(events))
+		assert.Equal(t, 2, next.Round())
+		assert.False(t, next.RoundOver())
+	})
+
+	t.Run("rejects unknown actions", func(t *testing.T) {
+		_, _, err := Apply(newTestState(nil, nil), Action{Type: "shuffle"})
+		assert.EqualError(t, err, "unknown action: shuffle")
+	})
+}
+
+func TestCopyIntoKeepsPlayers(t *testing.T) {
+	game := newTestState([]*Card{{ID: "h1", Value: "7"}, {ID: "h2", Value: "9"}}, nil).Game()
+	player := game.Players[0]
+
+	next, _, err := Apply(FromGame(game), Action{Type: ActionPlay, PlayerID: "p1", CardIDs: []string{"h1"}})
+	require.NoError(t, err)
+	next.CopyInto(game)
+
+	assert.Same(t, player, game.Players[0])
+	assert.Len(t, player.Hand, 1)
+	assert.Len(t, game.CenterPile, 1)
+}
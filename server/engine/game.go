@@ -0,0 +1,137 @@
+package engine
+
+import "time"
+
+// Game represents a game instance with all its state
+type Game struct {
+	ID                 string         `json:"id"`
+	RoomCode           string         `json:"roomCode"`
+	Players            []*Player      `json:"players"`
+	Forfeited          []*Player      `json:"forfeited"`
+	Deal               DealConfig     `json:"deal"`
+	Rules              RuleSet        `json:"rules"`
+	Scoring            ScoringProfile `json:"scoring"`
+	History            []RoundResult  `json:"history"` // Summary of every finished round
+	Progress           Progress       `json:"progress"`
+	StalemateActions   int            `json:"stalemateActions"` // Actions without progress before a round is ended; 0 uses the default
+	DiscardPile        []*Card        `json:"discardPile"`
+	Undealt            int            `json:"undealt"` // Cards at the bottom of the discard pile that nobody has seen
+	CenterPile         []*Card        `json:"centerPile"`
+	AfterPickup        bool           `json:"afterPickup"`
+	LastClearMessage   string         `json:"lastClearMessage"`
+	CurrentPlayerIndex int            `json:"currentPlayerIndex"`
+	DealerIndex        int            `json:"dealerIndex"`
+	Round              int            `json:"round"`
+	IsStarted          bool           `json:"isStarted"`
+	IsFinished         bool           `json:"isFinished"`
+	CreatedAt          time.Time      `json:"createdAt"`
+}
+
+// Player is a seat at the table and the cards in front of it
+type Player struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	JoinedAt       time.Time `json:"joinedAt"`
+	Hand           []*Card   `json:"hand"`
+	TableCardsUp   []*Card   `json:"tableCardsUp"`
+	TableCardsDown []*Card   `json:"tableCardsDown"`
+	RoundScore     int       `json:"roundScore"` // Points for current round
+	TotalScore     int       `json:"totalScore"` // Cumulative score across all rounds
+	IsBot          bool      `json:"isBot"`      // Seat is played by a bot
+	Registered     bool      `json:"registered"` // ID belongs to a signed-in account rather than a guest
+	Away           bool      `json:"away"`       // Seat is held for a player who left mid-game
+	AwayUntil      time.Time `json:"awayUntil"`  // When a held seat is given up
+	FlipMisses     int       `json:"flipMisses"` // Face-down flips this round that could not be played
+}
+
+// IsPresent reports whether a human is actively sitting in this seat
+func (p *Player) IsPresent() bool {
+	return !p.IsBot && !p.Away
+}
+
+// NewGame creates a new game instance
+func NewGame(id, roomCode string, players []*Player) *Game {
+	return &Game{
+		ID:                 id,
+		RoomCode:           roomCode,
+		Players:            players,
+		Rules:              DefaultRuleSet(),
+		Scoring:            DefaultScoringProfile(),
+		DiscardPile:        []*Card{},
+		CenterPile:         []*Card{},
+		LastClearMessage:   "",
+		CurrentPlayerIndex: 0,
+		DealerIndex:        0,
+		Round:              1,
+		IsStarted:          false,
+		IsFinished:         false,
+		CreatedAt:          time.Now(),
+	}
+}
+
+// GetCurrentPlayer returns the current player
+func (g *Game) GetCurrentPlayer() *Player {
+	if g.CurrentPlayerIndex >= 0 && g.CurrentPlayerIndex < len(g.Players) {
+		return g.Players[g.CurrentPlayerIndex]
+	}
+	return nil
+}
+
+// NextPlayer advances to the next player, skipping seats held for away players
+func (g *Game) NextPlayer() {
+	if len(g.Players) == 0 {
+		return
+	}
+	for i := 0; i < len(g.Players); i++ {
+		g.CurrentPlayerIndex = (g.CurrentPlayerIndex + 1) % len(g.Players)
+		if !g.Players[g.CurrentPlayerIndex].Away {
+			return
+		}
+	}
+}
+
+// PlayerIndex returns the seat index of the player, or -1 if they are not in the game
+func (g *Game) PlayerIndex(playerID string) int {
+	for i, p := range g.Players {
+		if p.ID == playerID {
+			return i
+		}
+	}
+	return -1
+}
+
+// Start marks the game as started
+func (g *Game) Start() {
+	g.IsStarted = true
+}
+
+// Finish marks the game as finished
+func (g *Game) Finish() {
+	g.IsFinished = true
+}
+
+// ActiveRules returns the game's rule set, falling back to the standard rules for games built without one
+func (g *Game) ActiveRules() RuleSet {
+	if g.Rules.MinSetSize == 0 {
+		return DefaultRuleSet()
+	}
+	return g.Rules
+}
+
+// ActiveScoring returns the game's scoring profile, falling back to classic scoring for games built without one
+func (g *Game) ActiveScoring() ScoringProfile {
+	if g.Scoring.CardPoints == nil {
+		return DefaultScoringProfile()
+	}
+	return g.Scoring
+}
+
+// GetLastClearMessage exposes the most recent clear message for UI/tests
+func (g *Game) GetLastClearMessage() string {
+	return g.LastClearMessage
+}
+
+// SetLastClearMessage updates the clear message
+func (g *Game) SetLastClearMessage(msg string) {
+	g.LastClearMessage = msg
+}
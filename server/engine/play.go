@@ -0,0 +1,506 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// Errors returned by the rules; details are wrapped around them, so compare with errors.Is
+var (
+	ErrNotYourTurn    = errors.New("not your turn")
+	ErrRoundOver      = errors.New("round is over")
+	ErrPlayerNotFound = errors.New("player not found")
+	ErrCardNotFound   = errors.New("card not found")
+	ErrInvalidPlay    = errors.New("invalid play")
+	ErrMixedValues    = errors.New("all cards must have the same value")
+	ErrFaceUpTooEarly = errors.New("face-up cards can only be played once your hand is empty")
+	ErrFlipBlocked    = errors.New("cannot flip face-down card until paired face-up is played")
+)
+
+// StartGame initializes a new game with deck creation, shuffling, and dealing
+// The deck and deal sizes are picked from the number of players
+func StartGame(players []*Player) (*Game, error) {
+	cfg, err := DealConfigFor(VariantAuto, len(players))
+	if err != nil {
+		return nil, err
+	}
+	return StartGameWithConfig(players, cfg)
+}
+
+// StartGameWithConfig initializes a new game dealt with the given deck count and deal sizes
+func StartGameWithConfig(players []*Player, cfg DealConfig) (*Game, error) {
+	return StartGameWithRand(players, cfg, nil)
+}
+
+// StartGameWithRand initializes a new game shuffled with the given source of randomness
+// A nil source uses the package-wide one
+func StartGameWithRand(players []*Player, cfg DealConfig, rng *rand.Rand) (*Game, error) {
+	// Validate the deck covers the deal
+	if err := ValidateDeal(cfg, len(players)); err != nil {
+		return nil, err
+	}
+
+	// Create and shuffle the deck
+	deck := CreateDecks(cfg.Decks)
+	ShuffleDeckWith(deck, rng)
+
+	// Deal cards to players
+	discardPile := DealCardsWithConfig(deck, players, cfg)
+
+	// Create game instance
+	game := NewGame("", "", players)
+	game.Deal = cfg
+	game.DiscardPile = discardPile
+	game.Undealt = len(discardPile)
+	game.CenterPile = []*Card{}
+	game.CurrentPlayerIndex = 0
+	game.IsStarted = true
+	game.IsFinished = false
+	game.ResetProgress()
+
+	return game, nil
+}
+
+// InitializeRound prepares a new round in an existing game
+func InitializeRound(game *Game) error {
+	return initializeRound(game, nil)
+}
+
+func initializeRound(game *Game, rng *rand.Rand) error {
+	playerCount := len(game.Players)
+
+	// Games created without a deal config use the one for their table size
+	cfg := game.Deal
+	if cfg.Decks == 0 {
+		var err error
+		if cfg, err = DealConfigFor(VariantAuto, playerCount); err != nil {
+			return err
+		}
+	}
+	if err := ValidateDeal(cfg, playerCount); err != nil {
+		return err
+	}
+
+	// Reset after-pickup state at the start of every round
+	game.AfterPickup = false
+	game.SetLastClearMessage("")
+
+	// Create and shuffle new deck
+	deck := CreateDecks(cfg.Decks)
+	ShuffleDeckWith(deck, rng)
+
+	// Clear existing cards from all players
+	for _, player := range game.Players {
+		player.Hand = []*Card{}
+		player.TableCardsUp = []*Card{}
+		player.TableCardsDown = []*Card{}
+		player.FlipMisses = 0
+	}
+
+	// Deal new cards
+	discardPile := DealCardsWithConfig(deck, game.Players, cfg)
+
+	// Reset game state
+	game.Deal = cfg
+	game.DiscardPile = discardPile
+	game.Undealt = len(discardPile)
+	game.CenterPile = []*Card{}
+	game.CurrentPlayerIndex = 0
+	game.IsFinished = false
+	game.ResetProgress()
+	return nil
+}
+
+// PlayCards handles a player playing cards to the center pile
+func PlayCards(game *Game, playerID string, cardIDs []string, afterPickup bool) error {
+	if game.RoundOver() {
+		return ErrRoundOver
+	}
+
+	// Reset clear message for this action
+	game.SetLastClearMessage("")
+	rules := game.ActiveRules()
+
+	// Find the player
+	var player *Player
+	for _, p := range game.Players {
+		if p.ID == playerID {
+			player = p
+			break
+		}
+	}
+	if player == nil {
+		return ErrPlayerNotFound
+	}
+
+	// Check if it's the player's turn
+	currentPlayer := game.GetCurrentPlayer()
+	if currentPlayer.ID != playerID {
+		return ErrNotYourTurn
+	}
+
+	// Find the cards to play
+	cardsToPlay := make([]*Card, 0)
+	fromHand := 0
+	fromFaceUp := 0
+	for _, cardID := range cardIDs {
+		found := false
+		// Check in hand
+		for _, card := range player.Hand {
+			if card.ID == cardID {
+				cardsToPlay = append(cardsToPlay, card)
+				found = true
+				fromHand++
+				break
+			}
+		}
+		// Check in table up
+		if !found {
+			for _, card := range player.TableCardsUp {
+				if card.ID == cardID {
+					cardsToPlay = append(cardsToPlay, card)
+					found = true
+					fromFaceUp++
+					break
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: %s", ErrCardNotFound, cardID)
+		}
+	}
+
+	// Face-up cards may have to wait until the hand is played out
+	if fromFaceUp > 0 && !rules.FaceUpBeforeHandEmpty && fromHand < len(player.Hand) {
+		return ErrFaceUpTooEarly
+	}
+
+	// Validate all cards are the same value
+	if !AllSameValue(cardsToPlay) {
+		return ErrMixedValues
+	}
+
+	// Determine if this play follows a pickup (allows any value)
+	effectiveAfterPickup := afterPickup || game.AfterPickup
+
+	// Validate play is legal
+	valid, reason := ValidatePlay(cardsToPlay, game.CenterPile, effectiveAfterPickup, rules)
+	if !valid {
+		return fmt.Errorf("%w: %s", ErrInvalidPlay, reason)
+	}
+
+	// Valid play consumes after-pickup state
+	game.AfterPickup = false
+
+	// Remove cards from player's hand/table
+	for _, cardID := range cardIDs {
+		// Remove from hand
+		for i, card := range player.Hand {
+			if card.ID == cardID {
+				player.Hand = append(player.Hand[:i], player.Hand[i+1:]...)
+				break
+			}
+		}
+		// Remove from table up
+		for i, card := range player.TableCardsUp {
+			if card.ID == cardID {
+				player.TableCardsUp = append(player.TableCardsUp[:i], player.TableCardsUp[i+1:]...)
+				break
+			}
+		}
+	}
+
+	resolvePlay(game, player, cardsToPlay)
+	game.RecordAction()
+	return nil
+}
+
+// resolvePlay puts legal cards on the center pile and applies wilds, sets and over-value pickups
+// The turn passes on unless the pile was cleared
+func resolvePlay(game *Game, player *Player, cardsToPlay []*Card) {
+	rules := game.ActiveRules()
+
+	// Snapshot previous top for over-value resolution
+	var prevTop *Card
+	if len(game.CenterPile) > 0 {
+		prevTop = game.CenterPile[len(game.CenterPile)-1]
+	}
+
+	// Add cards to center pile
+	game.CenterPile = append(game.CenterPile, cardsToPlay...)
+
+	// Check for wilds: they either clear the deck or reset the pile for the next player
+	if rules.IsWild(cardsToPlay[0].Value) {
+		if rules.WildClears {
+			game.SetLastClearMessage(formatWildClearMessage(cardsToPlay[0].Value))
+			ClearDeck(game)
+			return
+		}
+		game.NextPlayer()
+		return
+	}
+
+	// Check for set (enough of the same value)
+	if count, value := CountTrailingSet(game.CenterPile); count >= rules.MinSetSize {
+		game.SetLastClearMessage(formatSetClearMessage(count, value))
+		ClearDeck(game)
+		return
+	}
+
+	// Resolve over-value pickup: keep only matching value on pile, pick up the rest
+	if IsOverValue(cardsToPlay, prevTop, rules) {
+		keep := make([]*Card, 0)
+		pickup := make([]*Card, 0)
+		for _, c := range game.CenterPile {
+			if c.Value == cardsToPlay[0].Value {
+				keep = append(keep, c)
+			} else {
+				pickup = append(pickup, c)
+			}
+		}
+		game.CenterPile = keep
+		player.Hand = append(player.Hand, pickup...)
+		// Over value cards form set after pickup, clear board and allow player to have another turn
+		if count, value := CountTrailingSet(game.CenterPile); count >= rules.MinSetSize {
+			game.SetLastClearMessage(formatSetClearMessage(count, value))
+			ClearDeck(game)
+			return
+		}
+	}
+
+	// Normal play - advance to next player
+	game.NextPlayer()
+}
+
+// ClearDeck moves center pile to discard and keeps turn with current player
+func ClearDeck(game *Game) {
+	// Move center pile to discard
+	game.DiscardPile = append(game.DiscardPile, game.CenterPile...)
+	game.CenterPile = []*Card{}
+	// Turn stays with current player (additional turn)
+}
+
+// PickupPile moves center pile to player's hand and keeps turn with current player
+func PickupPile(game *Game, playerID string) error {
+	if game.RoundOver() {
+		return ErrRoundOver
+	}
+
+	// Find the player
+	var player *Player
+	for _, p := range game.Players {
+		if p.ID == playerID {
+			player = p
+			break
+		}
+	}
+	if player == nil {
+		return ErrPlayerNotFound
+	}
+
+	// Move center pile to player's hand
+	player.Hand = append(player.Hand, game.CenterPile...)
+	game.CenterPile = []*Card{}
+	game.AfterPickup = true
+	// Turn stays with current player (additional turn)
+	game.RecordAction()
+	return nil
+}
+
+// FlipFaceDown reveals a face-down card and attempts to play it
+func FlipFaceDown(game *Game, playerID string, cardID string) error {
+	if game.RoundOver() {
+		return ErrRoundOver
+	}
+
+	// Reset clear message for this action
+	game.SetLastClearMessage("")
+
+	// Find the player
+	var player *Player
+	for _, p := range game.Players {
+		if p.ID == playerID {
+			player = p
+			break
+		}
+	}
+	if player == nil {
+		return ErrPlayerNotFound
+	}
+
+	// Check if it's the player's turn
+	currentPlayer := game.GetCurrentPlayer()
+	if currentPlayer.ID != playerID {
+		return ErrNotYourTurn
+	}
+
+	// Find and remove the face-down card
+	var flippedCard *Card
+	var flippedIndex int
+	for i, card := range player.TableCardsDown {
+		if card.ID == cardID {
+			flippedCard = card
+			flippedIndex = i
+			break
+		}
+	}
+	if flippedCard == nil {
+		return fmt.Errorf("face-down %w", ErrCardNotFound)
+	}
+
+	// Enforce paired face-up (same slot index) must be played first
+	if flippedIndex < len(player.TableCardsUp) {
+		if partner := player.TableCardsUp[flippedIndex]; partner != nil {
+			return ErrFlipBlocked // TODO this error is showing up unexpectedly, probably need to link the face up/down cards to avoid client/server mismatch in ordering. Or we remove cards from the array, reducing size
+		}
+	}
+
+	// Remove the card now that validation passed
+	player.TableCardsDown = append(player.TableCardsDown[:flippedIndex], player.TableCardsDown[flippedIndex+1:]...)
+
+	// Check if card can be played
+	valid, _ := ValidatePlay([]*Card{flippedCard}, game.CenterPile, false, game.ActiveRules())
+	if valid {
+		resolvePlay(game, player, []*Card{flippedCard})
+	} else if game.ActiveRules().FlipInvalidPickup {
+		// Invalid play - add flipped card and center pile to hand
+		player.FlipMisses++
+		player.Hand = append(player.Hand, flippedCard)
+		player.Hand = append(player.Hand, game.CenterPile...)
+		game.CenterPile = []*Card{}
+		// Turn stays with current player (additional turn to play from hand)
+	} else {
+		// Invalid play - only the flipped card is taken and the turn passes
+		player.FlipMisses++
+		player.Hand = append(player.Hand, flippedCard)
+		game.NextPlayer()
+	}
+
+	game.RecordAction()
+	return nil
+}
+
+// CheckWinCondition checks if a player has won (0 cards remaining)
+func CheckWinCondition(player *Player) bool {
+	totalCards := len(player.Hand) + len(player.TableCardsUp) + len(player.TableCardsDown)
+	return totalCards == 0
+}
+
+// EndRound calculates scores for all players and updates cumulative totals
+// Winner receives 0 points for the round; a summary of the round is added to the game history
+func EndRound(game *Game, winnerID string) {
+	endRound(game, winnerID, RoundEndWon)
+}
+
+func endRound(game *Game, winnerID, reason string) {
+	profile := game.ActiveScoring()
+	result := RoundResult{
+		Round:    game.Round,
+		WinnerID: winnerID,
+		Reason:   reason,
+		Scoring:  profile.Name,
+		Players:  make([]PlayerResult, 0, len(game.Players)),
+	}
+
+	for _, player := range game.Players {
+		var breakdown ScoreBreakdown
+		if player.ID == winnerID {
+			// Winner gets 0 points, less the bonus for a clean face-down row
+			if player.FlipMisses == 0 {
+				breakdown.Bonus = profile.CleanFinishBonus
+			}
+		} else {
+			// Calculate score from remaining cards
+			breakdown = ScoreAreas(player, profile)
+		}
+		player.RoundScore = breakdown.Total()
+
+		// Add round score to cumulative total
+		player.TotalScore += player.RoundScore
+
+		result.Players = append(result.Players, PlayerResult{
+			ID:             player.ID,
+			Name:           player.Name,
+			Hand:           append([]*Card{}, player.Hand...),
+			FaceUp:         append([]*Card{}, player.TableCardsUp...),
+			FaceDown:       append([]*Card{}, player.TableCardsDown...),
+			ScoreBreakdown: breakdown,
+			RoundScore:     player.RoundScore,
+			TotalScore:     player.TotalScore,
+		})
+	}
+
+	// Forfeited players keep their place on the score sheet with the total they left with
+	for _, player := range game.Forfeited {
+		result.Players = append(result.Players, PlayerResult{
+			ID:         player.ID,
+			Name:       player.Name,
+			RoundScore: player.RoundScore,
+			TotalScore: player.TotalScore,
+			Forfeited:  true,
+		})
+	}
+
+	rankResults(result.Players)
+	game.History = append(game.History, result)
+}
+
+// rankResults orders results by total score, lowest first, giving tied players the same rank
+// Forfeited players come after everyone still seated, whatever their total.
+func rankResults(results []PlayerResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Forfeited != results[j].Forfeited {
+			return !results[i].Forfeited
+		}
+		return results[i].TotalScore < results[j].TotalScore
+	})
+	for i := range results {
+		if i > 0 && results[i].TotalScore == results[i-1].TotalScore && results[i].Forfeited == results[i-1].Forfeited {
+			results[i].Rank = results[i-1].Rank
+		} else {
+			results[i].Rank = i + 1
+		}
+	}
+}
+
+// StartNextRound prepares the game for the next round
+// Rotates dealer clockwise, resets round scores, and deals new cards
+func StartNextRound(game *Game) error {
+	return StartNextRoundWithRand(game, nil)
+}
+
+// StartNextRoundWithRand starts the next round shuffled with the given source of randomness
+func StartNextRoundWithRand(game *Game, rng *rand.Rand) error {
+	// Increment round number
+	game.Round++
+
+	// Rotate dealer clockwise
+	game.DealerIndex = (game.DealerIndex + 1) % len(game.Players)
+
+	// Reset round scores
+	for _, player := range game.Players {
+		player.RoundScore = 0
+	}
+	for _, player := range game.Forfeited {
+		player.RoundScore = 0
+	}
+
+	// Initialize new round with fresh cards
+	if err := initializeRound(game, rng); err != nil {
+		return err
+	}
+
+	// Set current player to left of dealer (after dealing)
+	game.CurrentPlayerIndex = (game.DealerIndex + 1) % len(game.Players)
+	return nil
+}
+
+func formatWildClearMessage(value string) string {
+	return fmt.Sprintf("Cleared by %s!", value)
+}
+
+func formatSetClearMessage(count int, value string) string {
+	return fmt.Sprintf("Cleared by %d %ss!", count, value)
+}
@@ -1,11 +1,9 @@
-package services
+package engine
 
 import (
 	"fmt"
 	"strings"
 	"testing"
-
-	"github.com/thben/clearthedeck/internal/models"
 )
 
 func TestStartGame(t *testing.T) {
@@ -23,19 +21,22 @@ func TestStartGame(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create players
-			players := make([]*models.Player, tt.playerCount)
+			players := make([]*Player, tt.playerCount)
 			for i := 0; i < tt.playerCount; i++ {
-				players[i] = &models.Player{
+				players[i] = &Player{
 					ID:             fmt.Sprintf("player-%d", i),
 					Name:           fmt.Sprintf("Player %d", i),
-					Hand:           []*models.Card{},
-					TableCardsUp:   []*models.Card{},
-					TableCardsDown: []*models.Card{},
+					Hand:           []*Card{},
+					TableCardsUp:   []*Card{},
+					TableCardsDown: []*Card{},
 				}
 			}
 
 			// Start game
-			game := StartGame(players)
+			game, err := StartGame(players)
+			if err != nil {
+				t.Fatalf("StartGame returned error: %v", err)
+			}
 
 			// Verify game was created
 			if game == nil {
@@ -94,52 +95,124 @@ func TestStartGameInvalidPlayerCount(t *testing.T) {
 		name        string
 		playerCount int
 	}{
-		{"Too few players", 2},
-		{"Too many players", 11},
+		{"Too few players", 1},
+		{"Too many players", 17},
 		{"No players", 0},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			defer func() {
-				if r := recover(); r == nil {
-					t.Errorf("StartGame should have panicked for invalid player count")
-				}
-			}()
-
 			// Create players
-			players := make([]*models.Player, tt.playerCount)
+			players := make([]*Player, tt.playerCount)
 			for i := 0; i < tt.playerCount; i++ {
-				players[i] = &models.Player{
+				players[i] = &Player{
 					ID:   fmt.Sprintf("player-%d", i),
 					Name: fmt.Sprintf("Player %d", i),
 				}
 			}
 
-			StartGame(players)
+			game, err := StartGame(players)
+			if err == nil {
+				t.Errorf("StartGame should have returned an error for %d players", tt.playerCount)
+			}
+			if game != nil {
+				t.Errorf("StartGame should not return a game for %d players", tt.playerCount)
+			}
+		})
+	}
+}
+
+func TestStartGameTableVariants(t *testing.T) {
+	tests := []struct {
+		name         string
+		playerCount  int
+		expectedHand int
+		expectedDeck int
+	}{
+		{"2 players use one deck", 2, 12, 52},
+		{"11 players use a large table", 11, 8, 260},
+		{"16 players use a large table", 16, 8, 312},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			players := make([]*Player, tt.playerCount)
+			for i := 0; i < tt.playerCount; i++ {
+				players[i] = &Player{ID: fmt.Sprintf("player-%d", i)}
+			}
+
+			game, err := StartGame(players)
+			if err != nil {
+				t.Fatalf("StartGame returned error: %v", err)
+			}
+
+			total := len(game.DiscardPile)
+			for i, player := range game.Players {
+				if len(player.Hand) != tt.expectedHand {
+					t.Errorf("Player %d has %d hand cards, expected %d", i, len(player.Hand), tt.expectedHand)
+				}
+				total += len(player.Hand) + len(player.TableCardsUp) + len(player.TableCardsDown)
+			}
+			if total != tt.expectedDeck {
+				t.Errorf("Game uses %d cards, expected %d", total, tt.expectedDeck)
+			}
 		})
 	}
 }
 
+func TestStartGameWithConfig(t *testing.T) {
+	players := make([]*Player, 4)
+	for i := range players {
+		players[i] = &Player{ID: fmt.Sprintf("player-%d", i)}
+	}
+
+	t.Run("Deals configured area sizes", func(t *testing.T) {
+		game, err := StartGameWithConfig(players, DealConfig{Decks: 1, FaceDown: 3, FaceUp: 3, Hand: 6})
+		if err != nil {
+			t.Fatalf("StartGameWithConfig returned error: %v", err)
+		}
+		for i, player := range game.Players {
+			if len(player.TableCardsDown) != 3 || len(player.TableCardsUp) != 3 || len(player.Hand) != 6 {
+				t.Errorf("Player %d dealt %d/%d/%d, expected 3/3/6", i,
+					len(player.TableCardsDown), len(player.TableCardsUp), len(player.Hand))
+			}
+		}
+		if len(game.DiscardPile) != 52-4*12 {
+			t.Errorf("Discard pile has %d cards, expected %d", len(game.DiscardPile), 52-4*12)
+		}
+	})
+
+	t.Run("Rejects a deck too small for the deal", func(t *testing.T) {
+		fresh := make([]*Player, 4)
+		for i := range fresh {
+			fresh[i] = &Player{ID: fmt.Sprintf("player-%d", i)}
+		}
+		_, err := StartGameWithConfig(fresh, DealConfig{Decks: 1, FaceDown: 4, FaceUp: 4, Hand: 12})
+		if err == nil {
+			t.Error("Expected error when one deck cannot cover 4 players with 20 cards")
+		}
+	})
+}
+
 func TestPlayCards(t *testing.T) {
 	t.Run("Valid play updates center pile", func(t *testing.T) {
 		// Create a simple game with 3 players
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:   "player-1",
 				Name: "Player 1",
-				Hand: []*models.Card{
+				Hand: []*Card{
 					{ID: "card-1", Suit: "Hearts", Value: "5"},
 					{ID: "card-2", Suit: "Diamonds", Value: "5"},
 				},
-				TableCardsUp:   []*models.Card{},
-				TableCardsDown: []*models.Card{},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{},
 			},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
 
@@ -162,27 +235,27 @@ func TestPlayCards(t *testing.T) {
 	})
 
 	t.Run("Face-up over-value play allowed while hand still has cards", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:   "player-1",
 				Name: "Player 1",
-				Hand: []*models.Card{
+				Hand: []*Card{
 					{ID: "hand-low", Suit: "Clubs", Value: "3"},
 					{ID: "hand-high", Suit: "Hearts", Value: "K"},
 				},
-				TableCardsUp: []*models.Card{
+				TableCardsUp: []*Card{
 					{ID: "up-1", Suit: "Spades", Value: "9"},
 				},
-				TableCardsDown: []*models.Card{},
+				TableCardsDown: []*Card{},
 			},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
-		game.CenterPile = []*models.Card{
+		game.CenterPile = []*Card{
 			{ID: "center-1", Suit: "Diamonds", Value: "4"},
 			{ID: "center-2", Suit: "Clubs", Value: "5"},
 		}
@@ -213,21 +286,21 @@ func TestPlayCards(t *testing.T) {
 	})
 
 	t.Run("Invalid play returns error when card missing", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:   "player-1",
 				Name: "Player 1",
-				Hand: []*models.Card{
+				Hand: []*Card{
 					{ID: "card-1", Suit: "Hearts", Value: "5"},
 				},
-				TableCardsUp:   []*models.Card{},
-				TableCardsDown: []*models.Card{},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{},
 			},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
 
@@ -240,22 +313,22 @@ func TestPlayCards(t *testing.T) {
 	})
 
 	t.Run("Playing cards removes from hand", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:   "player-1",
 				Name: "Player 1",
-				Hand: []*models.Card{
+				Hand: []*Card{
 					{ID: "card-1", Suit: "Hearts", Value: "3"},
 					{ID: "card-2", Suit: "Diamonds", Value: "7"},
 				},
-				TableCardsUp:   []*models.Card{},
-				TableCardsDown: []*models.Card{},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{},
 			},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
 
@@ -276,24 +349,24 @@ func TestPlayCards(t *testing.T) {
 	})
 
 	t.Run("Set detection triggers clear", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:   "player-1",
 				Name: "Player 1",
-				Hand: []*models.Card{
+				Hand: []*Card{
 					{ID: "card-1", Suit: "Hearts", Value: "5"},
 				},
-				TableCardsUp:   []*models.Card{},
-				TableCardsDown: []*models.Card{},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{},
 			},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
-		game.CenterPile = []*models.Card{
+		game.CenterPile = []*Card{
 			{ID: "center-1", Suit: "Diamonds", Value: "5"},
 			{ID: "center-2", Suit: "Clubs", Value: "5"},
 			{ID: "center-3", Suit: "Spades", Value: "5"},
@@ -323,24 +396,24 @@ func TestPlayCards(t *testing.T) {
 	})
 
 	t.Run("Wild tens trigger clear", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:   "player-1",
 				Name: "Player 1",
-				Hand: []*models.Card{
+				Hand: []*Card{
 					{ID: "card-1", Suit: "Hearts", Value: "10"},
 				},
-				TableCardsUp:   []*models.Card{},
-				TableCardsDown: []*models.Card{},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{},
 			},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
-		game.CenterPile = []*models.Card{
+		game.CenterPile = []*Card{
 			{ID: "center-1", Suit: "Diamonds", Value: "3"},
 		}
 
@@ -371,21 +444,21 @@ func TestPlayCards(t *testing.T) {
 	})
 
 	t.Run("Turn stays with player after clear", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:   "player-1",
 				Name: "Player 1",
-				Hand: []*models.Card{
+				Hand: []*Card{
 					{ID: "card-1", Suit: "Hearts", Value: "10"},
 				},
-				TableCardsUp:   []*models.Card{},
-				TableCardsDown: []*models.Card{},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{},
 			},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
 
@@ -405,24 +478,24 @@ func TestPlayCards(t *testing.T) {
 	})
 
 	t.Run("Over-value play stays on pile and ends turn", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:   "player-1",
 				Name: "Player 1",
-				Hand: []*models.Card{
+				Hand: []*Card{
 					{ID: "card-1", Suit: "Hearts", Value: "9"},
 				},
-				TableCardsUp:   []*models.Card{},
-				TableCardsDown: []*models.Card{},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{},
 			},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
-		game.CenterPile = []*models.Card{
+		game.CenterPile = []*Card{
 			{ID: "center-1", Suit: "Diamonds", Value: "5"},
 			{ID: "center-2", Suit: "Clubs", Value: "6"},
 		}
@@ -456,27 +529,27 @@ func TestPlayCards(t *testing.T) {
 	})
 
 	t.Run("Over-value set clears, keeps turn, and records message", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:   "player-1",
 				Name: "Player 1",
-				Hand: []*models.Card{
+				Hand: []*Card{
 					{ID: "card-1", Suit: "Hearts", Value: "9"},
 					{ID: "card-2", Suit: "Diamonds", Value: "9"},
 					{ID: "card-3", Suit: "Clubs", Value: "9"},
 					{ID: "card-4", Suit: "Spades", Value: "9"},
 				},
-				TableCardsUp:   []*models.Card{},
-				TableCardsDown: []*models.Card{},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{},
 			},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
-		game.CenterPile = []*models.Card{
+		game.CenterPile = []*Card{
 			{ID: "center-1", Suit: "Diamonds", Value: "5"},
 		}
 
@@ -502,28 +575,28 @@ func TestPlayCards(t *testing.T) {
 	})
 
 	t.Run("Over-value five-card set clears with pluralized message", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:   "player-1",
 				Name: "Player 1",
-				Hand: []*models.Card{
+				Hand: []*Card{
 					{ID: "card-1", Suit: "Hearts", Value: "7"},
 					{ID: "card-2", Suit: "Diamonds", Value: "7"},
 					{ID: "card-3", Suit: "Clubs", Value: "7"},
 					{ID: "card-4", Suit: "Spades", Value: "7"},
 					{ID: "card-5", Suit: "Hearts", Value: "7"},
 				},
-				TableCardsUp:   []*models.Card{},
-				TableCardsDown: []*models.Card{},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{},
 			},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
-		game.CenterPile = []*models.Card{{ID: "center-1", Suit: "Diamonds", Value: "3"}}
+		game.CenterPile = []*Card{{ID: "center-1", Suit: "Diamonds", Value: "3"}}
 
 		err := PlayCards(game, "player-1", []string{"card-1", "card-2", "card-3", "card-4", "card-5"}, false)
 		if err != nil {
@@ -549,21 +622,21 @@ func TestPlayCards(t *testing.T) {
 
 func TestClearDeck(t *testing.T) {
 	t.Run("Moves center to discard", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{ID: "player-1", Name: "Player 1"},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
-		game.CenterPile = []*models.Card{
+		game.CenterPile = []*Card{
 			{ID: "center-1", Suit: "Hearts", Value: "5"},
 			{ID: "center-2", Suit: "Diamonds", Value: "5"},
 			{ID: "center-3", Suit: "Clubs", Value: "5"},
 		}
-		game.DiscardPile = []*models.Card{
+		game.DiscardPile = []*Card{
 			{ID: "discard-1", Suit: "Spades", Value: "3"},
 		}
 
@@ -585,16 +658,16 @@ func TestClearDeck(t *testing.T) {
 	})
 
 	t.Run("Empties center pile", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{ID: "player-1", Name: "Player 1"},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
-		game.CenterPile = []*models.Card{
+		game.CenterPile = []*Card{
 			{ID: "center-1", Suit: "Hearts", Value: "10"},
 		}
 
@@ -606,16 +679,16 @@ func TestClearDeck(t *testing.T) {
 	})
 
 	t.Run("Keeps current player (additional turn)", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{ID: "player-1", Name: "Player 1"},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 1
-		game.CenterPile = []*models.Card{
+		game.CenterPile = []*Card{
 			{ID: "center-1", Suit: "Hearts", Value: "10"},
 		}
 
@@ -631,20 +704,20 @@ func TestClearDeck(t *testing.T) {
 
 func TestPickupPile(t *testing.T) {
 	t.Run("Moves center pile to hand", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:   "player-1",
 				Name: "Player 1",
-				Hand: []*models.Card{},
+				Hand: []*Card{},
 			},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
-		game.CenterPile = []*models.Card{
+		game.CenterPile = []*Card{
 			{ID: "center-1", Suit: "Hearts", Value: "5"},
 			{ID: "center-2", Suit: "Diamonds", Value: "7"},
 			{ID: "center-3", Suit: "Clubs", Value: "9"},
@@ -664,20 +737,20 @@ func TestPickupPile(t *testing.T) {
 	})
 
 	t.Run("Keeps current player (additional turn)", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:   "player-1",
 				Name: "Player 1",
-				Hand: []*models.Card{},
+				Hand: []*Card{},
 			},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 1
-		game.CenterPile = []*models.Card{
+		game.CenterPile = []*Card{
 			{ID: "center-1", Suit: "Hearts", Value: "5"},
 		}
 
@@ -694,13 +767,13 @@ func TestPickupPile(t *testing.T) {
 	})
 
 	t.Run("Returns error for invalid player", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{ID: "player-1", Name: "Player 1"},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 
 		err := PickupPile(game, "invalid-player")
@@ -712,13 +785,13 @@ func TestPickupPile(t *testing.T) {
 
 func TestFlipFaceDown(t *testing.T) {
 	t.Run("Valid flip plays card", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:           "player-1",
 				Name:         "Player 1",
-				Hand:         []*models.Card{},
-				TableCardsUp: []*models.Card{},
-				TableCardsDown: []*models.Card{
+				Hand:         []*Card{},
+				TableCardsUp: []*Card{},
+				TableCardsDown: []*Card{
 					{ID: "fd-1", Suit: "Hearts", Value: "3"},
 					{ID: "fd-2", Suit: "Clubs", Value: "4"},
 				},
@@ -727,10 +800,10 @@ func TestFlipFaceDown(t *testing.T) {
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
-		game.CenterPile = []*models.Card{{ID: "c1", Suit: "Diamonds", Value: "5"}}
+		game.CenterPile = []*Card{{ID: "c1", Suit: "Diamonds", Value: "5"}}
 
 		err := FlipFaceDown(game, "player-1", "fd-1")
 		if err != nil {
@@ -749,19 +822,19 @@ func TestFlipFaceDown(t *testing.T) {
 	})
 
 	t.Run("Cannot flip with cards in hand", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:             "player-1",
 				Name:           "Player 1",
-				Hand:           []*models.Card{{ID: "h1", Suit: "Spades", Value: "2"}},
-				TableCardsUp:   []*models.Card{},
-				TableCardsDown: []*models.Card{{ID: "fd-2", Suit: "Hearts", Value: "5"}},
+				Hand:           []*Card{{ID: "h1", Suit: "Spades", Value: "2"}},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{{ID: "fd-2", Suit: "Hearts", Value: "5"}},
 			},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
 
@@ -772,22 +845,22 @@ func TestFlipFaceDown(t *testing.T) {
 	})
 
 	t.Run("Over-value flip stays on pile", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:             "player-1",
 				Name:           "Player 1",
-				Hand:           []*models.Card{},
-				TableCardsUp:   []*models.Card{},
-				TableCardsDown: []*models.Card{{ID: "fd-3", Suit: "Hearts", Value: "7"}},
+				Hand:           []*Card{},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{{ID: "fd-3", Suit: "Hearts", Value: "7"}},
 			},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
-		game.CenterPile = []*models.Card{{ID: "c1", Suit: "Diamonds", Value: "5"}}
+		game.CenterPile = []*Card{{ID: "c1", Suit: "Diamonds", Value: "5"}}
 
 		err := FlipFaceDown(game, "player-1", "fd-3")
 		if err != nil {
@@ -810,15 +883,15 @@ func TestFlipFaceDown(t *testing.T) {
 	})
 
 	t.Run("Flip is rejected when paired face-up still on table", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:   "player-1",
 				Name: "Player 1",
-				Hand: []*models.Card{},
-				TableCardsUp: []*models.Card{
+				Hand: []*Card{},
+				TableCardsUp: []*Card{
 					{ID: "up-locked", Suit: "Spades", Value: "6"},
 				},
-				TableCardsDown: []*models.Card{
+				TableCardsDown: []*Card{
 					{ID: "fd-locked", Suit: "Hearts", Value: "6"},
 				},
 			},
@@ -826,7 +899,7 @@ func TestFlipFaceDown(t *testing.T) {
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
 
@@ -846,22 +919,22 @@ func TestFlipFaceDown(t *testing.T) {
 	})
 
 	t.Run("Wild ten clears deck", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:             "player-1",
 				Name:           "Player 1",
-				Hand:           []*models.Card{},
-				TableCardsUp:   []*models.Card{},
-				TableCardsDown: []*models.Card{{ID: "fd-10", Suit: "Hearts", Value: "10"}},
+				Hand:           []*Card{},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{{ID: "fd-10", Suit: "Hearts", Value: "10"}},
 			},
 			{ID: "player-2", Name: "Player 2"},
 			{ID: "player-3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.CurrentPlayerIndex = 0
-		game.CenterPile = []*models.Card{{ID: "c1", Suit: "Diamonds", Value: "5"}}
+		game.CenterPile = []*Card{{ID: "c1", Suit: "Diamonds", Value: "5"}}
 
 		err := FlipFaceDown(game, "player-1", "fd-10")
 		if err != nil {
@@ -879,11 +952,11 @@ func TestFlipFaceDown(t *testing.T) {
 
 func TestCheckWinCondition(t *testing.T) {
 	t.Run("Player with no cards wins", func(t *testing.T) {
-		player := &models.Player{
+		player := &Player{
 			ID:             "p1",
-			Hand:           []*models.Card{},
-			TableCardsUp:   []*models.Card{},
-			TableCardsDown: []*models.Card{},
+			Hand:           []*Card{},
+			TableCardsUp:   []*Card{},
+			TableCardsDown: []*Card{},
 		}
 
 		hasWon := CheckWinCondition(player)
@@ -893,11 +966,11 @@ func TestCheckWinCondition(t *testing.T) {
 	})
 
 	t.Run("Player with hand cards has not won", func(t *testing.T) {
-		player := &models.Player{
+		player := &Player{
 			ID:             "p1",
-			Hand:           []*models.Card{{ID: "c1", Value: "5"}},
-			TableCardsUp:   []*models.Card{},
-			TableCardsDown: []*models.Card{},
+			Hand:           []*Card{{ID: "c1", Value: "5"}},
+			TableCardsUp:   []*Card{},
+			TableCardsDown: []*Card{},
 		}
 
 		hasWon := CheckWinCondition(player)
@@ -907,11 +980,11 @@ func TestCheckWinCondition(t *testing.T) {
 	})
 
 	t.Run("Player with face-up cards has not won", func(t *testing.T) {
-		player := &models.Player{
+		player := &Player{
 			ID:             "p1",
-			Hand:           []*models.Card{},
-			TableCardsUp:   []*models.Card{{ID: "c1", Value: "5"}},
-			TableCardsDown: []*models.Card{},
+			Hand:           []*Card{},
+			TableCardsUp:   []*Card{{ID: "c1", Value: "5"}},
+			TableCardsDown: []*Card{},
 		}
 
 		hasWon := CheckWinCondition(player)
@@ -921,11 +994,11 @@ func TestCheckWinCondition(t *testing.T) {
 	})
 
 	t.Run("Player with face-down cards has not won", func(t *testing.T) {
-		player := &models.Player{
+		player := &Player{
 			ID:             "p1",
-			Hand:           []*models.Card{},
-			TableCardsUp:   []*models.Card{},
-			TableCardsDown: []*models.Card{{ID: "c1", Value: "5"}},
+			Hand:           []*Card{},
+			TableCardsUp:   []*Card{},
+			TableCardsDown: []*Card{{ID: "c1", Value: "5"}},
 		}
 
 		hasWon := CheckWinCondition(player)
@@ -937,31 +1010,31 @@ func TestCheckWinCondition(t *testing.T) {
 
 func TestEndRound(t *testing.T) {
 	t.Run("Winner gets 0 points", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:             "p1",
 				Name:           "Player 1",
-				Hand:           []*models.Card{},
-				TableCardsUp:   []*models.Card{},
-				TableCardsDown: []*models.Card{},
+				Hand:           []*Card{},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{},
 				RoundScore:     0,
 				TotalScore:     0,
 			},
 			{
 				ID:   "p2",
 				Name: "Player 2",
-				Hand: []*models.Card{
+				Hand: []*Card{
 					{Value: "5"},
 					{Value: "7"},
 				},
-				TableCardsUp:   []*models.Card{},
-				TableCardsDown: []*models.Card{},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{},
 				RoundScore:     0,
 				TotalScore:     0,
 			},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 
 		EndRound(game, "p1")
@@ -978,30 +1051,30 @@ func TestEndRound(t *testing.T) {
 	})
 
 	t.Run("Cumulative score is updated", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:             "p1",
 				Name:           "Player 1",
-				Hand:           []*models.Card{},
-				TableCardsUp:   []*models.Card{},
-				TableCardsDown: []*models.Card{},
+				Hand:           []*Card{},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{},
 				RoundScore:     0,
 				TotalScore:     10, // Previous rounds
 			},
 			{
 				ID:   "p2",
 				Name: "Player 2",
-				Hand: []*models.Card{
+				Hand: []*Card{
 					{Value: "3"},
 				},
-				TableCardsUp:   []*models.Card{},
-				TableCardsDown: []*models.Card{},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{},
 				RoundScore:     0,
 				TotalScore:     20, // Previous rounds
 			},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 
 		EndRound(game, "p1")
@@ -1018,30 +1091,30 @@ func TestEndRound(t *testing.T) {
 	})
 
 	t.Run("All remaining cards are scored", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{
 				ID:             "p1",
 				Name:           "Player 1",
-				Hand:           []*models.Card{},
-				TableCardsUp:   []*models.Card{},
-				TableCardsDown: []*models.Card{},
+				Hand:           []*Card{},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{},
 			},
 			{
 				ID:   "p2",
 				Name: "Player 2",
-				Hand: []*models.Card{
+				Hand: []*Card{
 					{Value: "2"},
 				},
-				TableCardsUp: []*models.Card{
+				TableCardsUp: []*Card{
 					{Value: "K"},
 				},
-				TableCardsDown: []*models.Card{
+				TableCardsDown: []*Card{
 					{Value: "10"},
 				},
 			},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 
 		EndRound(game, "p1")
@@ -1055,13 +1128,13 @@ func TestEndRound(t *testing.T) {
 
 func TestStartNextRound(t *testing.T) {
 	t.Run("Dealer rotates clockwise", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{ID: "p1", Name: "Player 1"},
 			{ID: "p2", Name: "Player 2"},
 			{ID: "p3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.DealerIndex = 0
 
@@ -1077,13 +1150,13 @@ func TestStartNextRound(t *testing.T) {
 	})
 
 	t.Run("Dealer wraps around", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{ID: "p1", Name: "Player 1"},
 			{ID: "p2", Name: "Player 2"},
 			{ID: "p3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.DealerIndex = 2 // Last player
 
@@ -1095,13 +1168,13 @@ func TestStartNextRound(t *testing.T) {
 	})
 
 	t.Run("Round number increments", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{ID: "p1", Name: "Player 1"},
 			{ID: "p2", Name: "Player 2"},
 			{ID: "p3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.Round = 1
 
@@ -1113,13 +1186,13 @@ func TestStartNextRound(t *testing.T) {
 	})
 
 	t.Run("Round scores reset", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{ID: "p1", Name: "Player 1", RoundScore: 10},
 			{ID: "p2", Name: "Player 2", RoundScore: 20},
 			{ID: "p3", Name: "Player 3", RoundScore: 15},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 
 		StartNextRound(game)
@@ -1132,13 +1205,13 @@ func TestStartNextRound(t *testing.T) {
 	})
 
 	t.Run("AfterPickup resets for new round", func(t *testing.T) {
-		players := []*models.Player{
+		players := []*Player{
 			{ID: "p1", Name: "Player 1"},
 			{ID: "p2", Name: "Player 2"},
 			{ID: "p3", Name: "Player 3"},
 		}
 
-		game := models.NewGame("game-1", "ABCD", players)
+		game := NewGame("game-1", "ABCD", players)
 		game.IsStarted = true
 		game.AfterPickup = true
 
@@ -1149,3 +1222,281 @@ func TestStartNextRound(t *testing.T) {
 		}
 	})
 }
+
+func TestPlayCardsWithRuleSet(t *testing.T) {
+	newRulesGame := func(rules RuleSet, hand, up, pile []*Card) *Game {
+		players := []*Player{
+			{ID: "player-1", Name: "Player 1", Hand: hand, TableCardsUp: up, TableCardsDown: []*Card{}},
+			{ID: "player-2", Name: "Player 2", Hand: []*Card{{ID: "x1", Value: "2"}}},
+			{ID: "player-3", Name: "Player 3", Hand: []*Card{{ID: "x2", Value: "2"}}},
+		}
+		game := NewGame("game-1", "ABCD", players)
+		game.IsStarted = true
+		game.Rules = rules
+		game.CenterPile = pile
+		return game
+	}
+
+	t.Run("Strict rules reject over-value plays", func(t *testing.T) {
+		rules, _ := LookupRuleSet(RulesStrict)
+		game := newRulesGame(rules,
+			[]*Card{{ID: "h1", Value: "K"}}, nil,
+			[]*Card{{ID: "c1", Value: "5"}})
+
+		err := PlayCards(game, "player-1", []string{"h1"}, false)
+		if err == nil {
+			t.Fatal("Expected over-value play to be rejected")
+		}
+		if len(game.Players[0].Hand) != 1 {
+			t.Error("Rejected cards should stay in hand")
+		}
+	})
+
+	t.Run("Reset tens stay on the pile and pass the turn", func(t *testing.T) {
+		rules, _ := LookupRuleSet(RulesResetTens)
+		game := newRulesGame(rules,
+			[]*Card{{ID: "h1", Value: "10"}, {ID: "h2", Value: "3"}}, nil,
+			[]*Card{{ID: "c1", Value: "5"}})
+
+		if err := PlayCards(game, "player-1", []string{"h1"}, false); err != nil {
+			t.Fatalf("PlayCards returned error: %v", err)
+		}
+		if len(game.CenterPile) != 2 {
+			t.Errorf("Center pile has %d cards, expected 2", len(game.CenterPile))
+		}
+		if game.CurrentPlayerIndex != 1 {
+			t.Error("Expected turn to pass after a reset ten")
+		}
+		if game.GetLastClearMessage() != "" {
+			t.Error("Reset tens should not clear the pile")
+		}
+
+		// Anything may follow the reset, and it is not an over-value pickup
+		game.Players[1].Hand = []*Card{{ID: "k1", Value: "K"}, {ID: "k2", Value: "2"}}
+		if err := PlayCards(game, "player-2", []string{"k1"}, false); err != nil {
+			t.Fatalf("PlayCards after reset returned error: %v", err)
+		}
+		if len(game.Players[1].Hand) != 1 {
+			t.Error("Playing on a reset ten should not pick up the pile")
+		}
+	})
+
+	t.Run("Minimum set size is read from the rules", func(t *testing.T) {
+		rules := DefaultRuleSet()
+		rules.MinSetSize = 3
+		game := newRulesGame(rules,
+			[]*Card{{ID: "h1", Value: "7"}, {ID: "h2", Value: "2"}}, nil,
+			[]*Card{{ID: "c1", Value: "7"}, {ID: "c2", Value: "7"}})
+
+		if err := PlayCards(game, "player-1", []string{"h1"}, false); err != nil {
+			t.Fatalf("PlayCards returned error: %v", err)
+		}
+		if len(game.CenterPile) != 0 {
+			t.Error("Three sevens should clear with a minimum set size of 3")
+		}
+		if game.GetLastClearMessage() != "Cleared by 3 7s!" {
+			t.Errorf("Unexpected clear message %q", game.GetLastClearMessage())
+		}
+	})
+
+	t.Run("Custom wild rank clears the pile", func(t *testing.T) {
+		rules := DefaultRuleSet()
+		rules.WildRanks = []string{"2"}
+		game := newRulesGame(rules,
+			[]*Card{{ID: "h1", Value: "2"}, {ID: "h2", Value: "10"}}, nil,
+			[]*Card{{ID: "c1", Value: "9"}})
+
+		if err := PlayCards(game, "player-1", []string{"h1"}, false); err != nil {
+			t.Fatalf("PlayCards returned error: %v", err)
+		}
+		if game.GetLastClearMessage() != "Cleared by 2!" {
+			t.Errorf("Unexpected clear message %q", game.GetLastClearMessage())
+		}
+	})
+
+	t.Run("Face-up cards wait for an empty hand when the rules say so", func(t *testing.T) {
+		rules := DefaultRuleSet()
+		rules.FaceUpBeforeHandEmpty = false
+		game := newRulesGame(rules,
+			[]*Card{{ID: "h1", Value: "4"}},
+			[]*Card{{ID: "u1", Value: "6"}, {ID: "u2", Value: "4"}}, nil)
+
+		if err := PlayCards(game, "player-1", []string{"u1"}, false); err == nil {
+			t.Error("Expected face-up play to be rejected while cards remain in hand")
+		}
+		// Playing the last hand card together with a matching face-up card is fine
+		if err := PlayCards(game, "player-1", []string{"h1", "u2"}, false); err != nil {
+			t.Errorf("Expected hand and face-up play to succeed: %v", err)
+		}
+	})
+}
+
+func TestFlipFaceDownWithRuleSet(t *testing.T) {
+	newFlipGame := func(rules RuleSet) *Game {
+		players := []*Player{
+			{
+				ID:             "player-1",
+				Name:           "Player 1",
+				Hand:           []*Card{},
+				TableCardsUp:   []*Card{},
+				TableCardsDown: []*Card{{ID: "fd-1", Value: "K"}, {ID: "fd-2", Value: "3"}},
+			},
+			{ID: "player-2", Name: "Player 2"},
+			{ID: "player-3", Name: "Player 3"},
+		}
+		game := NewGame("game-1", "ABCD", players)
+		game.IsStarted = true
+		game.Rules = rules
+		game.CenterPile = []*Card{{ID: "c1", Value: "5"}}
+		return game
+	}
+
+	t.Run("Invalid flip forces a pickup", func(t *testing.T) {
+		rules, _ := LookupRuleSet(RulesStrict)
+		game := newFlipGame(rules)
+
+		if err := FlipFaceDown(game, "player-1", "fd-1"); err != nil {
+			t.Fatalf("FlipFaceDown returned error: %v", err)
+		}
+		if len(game.Players[0].Hand) != 2 || len(game.CenterPile) != 0 {
+			t.Error("Expected flipped card and pile to be picked up")
+		}
+		if game.CurrentPlayerIndex != 0 {
+			t.Error("Expected player to keep the turn after a forced pickup")
+		}
+	})
+
+	t.Run("Invalid flip without forced pickup only takes the card", func(t *testing.T) {
+		rules, _ := LookupRuleSet(RulesStrict)
+		rules.FlipInvalidPickup = false
+		game := newFlipGame(rules)
+
+		if err := FlipFaceDown(game, "player-1", "fd-1"); err != nil {
+			t.Fatalf("FlipFaceDown returned error: %v", err)
+		}
+		if len(game.Players[0].Hand) != 1 || len(game.CenterPile) != 1 {
+			t.Error("Expected only the flipped card to be taken into hand")
+		}
+		if game.CurrentPlayerIndex != 1 {
+			t.Error("Expected turn to pass")
+		}
+	})
+}
+
+func TestEndRoundWithScoringProfile(t *testing.T) {
+	newScoredGame := func(profileName string) (*Game, []*Player) {
+		players := []*Player{
+			{ID: "p1", Name: "Player 1"},
+			{ID: "p2", Name: "Player 2", Hand: []*Card{{Value: "10"}, {Value: "K"}}},
+		}
+		game := NewGame("game-1", "ABCD", players)
+		game.IsStarted = true
+		profile, ok := LookupScoringProfile(profileName)
+		if !ok {
+			t.Fatalf("Profile %s not found", profileName)
+		}
+		game.Scoring = profile
+		return game, players
+	}
+
+	t.Run("Profile card values are applied", func(t *testing.T) {
+		game, players := newScoredGame(ScoringTens25)
+		EndRound(game, "p1")
+		if players[1].RoundScore != 38 { // 25 + 13
+			t.Errorf("Player 2 round score = %d, expected 38", players[1].RoundScore)
+		}
+	})
+
+	t.Run("Clean finish bonus goes to a winner whose flips all played", func(t *testing.T) {
+		game, players := newScoredGame(ScoringCleanFinish)
+		EndRound(game, "p1")
+		if players[0].RoundScore != -10 || players[0].TotalScore != -10 {
+			t.Errorf("Winner scores = %d/%d, expected -10/-10", players[0].RoundScore, players[0].TotalScore)
+		}
+	})
+
+	t.Run("No bonus after a missed flip", func(t *testing.T) {
+		game, players := newScoredGame(ScoringCleanFinish)
+		players[0].FlipMisses = 1
+		EndRound(game, "p1")
+		if players[0].RoundScore != 0 {
+			t.Errorf("Winner round score = %d, expected 0", players[0].RoundScore)
+		}
+	})
+
+	t.Run("Missed flips are counted", func(t *testing.T) {
+		game, players := newScoredGame(ScoringCleanFinish)
+		players[0].TableCardsDown = []*Card{{ID: "fd-1", Value: "K"}}
+		game.CenterPile = []*Card{{ID: "c1", Value: "3"}}
+		if err := FlipFaceDown(game, "p1", "fd-1"); err != nil {
+			t.Fatalf("FlipFaceDown returned error: %v", err)
+		}
+		if players[0].FlipMisses != 0 {
+			t.Error("An over-value flip under standard rules is still a play")
+		}
+
+		game.Rules, _ = LookupRuleSet(RulesStrict)
+		game.CurrentPlayerIndex = 0
+		players[0].Hand = nil
+		players[0].TableCardsDown = []*Card{{ID: "fd-2", Value: "Q"}}
+		game.CenterPile = []*Card{{ID: "c2", Value: "3"}}
+		if err := FlipFaceDown(game, "p1", "fd-2"); err != nil {
+			t.Fatalf("FlipFaceDown returned error: %v", err)
+		}
+		if players[0].FlipMisses != 1 {
+			t.Errorf("FlipMisses = %d, expected 1", players[0].FlipMisses)
+		}
+	})
+}
+
+func TestEndRoundSummary(t *testing.T) {
+	players := []*Player{
+		{ID: "p1", Name: "Player 1", TotalScore: 30},
+		{
+			ID:             "p2",
+			Name:           "Player 2",
+			Hand:           []*Card{{ID: "h1", Value: "10"}, {ID: "h2", Value: "3"}},
+			TableCardsUp:   []*Card{{ID: "u1", Value: "K"}},
+			TableCardsDown: []*Card{{ID: "d1", Value: "10"}, {ID: "d2", Value: "A"}},
+		},
+		{ID: "p3", Name: "Player 3", Hand: []*Card{{ID: "h3", Value: "7"}}, TotalScore: 23},
+	}
+	game := NewGame("game-1", "ABCD", players)
+	game.IsStarted = true
+
+	EndRound(game, "p1")
+
+	result := game.LastResult()
+	if result == nil {
+		t.Fatal("Expected a round result in the history")
+	}
+	if result.Round != 1 || result.WinnerID != "p1" || result.Scoring != ScoringClassic {
+		t.Errorf("Unexpected round header: %+v", result)
+	}
+
+	// Totals: p1 30, p2 57, p3 30 - ranked lowest first with p1 and p3 tied
+	wantOrder := []string{"p1", "p3", "p2"}
+	wantRanks := []int{1, 1, 3}
+	for i, p := range result.Players {
+		if p.ID != wantOrder[i] || p.Rank != wantRanks[i] {
+			t.Errorf("Position %d = %s rank %d, expected %s rank %d", i, p.ID, p.Rank, wantOrder[i], wantRanks[i])
+		}
+	}
+
+	p2 := result.Players[2]
+	if p2.HandPoints != 23 || p2.FaceUpPoints != 13 || p2.FaceDownPoints != 21 {
+		t.Errorf("Unexpected area points: %+v", p2.ScoreBreakdown)
+	}
+	if p2.TensPenalty != 40 {
+		t.Errorf("Tens penalty = %d, expected 40", p2.TensPenalty)
+	}
+	if p2.RoundScore != 57 || len(p2.FaceDown) != 2 {
+		t.Errorf("Round score = %d with %d revealed cards, expected 57 with 2", p2.RoundScore, len(p2.FaceDown))
+	}
+
+	// A second round adds to the history rather than replacing it
+	EndRound(game, "p3")
+	if len(game.History) != 2 || game.LastResult().WinnerID != "p3" {
+		t.Errorf("Expected two rounds in the history, got %d", len(game.History))
+	}
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Progress tracks whether a round is getting anywhere
+// A round progresses when the cards held by the players reach a new low; repeated table states mean it is cycling
+type Progress struct {
+	Actions        int            `json:"actions"`        // Actions taken this round
+	MinCardsHeld   int            `json:"minCardsHeld"`   // Fewest cards held by all players together so far
+	LastProgressAt int            `json:"lastProgressAt"` // Action count when MinCardsHeld last dropped
+	MaxRepeats     int            `json:"maxRepeats"`     // Most times any table state has been seen
+	seen           map[string]int // Times each table state has been seen
+}
+
+// DefaultStalemateActions is how many actions a round may go without progress before it is ended
+const DefaultStalemateActions = 300
+
+// StalemateRepeats is how many times the same table state may come round before the round is ended
+const StalemateRepeats = 3
+
+// CardsHeld counts the cards still held by the players in hand and on the table
+func (g *Game) CardsHeld() int {
+	total := 0
+	for _, p := range g.Players {
+		total += len(p.Hand) + len(p.TableCardsUp) + len(p.TableCardsDown)
+	}
+	return total
+}
+
+// ResetProgress starts progress tracking afresh for a new round
+func (g *Game) ResetProgress() {
+	g.Progress = Progress{
+		MinCardsHeld: g.CardsHeld(),
+		seen:         make(map[string]int),
+	}
+}
+
+// RoundOver reports whether the current round has already been scored
+func (g *Game) RoundOver() bool {
+	return len(g.History) > 0 && g.History[len(g.History)-1].Round == g.Round
+}
+
+// clone copies the progress so later actions do not change the copy
+func (p Progress) clone() Progress {
+	seen := make(map[string]int, len(p.seen))
+	for key, count := range p.seen {
+		seen[key] = count
+	}
+	p.seen = seen
+	return p
+}
+
+// RecordAction counts an action and notes whether it brought the round any closer to an end
+func (g *Game) RecordAction() {
+	p := &g.Progress
+	if p.seen == nil {
+		p.seen = make(map[string]int)
+	}
+	p.Actions++
+
+	if held := g.CardsHeld(); held < p.MinCardsHeld {
+		p.MinCardsHeld = held
+		p.LastProgressAt = p.Actions
+	}
+
+	key := g.tableState()
+	p.seen[key]++
+	if p.seen[key] > p.MaxRepeats {
+		p.MaxRepeats = p.seen[key]
+	}
+}
+
+// ActionsWithoutProgress returns how many actions have passed since the cards held last reached a new low
+func (g *Game) ActionsWithoutProgress() int {
+	return g.Progress.Actions - g.Progress.LastProgressAt
+}
+
+// tableState describes who is to play and where every card is, ignoring the order cards are held in
+func (g *Game) tableState() string {
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(g.CurrentPlayerIndex))
+	b.WriteString("|")
+	for _, c := range g.CenterPile {
+		b.WriteString(c.ID)
+		b.WriteString(",")
+	}
+	for _, p := range g.Players {
+		ids := make([]string, 0, len(p.Hand)+len(p.TableCardsUp)+len(p.TableCardsDown))
+		for _, area := range [][]*Card{p.Hand, p.TableCardsUp, p.TableCardsDown} {
+			for _, c := range area {
+				ids = append(ids, c.ID)
+			}
+		}
+		sort.Strings(ids)
+		b.WriteString("|")
+		b.WriteString(strings.Join(ids, ","))
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DecodeState reads a game as the server sends it to clients
+// Face-down cards sent hidden have no value; flipping them can be listed but not previewed
+func DecodeState(data []byte) (State, error) {
+	game := NewGame("", "", nil)
+	if err := json.Unmarshal(data, game); err != nil {
+		return State{}, fmt.Errorf("invalid game: %w", err)
+	}
+	if len(game.Players) == 0 {
+		return State{}, fmt.Errorf("invalid game: no players")
+	}
+	game.IsStarted = true
+	if game.Progress.Actions == 0 {
+		game.ResetProgress()
+	}
+	return State{game: game}, nil
+}
+
+// Validate reports why an action would be rejected, or nil if it is legal
+func Validate(s State, a Action) error {
+	_, _, err := Apply(s, a)
+	return err
+}
+
+// LegalActions lists the moves the player may make, or nothing when it is not their turn
+// Plays of each value are listed once per card count, using the player's cards in hand before face-up cards
+func LegalActions(s State, playerID string) []Action {
+	if s.game == nil || s.RoundOver() || s.CurrentPlayerID() != playerID {
+		return nil
+	}
+	player, err := s.player(playerID)
+	if err != nil {
+		return nil
+	}
+
+	var candidates []Action
+	for _, group := range valueGroups(player) {
+		for n := 1; n <= len(group); n++ {
+			candidates = append(candidates, Action{Type: ActionPlay, PlayerID: playerID, CardIDs: append([]string(nil), group[:n]...)})
+		}
+	}
+	for _, card := range player.TableCardsDown {
+		candidates = append(candidates, Action{Type: ActionFlip, PlayerID: playerID, CardIDs: []string{card.ID}})
+	}
+	candidates = append(candidates, Action{Type: ActionPickup, PlayerID: playerID})
+
+	legal := make([]Action, 0, len(candidates))
+	for _, a := range candidates {
+		if Validate(s, a) == nil {
+			legal = append(legal, a)
+		}
+	}
+	return legal
+}
+
+// Score returns the points the player's remaining cards are worth under the game's scoring profile
+func Score(s State, playerID string) (ScoreBreakdown, error) {
+	if s.game == nil {
+		return ScoreBreakdown{}, ErrNoGame
+	}
+	player, err := s.player(playerID)
+	if err != nil {
+		return ScoreBreakdown{}, err
+	}
+	return ScoreAreas(player, s.game.ActiveScoring()), nil
+}
+
+// valueGroups groups the IDs of the player's hand and face-up cards by value, hand cards first
+func valueGroups(player *Player) [][]string {
+	index := make(map[string]int)
+	var groups [][]string
+	for _, area := range [][]*Card{player.Hand, player.TableCardsUp} {
+		for _, c := range area {
+			i, ok := index[c.Value]
+			if !ok {
+				i = len(groups)
+				index[c.Value] = i
+				groups = append(groups, nil)
+			}
+			groups[i] = append(groups[i], c.ID)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const clientGame = `{
+	"players": [
+		{"id": "p1", "name": "Player 1",
+			"hand": [{"id": "h1", "suit": "Hearts", "value": "7"}, {"id": "h2", "suit": "Spades", "value": "7"}, {"id": "h3", "suit": "Spades", "value": "K"}],
+			"tableCardsUp": [], "tableCardsDown": [{"id": "d1", "hidden": true}]},
+		{"id": "p2", "name": "Player 2", "hand": [{"id": "x1", "suit": "Clubs", "value": "3"}], "tableCardsUp": [], "tableCardsDown": []}
+	],
+	"centerPile": [{"id": "c1", "suit": "Hearts", "value": "9"}],
+	"currentPlayerIndex": 0,
+	"round": 1,
+	"rules": {"name": "strict", "wildRanks": ["10"], "minSetSize": 4, "wildClears": true, "allowOverValue": false}
+}`
+
+func TestDecodeState(t *testing.T) {
+	s, err := DecodeState([]byte(clientGame))
+
+	require.NoError(t, err)
+	assert.Equal(t, "p1", s.CurrentPlayerID())
+	assert.Equal(t, "strict", s.Rules().Name)
+	assert.False(t, s.RoundOver())
+
+	_, err = DecodeState([]byte(`{"players": []}`))
+	assert.Error(t, err)
+	_, err = DecodeState([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestLegalActions(t *testing.T) {
+	s, err := DecodeState([]byte(clientGame))
+	require.NoError(t, err)
+
+	t.Run("lists the plays the rules allow", func(t *testing.T) {
+		actions := LegalActions(s, "p1")
+
+		var plays [][]string
+		hasPickup := false
+		for _, a := range actions {
+			switch a.Type {
+			case ActionPlay:
+				plays = append(plays, a.CardIDs)
+			case ActionPickup:
+				hasPickup = true
+			}
+		}
+		// Strict rules forbid playing the king over the nine
+		assert.Equal(t, [][]string{{"h1"}, {"h1", "h2"}}, plays)
+		assert.True(t, hasPickup)
+	})
+
+	t.Run("lists nothing when it is not the player's turn", func(t *testing.T) {
+		assert.Empty(t, LegalActions(s, "p2"))
+	})
+
+	t.Run("every listed action is accepted", func(t *testing.T) {
+		for _, a := range LegalActions(s, "p1") {
+			assert.NoError(t, Validate(s, a), a)
+		}
+	})
+}
+
+func TestScore(t *testing.T) {
+	s := newTestState([]*Card{{ID: "h1", Value: "10"}, {ID: "h2", Value: "K"}}, nil)
+
+	breakdown, err := Score(s, "p1")
+
+	require.NoError(t, err)
+	assert.Equal(t, 33, breakdown.HandPoints)
+	assert.Equal(t, 20, breakdown.TensPenalty)
+	assert.Equal(t, 33, breakdown.Total())
+
+	_, err = Score(s, "nobody")
+	assert.Error(t, err)
+}
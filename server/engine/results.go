@@ -0,0 +1,46 @@
+package engine
+
+// ScoreBreakdown splits a player's penalty points by where the cards were left
+type ScoreBreakdown struct {
+	HandPoints     int `json:"handPoints"`
+	FaceUpPoints   int `json:"faceUpPoints"`
+	FaceDownPoints int `json:"faceDownPoints"`
+	TensPenalty    int `json:"tensPenalty"` // Points from tens, already included in the area points
+	Bonus          int `json:"bonus"`       // Points taken off the round score
+}
+
+// Total returns the round score the breakdown adds up to
+func (b ScoreBreakdown) Total() int {
+	return b.HandPoints + b.FaceUpPoints + b.FaceDownPoints - b.Bonus
+}
+
+// PlayerResult is one player's line in a round summary
+type PlayerResult struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Hand     []*Card `json:"hand"`
+	FaceUp   []*Card `json:"faceUp"`
+	FaceDown []*Card `json:"faceDown"` // Revealed at the end of the round
+	ScoreBreakdown
+	RoundScore int  `json:"roundScore"`
+	TotalScore int  `json:"totalScore"`
+	Rank       int  `json:"rank"`                // Standing by total score; tied players share a rank
+	Forfeited  bool `json:"forfeited,omitempty"` // Player left the game before it ended
+}
+
+// RoundResult summarises how a round was scored
+type RoundResult struct {
+	Round    int            `json:"round"`
+	WinnerID string         `json:"winnerId"` // Empty when the round ended without a winner
+	Reason   string         `json:"reason"`   // Why the round ended
+	Scoring  string         `json:"scoring"`
+	Players  []PlayerResult `json:"players"`
+}
+
+// LastResult returns the summary of the most recently finished round, or nil before any round ends
+func (g *Game) LastResult() *RoundResult {
+	if len(g.History) == 0 {
+		return nil
+	}
+	return &g.History[len(g.History)-1]
+}
@@ -0,0 +1,88 @@
+package engine
+
+// RuleSet holds the house rules a game is played with
+type RuleSet struct {
+	Name                  string   `json:"name"`
+	WildRanks             []string `json:"wildRanks"`             // Ranks that can be played on anything
+	MinSetSize            int      `json:"minSetSize"`            // Matching cards on top of the pile needed to clear it
+	WildClears            bool     `json:"wildClears"`            // Wilds clear the pile; otherwise they reset it so any card may follow
+	AllowOverValue        bool     `json:"allowOverValue"`        // Playing above the top card is allowed (the rest of the pile is picked up)
+	FlipInvalidPickup     bool     `json:"flipInvalidPickup"`     // A flipped card that cannot be played forces picking up the pile
+	FaceUpBeforeHandEmpty bool     `json:"faceUpBeforeHandEmpty"` // Face-up cards are playable while cards remain in hand
+	PickupAllowsAnyPlay   bool     `json:"pickupAllowsAnyPlay"`   // After picking up the pile any card may be played
+}
+
+// Rule set names
+const (
+	RulesStandard  = "standard"
+	RulesStrict    = "strict"
+	RulesResetTens = "resetTens"
+	RulesCustom    = "custom"
+)
+
+// DefaultRuleSet returns the standard rules: tens are wild and clear the pile, sets of four clear,
+// over-value plays stay on the pile, and face-up cards play like cards in hand
+func DefaultRuleSet() RuleSet {
+	return RuleSet{
+		Name:                  RulesStandard,
+		WildRanks:             []string{"10"},
+		MinSetSize:            4,
+		WildClears:            true,
+		AllowOverValue:        true,
+		FlipInvalidPickup:     true,
+		FaceUpBeforeHandEmpty: true,
+		PickupAllowsAnyPlay:   true,
+	}
+}
+
+// LookupRuleSet returns a named rule preset
+func LookupRuleSet(name string) (RuleSet, bool) {
+	rules := DefaultRuleSet()
+	switch name {
+	case RulesStandard:
+	case RulesStrict:
+		// Equal-or-lower is enforced: stuck players must pick up the pile
+		rules.Name = RulesStrict
+		rules.AllowOverValue = false
+		rules.FaceUpBeforeHandEmpty = false
+	case RulesResetTens:
+		rules.Name = RulesResetTens
+		rules.WildClears = false
+	default:
+		return RuleSet{}, false
+	}
+	return rules, true
+}
+
+// IsWild reports whether cards of the value are wild under these rules
+func (r RuleSet) IsWild(value string) bool {
+	for _, rank := range r.WildRanks {
+		if rank == value {
+			return true
+		}
+	}
+	return false
+}
+
+// SameRules reports whether both rule sets play the same, ignoring their names
+func (r RuleSet) SameRules(other RuleSet) bool {
+	if len(r.WildRanks) != len(other.WildRanks) {
+		return false
+	}
+	for _, rank := range r.WildRanks {
+		if !other.IsWild(rank) {
+			return false
+		}
+	}
+	return r.MinSetSize == other.MinSetSize &&
+		r.WildClears == other.WildClears &&
+		r.AllowOverValue == other.AllowOverValue &&
+		r.FlipInvalidPickup == other.FlipInvalidPickup &&
+		r.FaceUpBeforeHandEmpty == other.FaceUpBeforeHandEmpty &&
+		r.PickupAllowsAnyPlay == other.PickupAllowsAnyPlay
+}
+
+// IsStandard reports whether the rules are the standard ones
+func (r RuleSet) IsStandard() bool {
+	return r.SameRules(DefaultRuleSet())
+}
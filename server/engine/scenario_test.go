@@ -0,0 +1,64 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/scenario"
+)
+
+// setUp builds the game a scenario describes
+func setUp(t *testing.T, text string) *engine.Game {
+	t.Helper()
+	s, err := scenario.Parse([]byte(text))
+	if err != nil {
+		t.Fatalf("Invalid scenario: %v", err)
+	}
+	game, err := s.Game()
+	if err != nil {
+		t.Fatalf("Scenario could not be set up: %v", err)
+	}
+	return game
+}
+
+func TestScenarioOverValueKeepsMatchingCards(t *testing.T) {
+	game := setUp(t, `
+current: A
+pile: [9H, 4C, 9D, 5S]
+players:
+  - {name: A, hand: [9S, 3C]}
+  - {name: B, hand: [2H]}
+`)
+
+	if err := engine.PlayCards(game, "A", []string{"A-hand-0"}, false); err != nil {
+		t.Fatalf("PlayCards returned error: %v", err)
+	}
+
+	// The three 9s stay on the pile and the 4 and 5 go to A
+	if len(game.CenterPile) != 3 {
+		t.Errorf("Center pile has %d cards, expected the three 9s", len(game.CenterPile))
+	}
+	if hand := game.Players[0].Hand; len(hand) != 3 {
+		t.Errorf("A holds %d cards, expected 3", len(hand))
+	}
+}
+
+func TestScenarioFourthKingClears(t *testing.T) {
+	game := setUp(t, `
+current: B
+pile: [KD, KC, KH]
+players:
+  - {name: A, faceUp: [QS], faceDown: [2C]}
+  - {name: B, hand: [KS, 3D]}
+`)
+
+	if err := engine.PlayCards(game, "B", []string{"B-hand-0"}, false); err != nil {
+		t.Fatalf("PlayCards returned error: %v", err)
+	}
+	if len(game.CenterPile) != 0 {
+		t.Errorf("Center pile has %d cards, expected it to be cleared", len(game.CenterPile))
+	}
+	if current := game.GetCurrentPlayer().ID; current != "B" {
+		t.Errorf("Current player is %s, expected B to go again", current)
+	}
+}
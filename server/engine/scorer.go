@@ -0,0 +1,36 @@
+package engine
+
+// GetCardPointValue returns the point value of a card under classic scoring
+// A=1, 2-9=face value, 10=20 points, J=11, Q=12, K=13
+func GetCardPointValue(card *Card) int {
+	return DefaultScoringProfile().PointValue(card.Value)
+}
+
+// CalculatePlayerScore calculates total points for a player's remaining cards under a scoring profile
+// Includes cards in hand, face-up, and face-down
+func CalculatePlayerScore(player *Player, profile ScoringProfile) int {
+	return ScoreAreas(player, profile).Total()
+}
+
+// ScoreAreas scores a player's remaining cards area by area
+func ScoreAreas(player *Player, profile ScoringProfile) ScoreBreakdown {
+	var breakdown ScoreBreakdown
+	areas := []struct {
+		cards  []*Card
+		points *int
+	}{
+		{player.Hand, &breakdown.HandPoints},
+		{player.TableCardsUp, &breakdown.FaceUpPoints},
+		{player.TableCardsDown, &breakdown.FaceDownPoints},
+	}
+	for _, area := range areas {
+		for _, card := range area.cards {
+			points := profile.PointValue(card.Value)
+			*area.points += points
+			if card.Value == "10" {
+				breakdown.TensPenalty += points
+			}
+		}
+	}
+	return breakdown
+}
@@ -1,80 +1,78 @@
-package utils
+package engine
 
 import (
 	"testing"
-
-	"github.com/thben/clearthedeck/internal/models"
 )
 
 func TestGetCardPointValue(t *testing.T) {
 	tests := []struct {
 		name          string
-		card          *models.Card
+		card          *Card
 		expectedValue int
 	}{
 		{
 			name:          "Ace is 1 point",
-			card:          &models.Card{Value: "A"},
+			card:          &Card{Value: "A"},
 			expectedValue: 1,
 		},
 		{
 			name:          "Two is 2 points",
-			card:          &models.Card{Value: "2"},
+			card:          &Card{Value: "2"},
 			expectedValue: 2,
 		},
 		{
 			name:          "Three is 3 points",
-			card:          &models.Card{Value: "3"},
+			card:          &Card{Value: "3"},
 			expectedValue: 3,
 		},
 		{
 			name:          "Four is 4 points",
-			card:          &models.Card{Value: "4"},
+			card:          &Card{Value: "4"},
 			expectedValue: 4,
 		},
 		{
 			name:          "Five is 5 points",
-			card:          &models.Card{Value: "5"},
+			card:          &Card{Value: "5"},
 			expectedValue: 5,
 		},
 		{
 			name:          "Six is 6 points",
-			card:          &models.Card{Value: "6"},
+			card:          &Card{Value: "6"},
 			expectedValue: 6,
 		},
 		{
 			name:          "Seven is 7 points",
-			card:          &models.Card{Value: "7"},
+			card:          &Card{Value: "7"},
 			expectedValue: 7,
 		},
 		{
 			name:          "Eight is 8 points",
-			card:          &models.Card{Value: "8"},
+			card:          &Card{Value: "8"},
 			expectedValue: 8,
 		},
 		{
 			name:          "Nine is 9 points",
-			card:          &models.Card{Value: "9"},
+			card:          &Card{Value: "9"},
 			expectedValue: 9,
 		},
 		{
 			name:          "Ten is 20 points",
-			card:          &models.Card{Value: "10"},
+			card:          &Card{Value: "10"},
 			expectedValue: 20,
 		},
 		{
 			name:          "Jack is 11 points",
-			card:          &models.Card{Value: "J"},
+			card:          &Card{Value: "J"},
 			expectedValue: 11,
 		},
 		{
 			name:          "Queen is 12 points",
-			card:          &models.Card{Value: "Q"},
+			card:          &Card{Value: "Q"},
 			expectedValue: 12,
 		},
 		{
 			name:          "King is 13 points",
-			card:          &models.Card{Value: "K"},
+			card:          &Card{Value: "K"},
 			expectedValue: 13,
 		},
 	}
@@ -91,32 +89,32 @@ func TestGetCardPointValue(t *testing.T) {
 
 func TestCalculatePlayerScore(t *testing.T) {
 	t.Run("Empty player has 0 points", func(t *testing.T) {
-		player := &models.Player{
+		player := &Player{
 			ID:             "p1",
-			Hand:           []*models.Card{},
-			TableCardsUp:   []*models.Card{},
-			TableCardsDown: []*models.Card{},
+			Hand:           []*Card{},
+			TableCardsUp:   []*Card{},
+			TableCardsDown: []*Card{},
 		}
 
-		score := CalculatePlayerScore(player)
+		score := CalculatePlayerScore(player, DefaultScoringProfile())
 		if score != 0 {
 			t.Errorf("Expected 0 points for empty player, got %d", score)
 		}
 	})
 
 	t.Run("Score includes cards in hand", func(t *testing.T) {
-		player := &models.Player{
+		player := &Player{
 			ID: "p1",
-			Hand: []*models.Card{
+			Hand: []*Card{
 				{Value: "5"},
 				{Value: "7"},
 				{Value: "A"},
 			},
-			TableCardsUp:   []*models.Card{},
-			TableCardsDown: []*models.Card{},
+			TableCardsUp:   []*Card{},
+			TableCardsDown: []*Card{},
 		}
 
-		score := CalculatePlayerScore(player)
+		score := CalculatePlayerScore(player, DefaultScoringProfile())
 		// 5 + 7 + 1 = 13
 		if score != 13 {
 			t.Errorf("Expected 13 points, got %d", score)
@@ -124,17 +122,17 @@ func TestCalculatePlayerScore(t *testing.T) {
 	})
 
 	t.Run("Score includes face-up cards", func(t *testing.T) {
-		player := &models.Player{
+		player := &Player{
 			ID:   "p1",
-			Hand: []*models.Card{},
-			TableCardsUp: []*models.Card{
+			Hand: []*Card{},
+			TableCardsUp: []*Card{
 				{Value: "K"},
 				{Value: "Q"},
 			},
-			TableCardsDown: []*models.Card{},
+			TableCardsDown: []*Card{},
 		}
 
-		score := CalculatePlayerScore(player)
+		score := CalculatePlayerScore(player, DefaultScoringProfile())
 		// 13 + 12 = 25
 		if score != 25 {
 			t.Errorf("Expected 25 points, got %d", score)
@@ -142,17 +140,17 @@ func TestCalculatePlayerScore(t *testing.T) {
 	})
 
 	t.Run("Score includes face-down cards", func(t *testing.T) {
-		player := &models.Player{
+		player := &Player{
 			ID:           "p1",
-			Hand:         []*models.Card{},
-			TableCardsUp: []*models.Card{},
-			TableCardsDown: []*models.Card{
+			Hand:         []*Card{},
+			TableCardsUp: []*Card{},
+			TableCardsDown: []*Card{
 				{Value: "3"},
 				{Value: "J"},
 			},
 		}
 
-		score := CalculatePlayerScore(player)
+		score := CalculatePlayerScore(player, DefaultScoringProfile())
 		// 3 + 11 = 14
 		if score != 14 {
 			t.Errorf("Expected 14 points, got %d", score)
@@ -160,20 +158,20 @@ func TestCalculatePlayerScore(t *testing.T) {
 	})
 
 	t.Run("Score includes all card locations", func(t *testing.T) {
-		player := &models.Player{
+		player := &Player{
 			ID: "p1",
-			Hand: []*models.Card{
+			Hand: []*Card{
 				{Value: "2"},
 			},
-			TableCardsUp: []*models.Card{
+			TableCardsUp: []*Card{
 				{Value: "3"},
 			},
-			TableCardsDown: []*models.Card{
+			TableCardsDown: []*Card{
 				{Value: "4"},
 			},
 		}
 
-		score := CalculatePlayerScore(player)
+		score := CalculatePlayerScore(player, DefaultScoringProfile())
 		// 2 + 3 + 4 = 9
 		if score != 9 {
 			t.Errorf("Expected 9 points, got %d", score)
@@ -181,17 +179,17 @@ func TestCalculatePlayerScore(t *testing.T) {
 	})
 
 	t.Run("Ten is worth 20 points", func(t *testing.T) {
-		player := &models.Player{
+		player := &Player{
 			ID: "p1",
-			Hand: []*models.Card{
+			Hand: []*Card{
 				{Value: "10"},
 				{Value: "10"},
 			},
-			TableCardsUp:   []*models.Card{},
-			TableCardsDown: []*models.Card{},
+			TableCardsUp:   []*Card{},
+			TableCardsDown: []*Card{},
 		}
 
-		score := CalculatePlayerScore(player)
+		score := CalculatePlayerScore(player, DefaultScoringProfile())
 		// 20 + 20 = 40
 		if score != 40 {
 			t.Errorf("Expected 40 points for two tens, got %d", score)
@@ -199,26 +197,56 @@ func TestCalculatePlayerScore(t *testing.T) {
 	})
 
 	t.Run("Complex scoring with multiple card types", func(t *testing.T) {
-		player := &models.Player{
+		player := &Player{
 			ID: "p1",
-			Hand: []*models.Card{
+			Hand: []*Card{
 				{Value: "A"},
 				{Value: "10"},
 				{Value: "K"},
 			},
-			TableCardsUp: []*models.Card{
+			TableCardsUp: []*Card{
 				{Value: "5"},
 				{Value: "Q"},
 			},
-			TableCardsDown: []*models.Card{
+			TableCardsDown: []*Card{
 				{Value: "7"},
 			},
 		}
 
-		score := CalculatePlayerScore(player)
+		score := CalculatePlayerScore(player, DefaultScoringProfile())
 		// 1 + 20 + 13 + 5 + 12 + 7 = 58
 		if score != 58 {
 			t.Errorf("Expected 58 points, got %d", score)
 		}
 	})
 }
+
+func TestCalculatePlayerScoreWithProfiles(t *testing.T) {
+	player := &Player{
+		ID:           "p1",
+		Hand:         []*Card{{Value: "10"}, {Value: "K"}},
+		TableCardsUp: []*Card{{Value: "J"}, {Value: "4"}},
+	}
+
+	tests := []struct {
+		profile  string
+		expected int
+	}{
+		{ScoringClassic, 20 + 13 + 11 + 4},
+		{ScoringFacesTen, 20 + 10 + 10 + 4},
+		{ScoringTens25, 25 + 13 + 11 + 4},
+		{ScoringCleanFinish, 20 + 13 + 11 + 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			profile, ok := LookupScoringProfile(tt.profile)
+			if !ok {
+				t.Fatalf("Profile %s not found", tt.profile)
+			}
+			if score := CalculatePlayerScore(player, profile); score != tt.expected {
+				t.Errorf("Expected %d points, got %d", tt.expected, score)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package engine
+
+// ScoringProfile holds the penalty points for cards left at the end of a round
+type ScoringProfile struct {
+	Name       string         `json:"name"`
+	CardPoints map[string]int `json:"cardPoints"` // Points per card value; unknown values score 0
+	// CleanFinishBonus is taken off the winner's round score when every face-down card they flipped could be played
+	CleanFinishBonus int `json:"cleanFinishBonus"`
+}
+
+// Scoring profile names
+const (
+	ScoringClassic     = "classic"
+	ScoringFacesTen    = "facesTen"
+	ScoringTens25      = "tens25"
+	ScoringCleanFinish = "cleanFinish"
+)
+
+// DefaultScoringProfile returns the classic scoring: aces 1, pips at face value, J/Q/K 11-13 and tens 20
+func DefaultScoringProfile() ScoringProfile {
+	return ScoringProfile{
+		Name: ScoringClassic,
+		CardPoints: map[string]int{
+			"A": 1, "2": 2, "3": 3, "4": 4, "5": 5, "6": 6, "7": 7, "8": 8, "9": 9,
+			"10": 20, "J": 11, "Q": 12, "K": 13,
+		},
+	}
+}
+
+// LookupScoringProfile returns a named scoring profile
+func LookupScoringProfile(name string) (ScoringProfile, bool) {
+	profile := DefaultScoringProfile()
+	switch name {
+	case ScoringClassic:
+	case ScoringFacesTen:
+		profile.Name = ScoringFacesTen
+		profile.CardPoints["J"] = 10
+		profile.CardPoints["Q"] = 10
+		profile.CardPoints["K"] = 10
+	case ScoringTens25:
+		profile.Name = ScoringTens25
+		profile.CardPoints["10"] = 25
+	case ScoringCleanFinish:
+		profile.Name = ScoringCleanFinish
+		profile.CleanFinishBonus = 10
+	default:
+		return ScoringProfile{}, false
+	}
+	return profile, true
+}
+
+// ScoringProfileNames lists the selectable profiles
+func ScoringProfileNames() []string {
+	return []string{ScoringClassic, ScoringFacesTen, ScoringTens25, ScoringCleanFinish}
+}
+
+// PointValue returns the penalty points for a card value
+func (s ScoringProfile) PointValue(value string) int {
+	return s.CardPoints[value]
+}
@@ -0,0 +1,87 @@
+package engine
+
+// seatSnapshot holds one player's round state
+type seatSnapshot struct {
+	Hand           []*Card
+	TableCardsUp   []*Card
+	TableCardsDown []*Card
+	FlipMisses     int
+}
+
+// GameSnapshot is a copy of the round state taken before an action, used to undo it
+// Cards are never modified once dealt, so only the slices holding them are copied
+type GameSnapshot struct {
+	seats              map[string]seatSnapshot
+	order              []string
+	discardPile        []*Card
+	centerPile         []*Card
+	afterPickup        bool
+	lastClearMessage   string
+	currentPlayerIndex int
+	round              int
+	progress           Progress
+}
+
+// Snapshot captures the round state so it can be restored later
+func (g *Game) Snapshot() *GameSnapshot {
+
+	s := &GameSnapshot{
+		seats:              make(map[string]seatSnapshot, len(g.Players)),
+		order:              make([]string, 0, len(g.Players)),
+		discardPile:        copyCards(g.DiscardPile),
+		centerPile:         copyCards(g.CenterPile),
+		afterPickup:        g.AfterPickup,
+		lastClearMessage:   g.LastClearMessage,
+		currentPlayerIndex: g.CurrentPlayerIndex,
+		round:              g.Round,
+		progress:           g.Progress.clone(),
+	}
+	for _, p := range g.Players {
+		s.order = append(s.order, p.ID)
+		s.seats[p.ID] = seatSnapshot{
+			Hand:           copyCards(p.Hand),
+			TableCardsUp:   copyCards(p.TableCardsUp),
+			TableCardsDown: copyCards(p.TableCardsDown),
+			FlipMisses:     p.FlipMisses,
+		}
+	}
+	return s
+}
+
+// Matches reports whether the snapshot was taken in this round with the same seats
+func (s *GameSnapshot) Matches(g *Game) bool {
+	if s.round != g.Round || len(s.order) != len(g.Players) {
+		return false
+	}
+	for i, p := range g.Players {
+		if s.order[i] != p.ID {
+			return false
+		}
+	}
+	return true
+}
+
+// Restore puts the round state back to the snapshot; the player objects themselves are kept
+func (g *Game) Restore(s *GameSnapshot) {
+
+	for _, p := range g.Players {
+		seat, ok := s.seats[p.ID]
+		if !ok {
+			continue
+		}
+		p.Hand = copyCards(seat.Hand)
+		p.TableCardsUp = copyCards(seat.TableCardsUp)
+		p.TableCardsDown = copyCards(seat.TableCardsDown)
+		p.FlipMisses = seat.FlipMisses
+	}
+	g.DiscardPile = copyCards(s.discardPile)
+	g.CenterPile = copyCards(s.centerPile)
+	g.AfterPickup = s.afterPickup
+	g.LastClearMessage = s.lastClearMessage
+	g.CurrentPlayerIndex = s.currentPlayerIndex
+	g.Progress = s.progress.clone()
+}
+
+func copyCards(cards []*Card) []*Card {
+	return append([]*Card{}, cards...)
+}
@@ -0,0 +1,31 @@
+package engine
+
+// Reasons a round ends, reported with its result
+const (
+	RoundEndWon       = "won"
+	RoundEndStalemate = "stalemate"
+	RoundEndRepeated  = "repeated"
+)
+
+// CheckStalemate reports whether the round has stopped making progress and why
+// A round is stuck when the cards held have not reached a new low within the game's action limit,
+// or when the same table state has come round too many times
+func CheckStalemate(game *Game) (bool, string) {
+	if game.Progress.MaxRepeats >= StalemateRepeats {
+		return true, RoundEndRepeated
+	}
+
+	limit := game.StalemateActions
+	if limit == 0 {
+		limit = DefaultStalemateActions
+	}
+	if game.ActionsWithoutProgress() >= limit {
+		return true, RoundEndStalemate
+	}
+	return false, ""
+}
+
+// EndRoundInStalemate scores a stuck round as it stands; nobody wins it
+func EndRoundInStalemate(game *Game, reason string) {
+	endRound(game, "", reason)
+}
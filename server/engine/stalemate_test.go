@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+)
+
+func newStalemateGame() *Game {
+	players := []*Player{
+		{ID: "p1", Name: "Player 1", Hand: []*Card{{ID: "h1", Value: "5"}, {ID: "h2", Value: "K"}}},
+		{ID: "p2", Name: "Player 2", Hand: []*Card{{ID: "h3", Value: "9"}}},
+	}
+	game := NewGame("game-1", "ABCD", players)
+	game.IsStarted = true
+	game.ResetProgress()
+	return game
+}
+
+func TestCheckStalemate(t *testing.T) {
+	t.Run("A new game tracks progress from its deal", func(t *testing.T) {
+		players := []*Player{{ID: "p1", Name: "Player 1"}, {ID: "p2", Name: "Player 2"}, {ID: "p3", Name: "Player 3"}}
+		cfg, err := DealConfigFor(VariantAuto, len(players))
+		if err != nil {
+			t.Fatalf("DealConfigFor returned error: %v", err)
+		}
+		game, err := StartGameWithConfig(players, cfg)
+		if err != nil {
+			t.Fatalf("StartGameWithConfig returned error: %v", err)
+		}
+		if game.Progress.MinCardsHeld != game.CardsHeld() {
+			t.Fatalf("MinCardsHeld = %d, expected the %d cards dealt", game.Progress.MinCardsHeld, game.CardsHeld())
+		}
+
+		// Holding fewer cards than were dealt is progress
+		players[0].Hand = players[0].Hand[1:]
+		game.RecordAction()
+		if game.Progress.LastProgressAt != 1 {
+			t.Errorf("LastProgressAt = %d, expected the first action to count as progress", game.Progress.LastProgressAt)
+		}
+	})
+
+	t.Run("Fresh round is not stuck", func(t *testing.T) {
+		game := newStalemateGame()
+		if stuck, _ := CheckStalemate(game); stuck {
+			t.Error("Expected a fresh round not to be stuck")
+		}
+	})
+
+	t.Run("Repeated table state ends the round", func(t *testing.T) {
+		game := newStalemateGame()
+		for i := 0; i < StalemateRepeats; i++ {
+			if stuck, _ := CheckStalemate(game); stuck {
+				t.Fatalf("Round stuck after only %d repeats", i)
+			}
+			if err := PickupPile(game, "p1"); err != nil {
+				t.Fatalf("PickupPile returned error: %v", err)
+			}
+		}
+		stuck, reason := CheckStalemate(game)
+		if !stuck || reason != RoundEndRepeated {
+			t.Errorf("CheckStalemate = %v, %q; expected true, %q", stuck, reason, RoundEndRepeated)
+		}
+	})
+
+	t.Run("No progress within the limit ends the round", func(t *testing.T) {
+		game := newStalemateGame()
+		game.StalemateActions = 5
+		for i := 0; i < 5; i++ {
+			// Every action leaves the players holding more cards than before
+			game.Players[0].Hand = append(game.Players[0].Hand, &Card{ID: fmt.Sprintf("x%d", i), Value: "4"})
+			game.RecordAction()
+		}
+		stuck, reason := CheckStalemate(game)
+		if !stuck || reason != RoundEndStalemate {
+			t.Errorf("CheckStalemate = %v, %q; expected true, %q", stuck, reason, RoundEndStalemate)
+		}
+	})
+
+	t.Run("Playing cards down counts as progress", func(t *testing.T) {
+		game := newStalemateGame()
+		game.StalemateActions = 1
+		if err := PlayCards(game, "p1", []string{"h1"}, false); err != nil {
+			t.Fatalf("PlayCards returned error: %v", err)
+		}
+		if stuck, _ := CheckStalemate(game); stuck {
+			t.Error("Expected a play that sheds cards to count as progress")
+		}
+		if game.Progress.Actions != 1 || game.Progress.MinCardsHeld != 2 {
+			t.Errorf("Progress = %+v, expected 1 action and 2 cards held", game.Progress)
+		}
+	})
+}
+
+func TestEndRoundInStalemate(t *testing.T) {
+	game := newStalemateGame()
+	EndRoundInStalemate(game, RoundEndStalemate)
+
+	result := game.LastResult()
+	if result == nil || result.WinnerID != "" || result.Reason != RoundEndStalemate {
+		t.Fatalf("Unexpected round result: %+v", result)
+	}
+	if game.Players[0].RoundScore != 18 || game.Players[1].RoundScore != 9 {
+		t.Errorf("Round scores = %d/%d, expected 18/9", game.Players[0].RoundScore, game.Players[1].RoundScore)
+	}
+	if !game.RoundOver() {
+		t.Error("Expected the round to be over")
+	}
+	if err := PlayCards(game, "p1", []string{"h1"}, false); err == nil {
+		t.Error("Expected plays to be rejected once the round is over")
+	}
+}
@@ -0,0 +1,135 @@
+// Package engine is the Clear the Deck rules engine
+//
+// Games advance by applying actions to states: Apply(state, action) returns the next state and
+// the events that describe what happened. States are immutable by convention; Apply never changes
+// the state it is given, so callers may keep earlier states for replays, undo or search.
+//
+// The package holds the whole rule set: the game types, dealing, play validation, scoring and
+// the end-of-round checks. The rule functions such as PlayCards and EndRound change a game in
+// place and are what Apply is built on; the server uses them for the game it owns, and search
+// uses them on its own copies. The package depends on nothing else in the module, so it builds
+// for WebAssembly as is.
+package engine
+
+import (
+	"math/rand"
+)
+
+// Seat is a player sitting down at a new game
+type Seat struct {
+	ID   string
+	Name string
+}
+
+// Config describes a new game; zero values use the standard game for the table size
+type Config struct {
+	Deal    DealConfig
+	Rules   RuleSet
+	Scoring ScoringProfile
+	Seed    int64 // Non-zero seeds give a repeatable deal
+}
+
+// State is one position in a game
+type State struct {
+	game *Game
+}
+
+// NewState deals a new game for the seats
+func NewState(seats []Seat, cfg Config) (State, error) {
+	players := make([]*Player, len(seats))
+	for i, seat := range seats {
+		players[i] = &Player{ID: seat.ID, Name: seat.Name}
+	}
+
+	deal, err := ResolveDealConfig(VariantAuto, cfg.Deal, len(seats))
+	if err != nil {
+		return State{}, err
+	}
+
+	game, err := StartGameWithRand(players, deal, seeded(cfg.Seed))
+	if err != nil {
+		return State{}, err
+	}
+	if cfg.Rules.MinSetSize != 0 {
+		game.Rules = cfg.Rules
+	}
+	if cfg.Scoring.CardPoints != nil {
+		game.Scoring = cfg.Scoring
+	}
+	return State{game: game}, nil
+}
+
+// FromGame captures a copy of a server game as a state
+func FromGame(game *Game) State {
+	return State{game: game.Clone()}
+}
+
+// Game returns a copy of the full game behind the state
+func (s State) Game() *Game {
+	if s.game == nil {
+		return nil
+	}
+	return s.game.Clone()
+}
+
+// CopyInto writes the state into an existing game, keeping that game's player objects
+func (s State) CopyInto(game *Game) {
+	game.Adopt(s.game)
+}
+
+// Valid reports whether the state holds a game
+func (s State) Valid() bool {
+	return s.game != nil
+}
+
+// CurrentPlayerID returns the ID of the player whose turn it is
+func (s State) CurrentPlayerID() string {
+	if s.game == nil {
+		return ""
+	}
+	if p := s.game.GetCurrentPlayer(); p != nil {
+		return p.ID
+	}
+	return ""
+}
+
+// Round returns the round number
+func (s State) Round() int {
+	if s.game == nil {
+		return 0
+	}
+	return s.game.Round
+}
+
+// RoundOver reports whether the current round has been scored
+func (s State) RoundOver() bool {
+	return s.game != nil && s.game.RoundOver()
+}
+
+// Finished reports whether the game is over
+func (s State) Finished() bool {
+	return s.game != nil && s.game.IsFinished
+}
+
+// Rules returns the rules the game is played with
+func (s State) Rules() RuleSet {
+	if s.game == nil {
+		return DefaultRuleSet()
+	}
+	return s.game.ActiveRules()
+}
+
+func (s State) player(id string) (*Player, error) {
+	if i := s.game.PlayerIndex(id); i >= 0 {
+		return s.game.Players[i], nil
+	}
+	return nil, ErrPlayerNotFound
+}
+
+// seeded returns a source of randomness for a seed, or nil for the shared one
+func seeded(seed int64) *rand.Rand {
+	if seed == 0 {
+		return nil
+	}
+	return rand.New(rand.NewSource(seed))
+}
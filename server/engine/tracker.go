@@ -0,0 +1,53 @@
+package engine
+
+import ()
+
+// TrackCards counts the cards of each value the whole table has seen this round
+// Cards picked up from the pile count as unseen again, as do cards a forfeiting player held.
+func TrackCards(game *Game) CardTracker {
+	t := CardTracker{
+		Decks:   game.Deal.Decks,
+		OnPile:  make(map[string]int, len(CardValues)),
+		Cleared: make(map[string]int, len(CardValues)),
+		FaceUp:  make(map[string]int, len(CardValues)),
+		Unseen:  make(map[string]int, len(CardValues)),
+	}
+	// Every value is listed, even with no cards, so clients can show the whole deck
+	for _, value := range CardValues {
+		t.OnPile[value], t.Cleared[value], t.FaceUp[value] = 0, 0, 0
+	}
+	for _, card := range game.CenterPile {
+		t.OnPile[card.Value]++
+	}
+	undealt := game.Undealt
+	if undealt > len(game.DiscardPile) {
+		undealt = len(game.DiscardPile)
+	}
+	for _, card := range game.DiscardPile[undealt:] {
+		t.Cleared[card.Value]++
+	}
+	for _, p := range game.Players {
+		for _, card := range p.TableCardsUp {
+			t.FaceUp[card.Value]++
+		}
+	}
+
+	perValue := 4 * game.Deal.Decks
+	for _, value := range CardValues {
+		seen := t.OnPile[value] + t.Cleared[value] + t.FaceUp[value]
+		t.Unseen[value] = max(perValue-seen, 0)
+	}
+	t.TensRemaining = max(perValue-t.OnPile["10"]-t.Cleared["10"], 0)
+	return t
+}
+
+// CardTracker is what everyone at the table can know about where each value's cards are this round
+// Every map has an entry for each card value.
+type CardTracker struct {
+	Decks         int            `json:"decks"`
+	OnPile        map[string]int `json:"onPile"`        // Played and still on the center pile
+	Cleared       map[string]int `json:"cleared"`       // Played and cleared out of play
+	FaceUp        map[string]int `json:"faceUp"`        // Face up on the tables
+	Unseen        map[string]int `json:"unseen"`        // In hands, face down or never dealt
+	TensRemaining int            `json:"tensRemaining"` // Tens neither cleared nor on the pile
+}
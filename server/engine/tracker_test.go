@@ -0,0 +1,90 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/models"
+	"github.com/thben/clearthedeck/internal/services"
+)
+
+func TestTrackCardsAfterDeal(t *testing.T) {
+	players := []*engine.Player{{ID: "a", Name: "A"}, {ID: "b", Name: "B"}, {ID: "c", Name: "C"}}
+	game, err := engine.StartGameWithConfig(players, engine.DealConfig{Decks: 1, FaceDown: 3, FaceUp: 3, Hand: 3})
+	if err != nil {
+		t.Fatalf("StartGameWithConfig returned error: %v", err)
+	}
+
+	tracker := engine.TrackCards(game)
+
+	faceUp, unseen := 0, 0
+	for value, n := range tracker.FaceUp {
+		faceUp += n
+		unseen += tracker.Unseen[value]
+		if tracker.Cleared[value] != 0 || tracker.OnPile[value] != 0 {
+			t.Errorf("%s is counted as played before anyone has played", value)
+		}
+	}
+	if faceUp != 9 {
+		t.Errorf("Counted %d face-up cards, expected 9", faceUp)
+	}
+	// The undealt cards went to the discard pile but nobody has seen them
+	if unseen != 52-9 {
+		t.Errorf("Counted %d unseen cards, expected %d", unseen, 52-9)
+	}
+	if tracker.TensRemaining != 4 {
+		t.Errorf("Tens remaining is %d, expected 4", tracker.TensRemaining)
+	}
+}
+
+func TestTrackCardsAfterClear(t *testing.T) {
+	game := setUp(t, `
+current: A
+pile: [5H, 7C]
+players:
+  - {name: A, hand: [10H, 3C], faceUp: [7D]}
+  - {name: B, hand: [2H]}
+`)
+	if err := engine.PlayCards(game, "A", []string{"A-hand-0"}, false); err != nil {
+		t.Fatalf("PlayCards returned error: %v", err)
+	}
+
+	tracker := engine.TrackCards(game)
+
+	if tracker.Cleared["10"] != 1 || tracker.Cleared["5"] != 1 || tracker.Cleared["7"] != 1 {
+		t.Errorf("Cleared counts are %v, expected the ten, five and seven", tracker.Cleared)
+	}
+	if tracker.FaceUp["7"] != 1 {
+		t.Errorf("Face-up sevens are %d, expected 1", tracker.FaceUp["7"])
+	}
+	perValue := 4 * tracker.Decks
+	if tracker.Unseen["7"] != perValue-2 {
+		t.Errorf("Unseen sevens are %d, expected %d", tracker.Unseen["7"], perValue-2)
+	}
+	if tracker.TensRemaining != perValue-1 {
+		t.Errorf("Tens remaining is %d, expected %d", tracker.TensRemaining, perValue-1)
+	}
+}
+
+func TestTrackCardsAfterForfeit(t *testing.T) {
+	game := setUp(t, `
+current: A
+players:
+  - {name: A, hand: [10H]}
+  - {name: B, hand: [9H]}
+  - {name: C, hand: [KH, KD], faceUp: [QS], faceDown: [JS]}
+`)
+	if err := services.RemovePlayer(game, "C", models.DepartureForfeit); err != nil {
+		t.Fatalf("RemovePlayer returned error: %v", err)
+	}
+
+	tracker := engine.TrackCards(game)
+
+	// Only the face-up queen was ever shown
+	if tracker.Cleared["Q"] != 1 {
+		t.Errorf("Cleared queens are %d, expected 1", tracker.Cleared["Q"])
+	}
+	if tracker.Cleared["K"] != 0 || tracker.Cleared["J"] != 0 {
+		t.Errorf("Hidden cards were counted as cleared: %v", tracker.Cleared)
+	}
+}
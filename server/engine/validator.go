@@ -1,11 +1,9 @@
-package utils
+package engine
 
-import (
-	"github.com/thben/clearthedeck/internal/models"
-)
+import ()
 
 // AllSameValue checks if all cards in the slice have the same value
-func AllSameValue(cards []*models.Card) bool {
+func AllSameValue(cards []*Card) bool {
 	if len(cards) == 0 {
 		return true
 	}
@@ -21,7 +19,7 @@ func AllSameValue(cards []*models.Card) bool {
 
 // GetCardValue returns the numeric value of a card
 // A=1, 2-9=face value, 10=10, J=11, Q=12, K=13
-func GetCardValue(card *models.Card) int {
+func GetCardValue(card *Card) int {
 	switch card.Value {
 	case "A":
 		return 1
@@ -54,15 +52,26 @@ func GetCardValue(card *models.Card) int {
 	}
 }
 
-// IsValidPlay checks if the cards can be played on the center pile
+// IsRank reports whether the value is a card rank (A, 2-10, J, Q, K)
+func IsRank(value string) bool {
+	return GetCardValue(&Card{Value: value}) > 0
+}
+
+// IsValidPlay checks if the cards can be played on the center pile under the standard rules
+// Returns (isValid, reason)
+func IsValidPlay(cardsToPlay []*Card, centerPile []*Card, afterPickup bool) (bool, string) {
+	return ValidatePlay(cardsToPlay, centerPile, afterPickup, DefaultRuleSet())
+}
+
+// ValidatePlay checks if the cards can be played on the center pile under the rule set
 // Returns (isValid, reason)
-func IsValidPlay(cardsToPlay []*models.Card, centerPile []*models.Card, afterPickup bool) (bool, string) {
+func ValidatePlay(cardsToPlay []*Card, centerPile []*Card, afterPickup bool, rules RuleSet) (bool, string) {
 	if len(cardsToPlay) == 0 {
 		return false, "no cards played"
 	}
 
 	// After pickup, any card can be played
-	if afterPickup {
+	if afterPickup && rules.PickupAllowsAnyPlay {
 		return true, ""
 	}
 
@@ -71,17 +80,39 @@ func IsValidPlay(cardsToPlay []*models.Card, centerPile []*models.Card, afterPic
 		return true, ""
 	}
 
-	// If playing tens (wild), always valid
-	if len(cardsToPlay) > 0 && cardsToPlay[0].Value == "10" {
+	// If playing wilds, always valid
+	if rules.IsWild(cardsToPlay[0].Value) {
+		return true, ""
+	}
+
+	// A wild that reset the pile lets any card follow
+	top := centerPile[len(centerPile)-1]
+	if rules.IsWild(top.Value) {
 		return true, ""
 	}
 
-	// Over-value plays are allowed and stay on the pile
+	// Over-value plays stay on the pile unless the rules forbid them
+	if GetCardValue(cardsToPlay[0]) > GetCardValue(top) && !rules.AllowOverValue {
+		return false, "must play equal or lower value"
+	}
+
 	return true, ""
 }
 
+// IsOverValue reports whether the cards beat the top of the pile, which sends the rest of the pile to the player
+// Wilds never count as over-value, and neither does anything played on a wild that reset the pile
+func IsOverValue(cardsToPlay []*Card, prevTop *Card, rules RuleSet) bool {
+	if len(cardsToPlay) == 0 || prevTop == nil {
+		return false
+	}
+	if rules.IsWild(cardsToPlay[0].Value) || rules.IsWild(prevTop.Value) {
+		return false
+	}
+	return GetCardValue(cardsToPlay[0]) > GetCardValue(prevTop)
+}
+
 // DetectSet checks if the last 4 or more cards in the center pile are all the same value
-func DetectSet(centerPile []*models.Card) bool {
+func DetectSet(centerPile []*Card) bool {
 	// Need at least 4 cards to form a set
 	if len(centerPile) < 4 {
 		return false
@@ -103,7 +134,7 @@ func DetectSet(centerPile []*models.Card) bool {
 }
 
 // CountTrailingSet returns the size and value of the trailing set of identical cards
-func CountTrailingSet(centerPile []*models.Card) (int, string) {
+func CountTrailingSet(centerPile []*Card) (int, string) {
 	if len(centerPile) == 0 {
 		return 0, ""
 	}
@@ -1,20 +1,18 @@
-package utils
+package engine
 
 import (
 	"testing"
-
-	"github.com/thben/clearthedeck/internal/models"
 )
 
 func TestAllSameValue(t *testing.T) {
 	tests := []struct {
 		name     string
-		cards    []*models.Card
+		cards    []*Card
 		expected bool
 	}{
 		{
 			name: "All same value returns true",
-			cards: []*models.Card{
+			cards: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "5"},
 				{ID: "2", Suit: "Diamonds", Value: "5"},
 				{ID: "3", Suit: "Clubs", Value: "5"},
@@ -23,7 +21,7 @@ func TestAllSameValue(t *testing.T) {
 		},
 		{
 			name: "Mixed values returns false",
-			cards: []*models.Card{
+			cards: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "5"},
 				{ID: "2", Suit: "Diamonds", Value: "6"},
 				{ID: "3", Suit: "Clubs", Value: "5"},
@@ -32,19 +30,19 @@ func TestAllSameValue(t *testing.T) {
 		},
 		{
 			name:     "Empty slice returns true",
-			cards:    []*models.Card{},
+			cards:    []*Card{},
 			expected: true,
 		},
 		{
 			name: "Single card returns true",
-			cards: []*models.Card{
+			cards: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "K"},
 			},
 			expected: true,
 		},
 		{
 			name: "All tens returns true",
-			cards: []*models.Card{
+			cards: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "10"},
 				{ID: "2", Suit: "Diamonds", Value: "10"},
 			},
@@ -65,28 +63,28 @@ func TestAllSameValue(t *testing.T) {
 func TestIsValidPlay(t *testing.T) {
 	tests := []struct {
 		name           string
-		cardsToPlay    []*models.Card
-		centerPile     []*models.Card
+		cardsToPlay    []*Card
+		centerPile     []*Card
 		afterPickup    bool
 		expectedValid  bool
 		expectedReason string
 	}{
 		{
 			name: "Empty center pile is always valid",
-			cardsToPlay: []*models.Card{
+			cardsToPlay: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "K"},
 			},
-			centerPile:     []*models.Card{},
+			centerPile:     []*Card{},
 			afterPickup:    false,
 			expectedValid:  true,
 			expectedReason: "",
 		},
 		{
 			name: "Equal value is valid",
-			cardsToPlay: []*models.Card{
+			cardsToPlay: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "5"},
 			},
-			centerPile: []*models.Card{
+			centerPile: []*Card{
 				{ID: "2", Suit: "Diamonds", Value: "5"},
 			},
 			afterPickup:    false,
@@ -95,10 +93,10 @@ func TestIsValidPlay(t *testing.T) {
 		},
 		{
 			name: "Lesser value is valid",
-			cardsToPlay: []*models.Card{
+			cardsToPlay: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "4"},
 			},
-			centerPile: []*models.Card{
+			centerPile: []*Card{
 				{ID: "2", Suit: "Diamonds", Value: "7"},
 			},
 			afterPickup:    false,
@@ -107,10 +105,10 @@ func TestIsValidPlay(t *testing.T) {
 		},
 		{
 			name: "Greater value is valid and should stay on stack",
-			cardsToPlay: []*models.Card{
+			cardsToPlay: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "9"},
 			},
-			centerPile: []*models.Card{
+			centerPile: []*Card{
 				{ID: "2", Suit: "Diamonds", Value: "5"},
 			},
 			afterPickup:    false,
@@ -119,10 +117,10 @@ func TestIsValidPlay(t *testing.T) {
 		},
 		{
 			name: "Wild tens are always valid",
-			cardsToPlay: []*models.Card{
+			cardsToPlay: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "10"},
 			},
-			centerPile: []*models.Card{
+			centerPile: []*Card{
 				{ID: "2", Suit: "Diamonds", Value: "3"},
 			},
 			afterPickup:    false,
@@ -131,10 +129,10 @@ func TestIsValidPlay(t *testing.T) {
 		},
 		{
 			name: "Wild tens on high card",
-			cardsToPlay: []*models.Card{
+			cardsToPlay: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "10"},
 			},
-			centerPile: []*models.Card{
+			centerPile: []*Card{
 				{ID: "2", Suit: "Diamonds", Value: "K"},
 			},
 			afterPickup:    false,
@@ -143,10 +141,10 @@ func TestIsValidPlay(t *testing.T) {
 		},
 		{
 			name: "After pickup any value is valid",
-			cardsToPlay: []*models.Card{
+			cardsToPlay: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "K"},
 			},
-			centerPile: []*models.Card{
+			centerPile: []*Card{
 				{ID: "2", Suit: "Diamonds", Value: "2"},
 			},
 			afterPickup:    true,
@@ -155,10 +153,10 @@ func TestIsValidPlay(t *testing.T) {
 		},
 		{
 			name: "Ace is value 1 - valid on 2",
-			cardsToPlay: []*models.Card{
+			cardsToPlay: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "A"},
 			},
-			centerPile: []*models.Card{
+			centerPile: []*Card{
 				{ID: "2", Suit: "Diamonds", Value: "2"},
 			},
 			afterPickup:    false,
@@ -167,10 +165,10 @@ func TestIsValidPlay(t *testing.T) {
 		},
 		{
 			name: "Jack (11) valid on King (13)",
-			cardsToPlay: []*models.Card{
+			cardsToPlay: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "J"},
 			},
-			centerPile: []*models.Card{
+			centerPile: []*Card{
 				{ID: "2", Suit: "Diamonds", Value: "K"},
 			},
 			afterPickup:    false,
@@ -179,10 +177,10 @@ func TestIsValidPlay(t *testing.T) {
 		},
 		{
 			name: "King (13) over-value on Jack (11) stays valid",
-			cardsToPlay: []*models.Card{
+			cardsToPlay: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "K"},
 			},
-			centerPile: []*models.Card{
+			centerPile: []*Card{
 				{ID: "2", Suit: "Diamonds", Value: "J"},
 			},
 			afterPickup:    false,
@@ -207,12 +205,12 @@ func TestIsValidPlay(t *testing.T) {
 func TestDetectSet(t *testing.T) {
 	tests := []struct {
 		name       string
-		centerPile []*models.Card
+		centerPile []*Card
 		expected   bool
 	}{
 		{
 			name: "4 same value cards at end is a set",
-			centerPile: []*models.Card{
+			centerPile: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "5"},
 				{ID: "2", Suit: "Diamonds", Value: "5"},
 				{ID: "3", Suit: "Clubs", Value: "5"},
@@ -222,7 +220,7 @@ func TestDetectSet(t *testing.T) {
 		},
 		{
 			name: "5 same value cards at end is a set",
-			centerPile: []*models.Card{
+			centerPile: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "K"},
 				{ID: "2", Suit: "Diamonds", Value: "K"},
 				{ID: "3", Suit: "Clubs", Value: "K"},
@@ -233,7 +231,7 @@ func TestDetectSet(t *testing.T) {
 		},
 		{
 			name: "Less than 4 same value is not a set",
-			centerPile: []*models.Card{
+			centerPile: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "7"},
 				{ID: "2", Suit: "Diamonds", Value: "7"},
 				{ID: "3", Suit: "Clubs", Value: "7"},
@@ -242,7 +240,7 @@ func TestDetectSet(t *testing.T) {
 		},
 		{
 			name: "4 cards with different values is not a set",
-			centerPile: []*models.Card{
+			centerPile: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "5"},
 				{ID: "2", Suit: "Diamonds", Value: "6"},
 				{ID: "3", Suit: "Clubs", Value: "7"},
@@ -252,7 +250,7 @@ func TestDetectSet(t *testing.T) {
 		},
 		{
 			name: "4 same values with different before is a set",
-			centerPile: []*models.Card{
+			centerPile: []*Card{
 				{ID: "0", Suit: "Hearts", Value: "3"},
 				{ID: "1", Suit: "Hearts", Value: "5"},
 				{ID: "2", Suit: "Diamonds", Value: "5"},
@@ -263,7 +261,7 @@ func TestDetectSet(t *testing.T) {
 		},
 		{
 			name: "4 cards but not consecutive same values is not a set",
-			centerPile: []*models.Card{
+			centerPile: []*Card{
 				{ID: "1", Suit: "Hearts", Value: "5"},
 				{ID: "2", Suit: "Diamonds", Value: "6"},
 				{ID: "3", Suit: "Clubs", Value: "5"},
@@ -273,7 +271,7 @@ func TestDetectSet(t *testing.T) {
 		},
 		{
 			name:       "Empty center pile is not a set",
-			centerPile: []*models.Card{},
+			centerPile: []*Card{},
 			expected:   false,
 		},
 	}
@@ -287,3 +285,89 @@ func TestDetectSet(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePlayWithRules(t *testing.T) {
+	strict, _ := LookupRuleSet(RulesStrict)
+	resetTens, _ := LookupRuleSet(RulesResetTens)
+	jacksWild := DefaultRuleSet()
+	jacksWild.WildRanks = []string{"J"}
+
+	tests := []struct {
+		name          string
+		rules         RuleSet
+		cardsToPlay   []*Card
+		centerPile    []*Card
+		afterPickup   bool
+		expectedValid bool
+	}{
+		{
+			name:          "Strict rules reject over-value plays",
+			rules:         strict,
+			cardsToPlay:   []*Card{{ID: "1", Value: "K"}},
+			centerPile:    []*Card{{ID: "2", Value: "J"}},
+			expectedValid: false,
+		},
+		{
+			name:          "Strict rules allow equal value",
+			rules:         strict,
+			cardsToPlay:   []*Card{{ID: "1", Value: "J"}},
+			centerPile:    []*Card{{ID: "2", Value: "J"}},
+			expectedValid: true,
+		},
+		{
+			name:          "Strict rules still allow anything after a pickup",
+			rules:         strict,
+			cardsToPlay:   []*Card{{ID: "1", Value: "K"}},
+			centerPile:    []*Card{{ID: "2", Value: "3"}},
+			afterPickup:   true,
+			expectedValid: true,
+		},
+		{
+			name:          "Anything follows a wild that reset the pile",
+			rules:         func() RuleSet { r := resetTens; r.AllowOverValue = false; return r }(),
+			cardsToPlay:   []*Card{{ID: "1", Value: "K"}},
+			centerPile:    []*Card{{ID: "2", Value: "3"}, {ID: "3", Value: "10"}},
+			expectedValid: true,
+		},
+		{
+			name:          "Custom wild rank plays on anything",
+			rules:         func() RuleSet { r := jacksWild; r.AllowOverValue = false; return r }(),
+			cardsToPlay:   []*Card{{ID: "1", Value: "J"}},
+			centerPile:    []*Card{{ID: "2", Value: "2"}},
+			expectedValid: true,
+		},
+		{
+			name:          "Tens are not wild when another rank is",
+			rules:         func() RuleSet { r := jacksWild; r.AllowOverValue = false; return r }(),
+			cardsToPlay:   []*Card{{ID: "1", Value: "10"}},
+			centerPile:    []*Card{{ID: "2", Value: "2"}},
+			expectedValid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, _ := ValidatePlay(tt.cardsToPlay, tt.centerPile, tt.afterPickup, tt.rules)
+			if valid != tt.expectedValid {
+				t.Errorf("ValidatePlay() valid = %v, expected %v", valid, tt.expectedValid)
+			}
+		})
+	}
+}
+
+func TestIsOverValue(t *testing.T) {
+	rules := DefaultRuleSet()
+
+	if !IsOverValue([]*Card{{Value: "K"}}, &Card{Value: "5"}, rules) {
+		t.Error("King on a five should be over-value")
+	}
+	if IsOverValue([]*Card{{Value: "5"}}, &Card{Value: "5"}, rules) {
+		t.Error("Equal value should not be over-value")
+	}
+	if IsOverValue([]*Card{{Value: "10"}}, &Card{Value: "5"}, rules) {
+		t.Error("Wilds should never be over-value")
+	}
+	if IsOverValue([]*Card{{Value: "K"}}, nil, rules) {
+		t.Error("Nothing is over-value on an empty pile")
+	}
+}
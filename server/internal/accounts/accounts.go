@@ -0,0 +1,225 @@
+// Package accounts keeps optional player accounts: a username and password, a display name and a
+// stable player ID that carries over from one table to the next
+//
+// Accounts are saved in a storage.Store, so they last as long as the store does. Players who do
+// not sign in still play as guests with a new ID for every table.
+package accounts
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/thben/clearthedeck/internal/storage"
+)
+
+// Storage kinds: accounts by ID, and the ID for each lower-cased username
+const (
+	accountsKind  = "accounts"
+	usernamesKind = "usernames"
+)
+
+// Limits on what players choose
+const (
+	MinUsernameLength    = 3
+	MaxUsernameLength    = 20
+	MinPasswordLength    = 8
+	MaxPasswordLength    = 128
+	MaxDisplayNameLength = 20
+)
+
+// DefaultTokenTTL is how long a session token is good for
+const DefaultTokenTTL = 30 * 24 * time.Hour
+
+var (
+	ErrInvalidUsername    = fmt.Errorf("username must be %d-%d letters, digits, - or _", MinUsernameLength, MaxUsernameLength)
+	ErrInvalidPassword    = fmt.Errorf("password must be %d-%d characters", MinPasswordLength, MaxPasswordLength)
+	ErrInvalidDisplayName = fmt.Errorf("display name must be 1-%d characters", MaxDisplayNameLength)
+	ErrUsernameTaken      = errors.New("username is already taken")
+	ErrWrongPassword      = errors.New("wrong username or password")
+	ErrInvalidToken       = errors.New("session token is invalid or has expired")
+	ErrAccountNotFound    = errors.New("account not found")
+)
+
+// Account is a registered player
+type Account struct {
+	ID           string    `json:"id"` // Player ID at every table the account sits at
+	Username     string    `json:"username"`
+	DisplayName  string    `json:"displayName"`
+	PasswordHash string    `json:"passwordHash"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Public returns the account without its password hash, as it is sent to clients
+func (a *Account) Public() map[string]interface{} {
+	return map[string]interface{}{
+		"id":          a.ID,
+		"username":    a.Username,
+		"displayName": a.DisplayName,
+	}
+}
+
+// Service registers players, checks their passwords and issues their session tokens
+type Service struct {
+	store    storage.Store
+	secret   []byte
+	tokenTTL time.Duration
+	now      func() time.Time
+	// Serializes registrations so two players cannot claim the same username
+	mu sync.Mutex
+}
+
+// NewService creates an account service saving to the store
+// Tokens are signed with the secret; an empty secret picks a random one, so tokens stop working
+// when the process restarts. A zero token lifetime uses DefaultTokenTTL.
+func NewService(store storage.Store, secret []byte, tokenTTL time.Duration) *Service {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(fmt.Sprintf("accounts: no randomness for a token secret: %v", err))
+		}
+	}
+	if tokenTTL <= 0 {
+		tokenTTL = DefaultTokenTTL
+	}
+	return &Service{store: store, secret: secret, tokenTTL: tokenTTL, now: time.Now}
+}
+
+// Register creates an account; an empty display name uses the username
+func (s *Service) Register(username, password, displayName string) (*Account, error) {
+	if !validUsername(username) {
+		return nil, ErrInvalidUsername
+	}
+	if n := utf8.RuneCountInString(password); n < MinPasswordLength || n > MaxPasswordLength {
+		return nil, ErrInvalidPassword
+	}
+	displayName = strings.TrimSpace(displayName)
+	if displayName == "" {
+		displayName = username
+	}
+	if utf8.RuneCountInString(displayName) > MaxDisplayNameLength {
+		return nil, ErrInvalidDisplayName
+	}
+
+	// Hashing is slow on purpose, so it is done before taking the lock
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := strings.ToLower(username)
+	if _, err := s.store.Load(usernamesKind, key); err == nil {
+		return nil, ErrUsernameTaken
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+
+	account := &Account{
+		ID:           uuid.New().String(),
+		Username:     username,
+		DisplayName:  displayName,
+		PasswordHash: hash,
+		CreatedAt:    s.now().UTC(),
+	}
+	if err := s.save(account); err != nil {
+		return nil, err
+	}
+	// The username is claimed last, so a failed save leaves it free
+	id, err := json.Marshal(account.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Save(usernamesKind, key, id); err != nil {
+		s.store.Delete(accountsKind, account.ID)
+		return nil, err
+	}
+	return account, nil
+}
+
+// Login returns the account if the password is right; usernames are not case-sensitive
+func (s *Service) Login(username, password string) (*Account, error) {
+	if !validUsername(username) {
+		return nil, ErrWrongPassword
+	}
+	data, err := s.store.Load(usernamesKind, strings.ToLower(username))
+	if errors.Is(err, storage.ErrNotFound) {
+		// Hash anyway so unknown usernames take as long as wrong passwords
+		checkPassword(password, dummyHash())
+		return nil, ErrWrongPassword
+	}
+	if err != nil {
+		return nil, err
+	}
+	var id string
+	if err := json.Unmarshal(data, &id); err != nil {
+		return nil, fmt.Errorf("accounts: username %s: %w", username, err)
+	}
+	account, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !checkPassword(password, account.PasswordHash) {
+		return nil, ErrWrongPassword
+	}
+	return account, nil
+}
+
+// Get loads an account by ID
+func (s *Service) Get(id string) (*Account, error) {
+	data, err := s.store.Load(accountsKind, id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var account Account
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("accounts: account %s: %w", id, err)
+	}
+	return &account, nil
+}
+
+func (s *Service) save(account *Account) error {
+	data, err := json.Marshal(account)
+	if err != nil {
+		return err
+	}
+	return s.store.Save(accountsKind, account.ID, data)
+}
+
+// validUsername allows ASCII letters, digits, - and _, which also keeps usernames safe as storage keys
+func validUsername(username string) bool {
+	if len(username) < MinUsernameLength || len(username) > MaxUsernameLength {
+		return false
+	}
+	for _, r := range username {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	dummy     string
+	dummyOnce sync.Once
+)
+
+// dummyHash is checked against when a username does not exist; it is made on first use
+func dummyHash() string {
+	dummyOnce.Do(func() {
+		dummy, _ = hashPassword("no such account")
+	})
+	return dummy
+}
@@ -0,0 +1,129 @@
+package accounts
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thben/clearthedeck/internal/storage"
+)
+
+func init() {
+	// Full-strength hashing would make every registration here take a tenth of a second
+	hashIterations = 1000
+}
+
+func TestPBKDF2(t *testing.T) {
+	// RFC 7914 section 11, in the stored format: hashes keep verifying whatever derives the key
+	known := "pbkdf2-sha256$1$c2FsdA$VawEblbjCJ/sFpHCJUS2BflBhSFt3gRl5oudV8INrLxJypzM8Xm2RZkWZLOdd+8xfHG4RbHjC9UJESBB06GXgw"
+	assert.True(t, checkPassword("passwd", known))
+	assert.False(t, checkPassword("password", known))
+}
+
+func TestPasswordHash(t *testing.T) {
+	hash, err := hashPassword("correct horse")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "pbkdf2-sha256$1000$"))
+	assert.NotContains(t, hash, "correct horse")
+	assert.True(t, checkPassword("correct horse", hash))
+	assert.False(t, checkPassword("correct horsE", hash))
+	assert.False(t, checkPassword("correct horse", "md5$abc"))
+
+	again, err := hashPassword("correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, again, "every hash gets its own salt")
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	store := storage.NewMemoryStore()
+	s := NewService(store, []byte("secret"), 0)
+
+	account, err := s.Register("Alice", "wonderland", "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, account.ID)
+	assert.Equal(t, "Alice", account.DisplayName)
+
+	t.Run("usernames are taken whatever their case", func(t *testing.T) {
+		_, err := s.Register("alice", "looking-glass", "Other Alice")
+		assert.ErrorIs(t, err, ErrUsernameTaken)
+	})
+
+	t.Run("login checks the password", func(t *testing.T) {
+		got, err := s.Login("ALICE", "wonderland")
+		require.NoError(t, err)
+		assert.Equal(t, account.ID, got.ID)
+
+		_, err = s.Login("Alice", "wonderlanD")
+		assert.ErrorIs(t, err, ErrWrongPassword)
+		_, err = s.Login("Nobody", "wonderland")
+		assert.ErrorIs(t, err, ErrWrongPassword)
+	})
+
+	t.Run("accounts survive a new service on the same store", func(t *testing.T) {
+		got, err := NewService(store, []byte("secret"), 0).Login("alice", "wonderland")
+		require.NoError(t, err)
+		assert.Equal(t, account.ID, got.ID)
+	})
+
+	t.Run("bad details are refused", func(t *testing.T) {
+		for _, c := range []struct {
+			username, password, displayName string
+			err                             error
+		}{
+			{"al", "wonderland", "", ErrInvalidUsername},
+			{"../etc", "wonderland", "", ErrInvalidUsername},
+			{"Bob", "short", "", ErrInvalidPassword},
+			{"Bob", "wonderland", strings.Repeat("b", MaxDisplayNameLength+1), ErrInvalidDisplayName},
+		} {
+			_, err := s.Register(c.username, c.password, c.displayName)
+			assert.ErrorIs(t, err, c.err, c.username)
+		}
+	})
+
+	t.Run("accounts work with the file store", func(t *testing.T) {
+		files, err := storage.NewFileStore(t.TempDir())
+		require.NoError(t, err)
+		s := NewService(files, nil, 0)
+		_, err = s.Register("Carol", "password1", "Caz")
+		require.NoError(t, err)
+		got, err := s.Login("carol", "password1")
+		require.NoError(t, err)
+		assert.Equal(t, "Caz", got.DisplayName)
+	})
+}
+
+func TestTokens(t *testing.T) {
+	s := NewService(storage.NewMemoryStore(), []byte("secret"), time.Hour)
+	account, err := s.Register("Alice", "wonderland", "Al")
+	require.NoError(t, err)
+
+	token, err := s.IssueToken(account)
+	require.NoError(t, err)
+	got, err := s.Authenticate(token)
+	require.NoError(t, err)
+	assert.Equal(t, account.ID, got.ID)
+	assert.Equal(t, "Al", got.DisplayName)
+
+	t.Run("tampered tokens are refused", func(t *testing.T) {
+		payload, sig, _ := strings.Cut(token, ".")
+		_, err := s.Authenticate(payload + "x." + sig)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+		_, err = s.Authenticate("not a token")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("another secret does not accept the token", func(t *testing.T) {
+		other := NewService(s.store, []byte("other"), time.Hour)
+		_, err := other.Authenticate(token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("tokens expire", func(t *testing.T) {
+		s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		defer func() { s.now = time.Now }()
+		_, err := s.Authenticate(token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
@@ -0,0 +1,58 @@
+package accounts
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Password hashes are PBKDF2 with HMAC-SHA256, stored as "pbkdf2-sha256$iterations$salt$key"
+// The iteration count is kept with each hash so it can be raised without invalidating old passwords.
+const (
+	hashScheme = "pbkdf2-sha256"
+	saltLength = 16
+	keyLength  = 32
+)
+
+// hashIterations is the work factor for new hashes; tests lower it
+var hashIterations = 600000
+
+// hashPassword returns an encoded hash of the password with a new random salt
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("accounts: %w", err)
+	}
+	key := pbkdf2.Key([]byte(password), salt, hashIterations, keyLength, sha256.New)
+	enc := base64.RawStdEncoding
+	return fmt.Sprintf("%s$%d$%s$%s", hashScheme, hashIterations, enc.EncodeToString(salt), enc.EncodeToString(key)), nil
+}
+
+// checkPassword reports whether the password matches an encoded hash
+func checkPassword(password, encoded string) bool {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != hashScheme {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	enc := base64.RawStdEncoding
+	salt, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := enc.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	got := pbkdf2.Key([]byte(password), salt, iterations, len(want), sha256.New)
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
@@ -0,0 +1,64 @@
+package accounts
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// claims is what a session token vouches for
+type claims struct {
+	Subject string `json:"sub"` // Account ID
+	Expires int64  `json:"exp"` // Unix seconds
+}
+
+// IssueToken signs a session token for the account that is good until the token lifetime runs out
+// Tokens are "payload.signature", both base64url: the payload is JSON claims and the signature
+// is an HMAC-SHA256 of the encoded payload under the server's secret.
+func (s *Service) IssueToken(account *Account) (string, error) {
+	payload, err := json.Marshal(claims{Subject: account.ID, Expires: s.now().Add(s.tokenTTL).Unix()})
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded)), nil
+}
+
+// Authenticate checks a session token and returns the account it was issued to
+func (s *Service) Authenticate(token string) (*Account, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, s.sign(encoded)) {
+		return nil, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil || c.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+	if !s.now().Before(time.Unix(c.Expires, 0)) {
+		return nil, ErrInvalidToken
+	}
+
+	// The account is looked up again so deleted accounts lose their sessions and names stay current
+	account, err := s.Get(c.Subject)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return account, nil
+}
+
+func (s *Service) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
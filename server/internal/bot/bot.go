@@ -0,0 +1,150 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/thben/clearthedeck/engine"
+)
+
+// ActionType identifies what a bot wants to do on its turn
+type ActionType = engine.ActionType
+
+const (
+	ActionPlay   = engine.ActionPlay
+	ActionFlip   = engine.ActionFlip
+	ActionPickup = engine.ActionPickup
+)
+
+// Action is a move chosen by a strategy
+type Action = engine.Action
+
+// Strategy chooses a move for a player whose turn it is
+type Strategy interface {
+	ChooseAction(game *engine.Game, playerID string) (Action, error)
+}
+
+// Apply performs the action for the player through the rules engine
+func Apply(game *engine.Game, playerID string, action Action) error {
+	action.PlayerID = playerID
+	next, _, err := engine.Apply(engine.FromGame(game), action)
+	if err != nil {
+		return err
+	}
+	next.CopyInto(game)
+	return nil
+}
+
+// Heuristic is a simple rule-of-thumb strategy used for seats taken over by a bot
+// It plays the highest group it can legally follow with, saves wilds for when it is stuck,
+// and otherwise dumps its lowest group as an over-value play, or picks up the pile when the rules forbid that.
+type Heuristic struct{}
+
+// NewHeuristic creates the default bot strategy
+func NewHeuristic() *Heuristic {
+	return &Heuristic{}
+}
+
+// ChooseAction picks a move for the player using the heuristic
+func (b *Heuristic) ChooseAction(game *engine.Game, playerID string) (Action, error) {
+	var player *engine.Player
+	for _, p := range game.Players {
+		if p.ID == playerID {
+			player = p
+			break
+		}
+	}
+	if player == nil {
+		return Action{}, fmt.Errorf("player not found")
+	}
+
+	source := player.Hand
+	if len(source) == 0 {
+		source = player.TableCardsUp
+	}
+	if len(source) == 0 {
+		if len(player.TableCardsDown) == 0 {
+			return Action{}, fmt.Errorf("player has no cards")
+		}
+		return Action{Type: ActionFlip, CardIDs: []string{player.TableCardsDown[0].ID}}, nil
+	}
+
+	rules := game.ActiveRules()
+	groups := groupByValue(source)
+	var wilds, lowest, bestFollow, highest *cardGroup
+	for i := range groups {
+		g := &groups[i]
+		if rules.IsWild(g.value) {
+			wilds = g
+			continue
+		}
+		if lowest == nil {
+			lowest = g
+		}
+		highest = g
+	}
+
+	// Open the pile high so the low cards stay easy to follow with later
+	var top *engine.Card
+	if len(game.CenterPile) > 0 {
+		top = game.CenterPile[len(game.CenterPile)-1]
+	}
+	if top == nil || rules.IsWild(top.Value) || (game.AfterPickup && rules.PickupAllowsAnyPlay) {
+		if highest != nil {
+			return playGroup(highest), nil
+		}
+		return playSingle(wilds), nil
+	}
+
+	topRank := engine.GetCardValue(top)
+	for i := range groups {
+		g := &groups[i]
+		if !rules.IsWild(g.value) && g.rank <= topRank {
+			bestFollow = g
+		}
+	}
+	if bestFollow != nil {
+		return playGroup(bestFollow), nil
+	}
+	if wilds != nil {
+		return playSingle(wilds), nil
+	}
+	if !rules.AllowOverValue {
+		return Action{Type: ActionPickup}, nil
+	}
+	return playGroup(lowest), nil
+}
+
+type cardGroup struct {
+	value string
+	rank  int
+	ids   []string
+}
+
+// groupByValue groups cards by value, ordered from lowest to highest rank
+func groupByValue(cards []*engine.Card) []cardGroup {
+	index := make(map[string]int)
+	groups := make([]cardGroup, 0)
+	for _, card := range cards {
+		i, ok := index[card.Value]
+		if !ok {
+			i = len(groups)
+			index[card.Value] = i
+			groups = append(groups, cardGroup{value: card.Value, rank: engine.GetCardValue(card)})
+		}
+		groups[i].ids = append(groups[i].ids, card.ID)
+	}
+	sort.SliceStable(groups, func(a, b int) bool {
+		return groups[a].rank < groups[b].rank
+	})
+	return groups
+}
+
+func playGroup(g *cardGroup) Action {
+	return Action{Type: ActionPlay, CardIDs: append([]string(nil), g.ids...)}
+}
+
+// playSingle plays one card of the group, keeping the rest for later
+func playSingle(g *cardGroup) Action {
+	return Action{Type: ActionPlay, CardIDs: []string{g.ids[0]}}
+}
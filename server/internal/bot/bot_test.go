@@ -0,0 +1,98 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thben/clearthedeck/engine"
+)
+
+func newBotGame(hand, up, down, pile []*engine.Card) *engine.Game {
+	players := []*engine.Player{
+		{ID: "bot", Name: "Bot", IsBot: true, Hand: hand, TableCardsUp: up, TableCardsDown: down},
+		{ID: "p2", Name: "Player 2", Hand: []*engine.Card{{ID: "x", Value: "2"}}},
+	}
+	game := engine.NewGame("game-1", "ABCD", players)
+	game.IsStarted = true
+	game.CenterPile = pile
+	return game
+}
+
+func TestHeuristicChooseAction(t *testing.T) {
+	t.Run("opens an empty pile with the highest non-ten group", func(t *testing.T) {
+		game := newBotGame([]*engine.Card{
+			{ID: "h1", Value: "3"}, {ID: "h2", Value: "Q"}, {ID: "h3", Value: "Q"}, {ID: "h4", Value: "10"},
+		}, nil, nil, nil)
+
+		action, err := NewHeuristic().ChooseAction(game, "bot")
+
+		require.NoError(t, err)
+		assert.Equal(t, ActionPlay, action.Type)
+		assert.ElementsMatch(t, []string{"h2", "h3"}, action.CardIDs)
+	})
+
+	t.Run("follows with the highest group not above the top card", func(t *testing.T) {
+		game := newBotGame([]*engine.Card{
+			{ID: "h1", Value: "3"}, {ID: "h2", Value: "7"}, {ID: "h3", Value: "K"},
+		}, nil, nil, []*engine.Card{{ID: "c1", Value: "8"}})
+
+		action, err := NewHeuristic().ChooseAction(game, "bot")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"h2"}, action.CardIDs)
+	})
+
+	t.Run("uses a single ten when it cannot follow", func(t *testing.T) {
+		game := newBotGame([]*engine.Card{
+			{ID: "h1", Value: "10"}, {ID: "h2", Value: "10"}, {ID: "h3", Value: "K"},
+		}, nil, nil, []*engine.Card{{ID: "c1", Value: "4"}})
+
+		action, err := NewHeuristic().ChooseAction(game, "bot")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"h1"}, action.CardIDs)
+	})
+
+	t.Run("dumps the lowest group when stuck without tens", func(t *testing.T) {
+		game := newBotGame([]*engine.Card{
+			{ID: "h1", Value: "K"}, {ID: "h2", Value: "6"},
+		}, nil, nil, []*engine.Card{{ID: "c1", Value: "4"}})
+
+		action, err := NewHeuristic().ChooseAction(game, "bot")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"h2"}, action.CardIDs)
+	})
+
+	t.Run("plays face-up cards once the hand is empty", func(t *testing.T) {
+		game := newBotGame(nil, []*engine.Card{{ID: "u1", Value: "5"}}, []*engine.Card{{ID: "d1", Value: "9"}}, nil)
+
+		action, err := NewHeuristic().ChooseAction(game, "bot")
+
+		require.NoError(t, err)
+		assert.Equal(t, ActionPlay, action.Type)
+		assert.Equal(t, []string{"u1"}, action.CardIDs)
+	})
+
+	t.Run("flips a face-down card when nothing else is left", func(t *testing.T) {
+		game := newBotGame(nil, nil, []*engine.Card{{ID: "d1", Value: "9"}}, nil)
+
+		action, err := NewHeuristic().ChooseAction(game, "bot")
+
+		require.NoError(t, err)
+		assert.Equal(t, ActionFlip, action.Type)
+		assert.Equal(t, []string{"d1"}, action.CardIDs)
+	})
+}
+
+func TestApply(t *testing.T) {
+	game := newBotGame([]*engine.Card{{ID: "h1", Value: "7"}, {ID: "h2", Value: "2"}}, nil, nil, nil)
+
+	err := Apply(game, "bot", Action{Type: ActionPlay, CardIDs: []string{"h1"}})
+
+	require.NoError(t, err)
+	require.Len(t, game.CenterPile, 1)
+	assert.Equal(t, "h1", game.CenterPile[0].ID)
+	assert.Equal(t, 1, game.CurrentPlayerIndex)
+}
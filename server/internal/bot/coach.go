@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thben/clearthedeck/engine"
+)
+
+// MistakeMargin is how much better, in average reward, the coach's move must do before a player's move is a mistake
+const MistakeMargin = 0.1
+
+// coachBudget is the search the coach makes for each suggestion or review
+var coachBudget = Budget{Iterations: 300, Time: 100 * time.Millisecond}
+
+// Coach suggests moves and reviews a player's choices
+// It searches from the player's own point of view, so it knows no more than they do.
+type Coach struct {
+	search *MCTS
+}
+
+// NewCoach creates a coach with the default search budget
+func NewCoach() *Coach {
+	return NewCoachWithSearch(NewMCTS(coachBudget, 0))
+}
+
+// NewCoachWithSearch creates a coach that uses the given search
+func NewCoachWithSearch(search *MCTS) *Coach {
+	return &Coach{search: search}
+}
+
+// Suggestion is the move the coach would make
+type Suggestion struct {
+	Action      Action  `json:"action"`
+	Description string  `json:"description"`
+	Value       float64 `json:"value"` // Average reward the move got in the search, from 0 to 1
+}
+
+// Mistake is a move that did clearly worse in the coach's search than the coach's own choice
+type Mistake struct {
+	Round          int     `json:"round"`
+	Move           int     `json:"move"` // Action number within the round, from 1
+	Played         string  `json:"played"`
+	PlayedValue    float64 `json:"playedValue"`
+	Suggested      string  `json:"suggested"`
+	SuggestedValue float64 `json:"suggestedValue"`
+}
+
+// Suggest returns the coach's move for the player
+func (c *Coach) Suggest(game *engine.Game, playerID string) (Suggestion, error) {
+	evaluations, err := c.search.Evaluate(game, playerID)
+	if err != nil {
+		return Suggestion{}, err
+	}
+	best := evaluations[0]
+	return Suggestion{Action: best.Action, Description: Describe(game, best.Action), Value: best.Value}, nil
+}
+
+// Review judges a move before it is made and returns a mistake if the coach's move did clearly better
+func (c *Coach) Review(game *engine.Game, playerID string, played Action) (*Mistake, error) {
+	played.PlayerID = playerID
+	evaluations, err := c.search.Evaluate(game, playerID)
+	if err != nil {
+		return nil, err
+	}
+	best := evaluations[0]
+	key := actionKey(game, played)
+	for _, e := range evaluations {
+		if e.key != key {
+			continue
+		}
+		// Moves the search barely tried say little about how good they are
+		if e.Visits == 0 || best.Value-e.Value < MistakeMargin {
+			return nil, nil
+		}
+		return &Mistake{
+			Round:          game.Round,
+			Move:           game.Progress.Actions + 1,
+			Played:         Describe(game, played),
+			PlayedValue:    e.Value,
+			Suggested:      Describe(game, best.Action),
+			SuggestedValue: best.Value,
+		}, nil
+	}
+	return nil, nil
+}
+
+// Describe says what a move does in a few words, such as "play 7, 7" or "pick up the pile"
+func Describe(game *engine.Game, a Action) string {
+	switch a.Type {
+	case ActionFlip:
+		return "flip a face-down card"
+	case ActionPickup:
+		return "pick up the pile"
+	case ActionPlay:
+		if i := game.PlayerIndex(a.PlayerID); i >= 0 {
+			player := game.Players[i]
+			var values []string
+			for _, id := range a.CardIDs {
+				for _, area := range [][]*engine.Card{player.Hand, player.TableCardsUp} {
+					for _, card := range area {
+						if card.ID == id {
+							values = append(values, card.Value)
+						}
+					}
+				}
+			}
+			if len(values) > 0 {
+				return "play " + strings.Join(values, ", ")
+			}
+		}
+	}
+	return fmt.Sprintf("%s %v", a.Type, a.CardIDs)
+}
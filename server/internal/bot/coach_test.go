@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thben/clearthedeck/engine"
+)
+
+func newTestCoach() *Coach {
+	return NewCoachWithSearch(NewMCTS(testBudget, 1))
+}
+
+func TestCoachSuggest(t *testing.T) {
+	game := newBotGame([]*engine.Card{{ID: "h1", Value: "7"}}, nil, nil, []*engine.Card{{ID: "c1", Value: "9"}})
+
+	suggestion, err := newTestCoach().Suggest(game, "bot")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"h1"}, suggestion.Action.CardIDs)
+	assert.Equal(t, "play 7", suggestion.Description)
+	assert.Equal(t, 1.0, suggestion.Value)
+}
+
+func TestCoachReview(t *testing.T) {
+	game := newBotGame([]*engine.Card{{ID: "h1", Value: "7"}}, nil, nil, []*engine.Card{{ID: "c1", Value: "9"}})
+
+	t.Run("picking up instead of going out is a mistake", func(t *testing.T) {
+		mistake, err := newTestCoach().Review(game, "bot", Action{Type: ActionPickup})
+
+		require.NoError(t, err)
+		require.NotNil(t, mistake)
+		assert.Equal(t, "pick up the pile", mistake.Played)
+		assert.Equal(t, "play 7", mistake.Suggested)
+		assert.Equal(t, 1, mistake.Move)
+		assert.Greater(t, mistake.SuggestedValue-mistake.PlayedValue, MistakeMargin)
+	})
+
+	t.Run("the coach's own move is fine", func(t *testing.T) {
+		mistake, err := newTestCoach().Review(game, "bot", Action{Type: ActionPlay, CardIDs: []string{"h1"}})
+
+		require.NoError(t, err)
+		assert.Nil(t, mistake)
+	})
+}
+
+func TestDescribe(t *testing.T) {
+	game := newBotGame([]*engine.Card{{ID: "h1", Value: "Q"}, {ID: "h2", Value: "Q"}}, nil, nil, nil)
+
+	assert.Equal(t, "play Q, Q", Describe(game, Action{Type: ActionPlay, PlayerID: "bot", CardIDs: []string{"h1", "h2"}}))
+	assert.Equal(t, "flip a face-down card", Describe(game, Action{Type: ActionFlip, PlayerID: "bot"}))
+	assert.Equal(t, "pick up the pile", Describe(game, Action{Type: ActionPickup, PlayerID: "bot"}))
+}
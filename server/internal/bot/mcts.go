@@ -0,0 +1,377 @@
+package bot
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/models"
+)
+
+// Budget bounds the search for one move; whichever limit is reached first ends it
+type Budget struct {
+	Iterations int           // Playouts per move
+	Time       time.Duration // Zero means no time limit
+}
+
+// Budgets for each difficulty
+var levelBudgets = map[models.BotLevel]Budget{
+	models.BotEasy:   {Iterations: 60, Time: 20 * time.Millisecond},
+	models.BotMedium: {Iterations: 300, Time: 100 * time.Millisecond},
+	models.BotHard:   {Iterations: 1500, Time: 400 * time.Millisecond},
+}
+
+// ForLevel returns the strategy for a difficulty; basic and unknown levels use the heuristic
+func ForLevel(level models.BotLevel) Strategy {
+	budget, ok := levelBudgets[level]
+	if !ok {
+		return NewHeuristic()
+	}
+	return NewMCTS(budget, 0)
+}
+
+const (
+	// exploration weighs trying rarely chosen moves against repeating good ones
+	exploration = 0.7
+	// rolloutNoise is how often a playout makes a random legal move instead of the heuristic one
+	rolloutNoise = 0.2
+	// maxPlayoutMoves stops a playout that is going nowhere; the position is then scored as it stands
+	maxPlayoutMoves = 400
+)
+
+// MCTS searches for moves with information-set Monte Carlo tree search
+// Each playout deals the cards the bot cannot see (other hands, every face-down card and the
+// undealt part of the deck) at random among the places they could be, then plays the round out
+// with the rules engine. Statistics are shared across these deals, so the bot chooses the move
+// that does best over everything the hidden cards could be rather than peeking at them.
+//
+// Cards an opponent picked up from the pile are treated as unknown, since the strategy sees only
+// the current position.
+type MCTS struct {
+	budget  Budget
+	seed    int64 // Non-zero seeds make searches repeatable
+	rollout *Heuristic
+}
+
+// NewMCTS creates a search bot; a zero seed picks a new one for every move
+func NewMCTS(budget Budget, seed int64) *MCTS {
+	if budget.Iterations <= 0 {
+		budget.Iterations = 1
+	}
+	return &MCTS{budget: budget, seed: seed, rollout: NewHeuristic()}
+}
+
+// node is a move in the search tree, shared by every deal in which the move was available
+type node struct {
+	key      string
+	action   Action
+	mover    string
+	children []*node
+	visits   int
+	avail    int     // Playouts in which the move could have been made
+	reward   float64 // Total reward for the mover
+}
+
+// Evaluation is how a move fared in a search
+type Evaluation struct {
+	Action Action
+	Value  float64 // Average reward for the player, from 0 (holding the most points) to 1 (going out)
+	Visits int
+	key    string
+}
+
+// ChooseAction searches from the player's point of view and returns the move tried most
+func (m *MCTS) ChooseAction(game *engine.Game, playerID string) (Action, error) {
+	evaluations, err := m.Evaluate(game, playerID)
+	if err != nil {
+		return Action{}, err
+	}
+	return evaluations[0].Action, nil
+}
+
+// Evaluate searches from the player's point of view and returns their moves, most tried first
+// A player with only one legal move gets it back without a search.
+func (m *MCTS) Evaluate(game *engine.Game, playerID string) ([]Evaluation, error) {
+	if game.PlayerIndex(playerID) < 0 {
+		return nil, fmt.Errorf("player not found")
+	}
+	seed := m.seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	view := engine.FromGame(game)
+	first := determinize(view.Game(), playerID, rng)
+	legal := engine.LegalActions(first, playerID)
+	switch len(legal) {
+	case 0:
+		return nil, fmt.Errorf("no legal moves")
+	case 1:
+		return []Evaluation{{Action: legal[0], key: actionKey(first.Game(), legal[0])}}, nil
+	}
+
+	root := &node{}
+	var deadline time.Time
+	if m.budget.Time > 0 {
+		deadline = time.Now().Add(m.budget.Time)
+	}
+	for i := 0; i < m.budget.Iterations; i++ {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			break
+		}
+		m.iterate(root, determinize(view.Game(), playerID, rng), rng)
+	}
+	if len(root.children) == 0 {
+		return []Evaluation{{Action: legal[0], key: actionKey(first.Game(), legal[0])}}, nil
+	}
+
+	evaluations := make([]Evaluation, 0, len(root.children))
+	for _, c := range root.children {
+		e := Evaluation{Action: c.action, Visits: c.visits, key: c.key}
+		if c.visits > 0 {
+			e.Value = c.reward / float64(c.visits)
+		}
+		evaluations = append(evaluations, e)
+	}
+	sort.SliceStable(evaluations, func(i, j int) bool {
+		return evaluations[i].Visits > evaluations[j].Visits
+	})
+	return evaluations, nil
+}
+
+// iterate runs one playout on a deal: down the tree, one new move, then a playout to the end of the round
+func (m *MCTS) iterate(root *node, world engine.State, rng *rand.Rand) {
+	var path []*node
+	n := root
+	for !world.RoundOver() {
+		mover := world.CurrentPlayerID()
+		legal := engine.LegalActions(world, mover)
+		if len(legal) == 0 {
+			break
+		}
+
+		game := world.Game()
+		byKey := make(map[string]Action, len(legal))
+		var keys []string
+		for _, a := range legal {
+			key := actionKey(game, a)
+			if _, seen := byKey[key]; !seen {
+				byKey[key] = a
+				keys = append(keys, key)
+			}
+		}
+		var available []*node
+		tried := make(map[string]bool, len(n.children))
+		for _, c := range n.children {
+			tried[c.key] = true
+			if _, ok := byKey[c.key]; ok {
+				c.avail++
+				available = append(available, c)
+			}
+		}
+
+		// Expand a move not tried yet in this spot
+		var untried []string
+		for _, key := range keys {
+			if !tried[key] {
+				untried = append(untried, key)
+			}
+		}
+		if len(untried) > 0 {
+			key := untried[rng.Intn(len(untried))]
+			child := &node{key: key, action: byKey[key], mover: mover, avail: 1}
+			n.children = append(n.children, child)
+			world = apply(world, child.action)
+			path = append(path, child)
+			break
+		}
+
+		n = selectChild(available)
+		world = apply(world, byKey[n.key])
+		path = append(path, n)
+	}
+
+	rewards := m.playout(world, rng)
+	for _, c := range path {
+		c.visits++
+		c.reward += rewards[c.mover]
+	}
+}
+
+// selectChild picks the move with the best upper confidence bound among those available
+func selectChild(available []*node) *node {
+	var best *node
+	bestScore := math.Inf(-1)
+	for _, c := range available {
+		score := math.Inf(1)
+		if c.visits > 0 {
+			score = c.reward/float64(c.visits) + exploration*math.Sqrt(math.Log(float64(c.avail))/float64(c.visits))
+		}
+		if score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best
+}
+
+// playout finishes the round with mostly heuristic moves and returns each player's reward
+// The playout owns its copy of the game, so moves are made on it directly rather than through
+// engine.Apply, which copies the whole game for every move.
+func (m *MCTS) playout(world engine.State, rng *rand.Rand) map[string]float64 {
+	game := world.Game()
+	for moves := 0; moves < maxPlayoutMoves && !game.RoundOver(); moves++ {
+		current := game.GetCurrentPlayer()
+		if current == nil {
+			break
+		}
+		if rng.Float64() < rolloutNoise && step(game, current.ID, randomPlay(current, rng)) == nil {
+			continue
+		}
+		action, err := m.rollout.ChooseAction(game, current.ID)
+		if err == nil {
+			err = step(game, current.ID, action)
+		}
+		if err != nil && step(game, current.ID, Action{Type: ActionPickup}) != nil {
+			break
+		}
+	}
+	return rewards(game)
+}
+
+// randomPlay plays every card of a random value the player could play from, which may not be legal
+func randomPlay(player *engine.Player, rng *rand.Rand) Action {
+	source := player.Hand
+	if len(source) == 0 {
+		source = player.TableCardsUp
+	}
+	if len(source) == 0 {
+		if len(player.TableCardsDown) == 0 {
+			return Action{}
+		}
+		return Action{Type: ActionFlip, CardIDs: []string{player.TableCardsDown[rng.Intn(len(player.TableCardsDown))].ID}}
+	}
+	groups := groupByValue(source)
+	return playGroup(&groups[rng.Intn(len(groups))])
+}
+
+// step makes a move in place and scores the round if it ended, as engine.Apply does
+func step(game *engine.Game, playerID string, a Action) error {
+	var err error
+	switch a.Type {
+	case ActionPlay:
+		err = engine.PlayCards(game, playerID, a.CardIDs, a.AfterPickup)
+	case ActionFlip:
+		err = engine.FlipFaceDown(game, playerID, a.CardIDs[0])
+	case ActionPickup:
+		if len(game.CenterPile) == 0 {
+			return fmt.Errorf("center pile is empty")
+		}
+		err = engine.PickupPile(game, playerID)
+	default:
+		err = fmt.Errorf("unknown action: %s", a.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, p := range game.Players {
+		if engine.CheckWinCondition(p) {
+			engine.EndRound(game, p.ID)
+			return nil
+		}
+	}
+	if stuck, reason := engine.CheckStalemate(game); stuck {
+		engine.EndRoundInStalemate(game, reason)
+	}
+	return nil
+}
+
+// rewards scores a position between 0 and 1 per player: going out is worth 1 and holding the most points 0
+func rewards(game *engine.Game) map[string]float64 {
+	scoring := game.ActiveScoring()
+	scores := make(map[string]int, len(game.Players))
+	most := 0
+	for _, p := range game.Players {
+		score := p.RoundScore
+		if !game.RoundOver() {
+			score = engine.CalculatePlayerScore(p, scoring)
+		}
+		scores[p.ID] = score
+		if score > most {
+			most = score
+		}
+	}
+	result := make(map[string]float64, len(scores))
+	for id, score := range scores {
+		result[id] = 1
+		if most > 0 {
+			result[id] = 1 - float64(score)/float64(most)
+		}
+	}
+	return result
+}
+
+// actionKey names a move the same way in every deal: plays by value and count, flips by card
+func actionKey(game *engine.Game, a Action) string {
+	if a.Type == ActionPlay {
+		player := game.Players[game.PlayerIndex(a.PlayerID)]
+		for _, area := range [][]*engine.Card{player.Hand, player.TableCardsUp} {
+			for _, c := range area {
+				if c.ID == a.CardIDs[0] {
+					return fmt.Sprintf("play:%sx%d", c.Value, len(a.CardIDs))
+				}
+			}
+		}
+	}
+	return fmt.Sprintf("%s:%v", a.Type, a.CardIDs)
+}
+
+func apply(world engine.State, a Action) engine.State {
+	next, _, err := engine.Apply(world, a)
+	if err != nil {
+		return world
+	}
+	return next
+}
+
+// determinize deals the cards the viewer cannot see at random among the places they could be
+// Card IDs stay where they are so moves keep naming the same cards; only their faces move.
+func determinize(game *engine.Game, viewerID string, rng *rand.Rand) engine.State {
+	var areas [][]*engine.Card
+	for _, p := range game.Players {
+		if p.ID != viewerID {
+			areas = append(areas, p.Hand)
+		}
+		areas = append(areas, p.TableCardsDown)
+	}
+	areas = append(areas, game.DiscardPile[:game.Undealt])
+
+	var faces []engine.Card
+	for _, area := range areas {
+		for _, c := range area {
+			faces = append(faces, *c)
+		}
+	}
+	// Sorted first so the deal depends only on which cards are hidden, not on where they really are
+	sort.Slice(faces, func(i, j int) bool {
+		if faces[i].Value != faces[j].Value {
+			return faces[i].Value < faces[j].Value
+		}
+		return faces[i].Suit < faces[j].Suit
+	})
+	rng.Shuffle(len(faces), func(i, j int) { faces[i], faces[j] = faces[j], faces[i] })
+
+	// Cards are shared between copies of a game, so hidden ones are replaced rather than changed
+	next := 0
+	for _, area := range areas {
+		for i, c := range area {
+			area[i] = &engine.Card{ID: c.ID, Suit: faces[next].Suit, Value: faces[next].Value}
+			next++
+		}
+	}
+	return engine.FromGame(game)
+}
@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/models"
+)
+
+var testBudget = Budget{Iterations: 200}
+
+// dealtGame deals a seeded three-player game and gives the bot the first turn
+func dealtGame(t *testing.T, seed int64) *engine.Game {
+	state, err := engine.NewState([]engine.Seat{{ID: "bot", Name: "Bot"}, {ID: "p2", Name: "P2"}, {ID: "p3", Name: "P3"}}, engine.Config{Seed: seed})
+	require.NoError(t, err)
+	return state.Game()
+}
+
+func faces(cards []*engine.Card) []string {
+	out := make([]string, len(cards))
+	for i, c := range cards {
+		out[i] = c.Suit + c.Value
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestMCTSChooseAction(t *testing.T) {
+	t.Run("goes out rather than picking up", func(t *testing.T) {
+		game := newBotGame([]*engine.Card{{ID: "h1", Value: "7"}}, nil, nil, []*engine.Card{{ID: "c1", Value: "9"}})
+
+		action, err := NewMCTS(testBudget, 1).ChooseAction(game, "bot")
+
+		require.NoError(t, err)
+		assert.Equal(t, ActionPlay, action.Type)
+		assert.Equal(t, []string{"h1"}, action.CardIDs)
+	})
+
+	t.Run("returns a legal move from a dealt game", func(t *testing.T) {
+		game := dealtGame(t, 7)
+
+		action, err := NewMCTS(testBudget, 1).ChooseAction(game, "bot")
+
+		require.NoError(t, err)
+		assert.NoError(t, engine.Validate(engine.FromGame(game), action))
+	})
+
+	t.Run("does not look at hidden cards", func(t *testing.T) {
+		game := dealtGame(t, 11)
+		peeked := game.Clone()
+		// Swap what the other players hold and what lies face down; nothing the bot can see changes
+		peeked.Players[1].Hand, peeked.Players[2].Hand = peeked.Players[2].Hand, peeked.Players[1].Hand
+		peeked.Players[0].TableCardsDown, peeked.Players[1].TableCardsDown = peeked.Players[1].TableCardsDown, peeked.Players[0].TableCardsDown
+
+		first, err := NewMCTS(testBudget, 3).ChooseAction(game, "bot")
+		require.NoError(t, err)
+		second, err := NewMCTS(testBudget, 3).ChooseAction(peeked, "bot")
+		require.NoError(t, err)
+
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("reports a player who is not seated", func(t *testing.T) {
+		_, err := NewMCTS(testBudget, 1).ChooseAction(dealtGame(t, 1), "nobody")
+		assert.Error(t, err)
+	})
+}
+
+func TestDeterminize(t *testing.T) {
+	game := dealtGame(t, 5)
+	hidden := func(g *engine.Game) []string {
+		var cards []*engine.Card
+		for _, p := range g.Players {
+			if p.ID != "bot" {
+				cards = append(cards, p.Hand...)
+			}
+			cards = append(cards, p.TableCardsDown...)
+		}
+		return faces(append(cards, g.DiscardPile[:g.Undealt]...))
+	}
+
+	world := determinize(game.Clone(), "bot", rand.New(rand.NewSource(1))).Game()
+
+	// The bot's own hand and every face-up card stay put; hidden cards are only moved around
+	assert.Equal(t, game.Players[0].Hand, world.Players[0].Hand)
+	for i := range game.Players {
+		assert.Equal(t, game.Players[i].TableCardsUp, world.Players[i].TableCardsUp)
+		assert.Len(t, world.Players[i].TableCardsDown, len(game.Players[i].TableCardsDown))
+	}
+	assert.Equal(t, hidden(game), hidden(world))
+	assert.NotEqual(t, faces(game.Players[1].Hand), faces(world.Players[1].Hand))
+}
+
+func TestForLevel(t *testing.T) {
+	assert.IsType(t, &Heuristic{}, ForLevel(models.BotBasic))
+	assert.IsType(t, &MCTS{}, ForLevel(models.BotHard))
+}
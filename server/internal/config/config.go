@@ -0,0 +1,441 @@
+// Package config loads the server configuration from defaults, an optional YAML file, the environment and flags
+//
+// Later sources win: a value set by a flag overrides the environment, which overrides the file.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Environments
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// Log levels
+const (
+	LogDebug = "debug"
+	LogInfo  = "info"
+	LogWarn  = "warn"
+	LogError = "error"
+)
+
+// Log formats
+const (
+	LogText = "text"
+	LogJSON = "json"
+)
+
+// Config is everything the server can be configured with
+type Config struct {
+	Env         string   `yaml:"env"`         // development allows any origin
+	Listen      string   `yaml:"listen"`      // host:port to listen on
+	TLSCert     string   `yaml:"tlsCert"`     // Certificate file; serves HTTPS when set with tlsKey
+	TLSKey      string   `yaml:"tlsKey"`      // Private key file
+	Origins     []string `yaml:"origins"`     // Origins allowed to open WebSockets outside development
+	PublicWSURL string   `yaml:"publicWsUrl"` // WebSocket URL given to the embedded client
+	LogLevel    string   `yaml:"logLevel"`
+	LogFormat   string   `yaml:"logFormat"` // text for people, json for log collectors
+	DataDir     string   `yaml:"dataDir"`   // Where games are saved at shutdown and accounts are kept; empty keeps nothing
+
+	AuthSecret string        `yaml:"authSecret"` // Signs session tokens; empty picks one per run, signing everyone out at restart
+	TokenTTL   time.Duration `yaml:"tokenTtl"`   // How long a sign-in lasts
+
+	MaxRooms       int `yaml:"maxRooms"`       // Rooms open at once; 0 means no limit
+	MaxConnsPerIP  int `yaml:"maxConnsPerIp"`  // WebSockets from one address; 0 means no limit
+	MaxLoginsPerIP int `yaml:"maxLoginsPerIp"` // Register and login requests from one address each minute; 0 means no limit
+
+	ReadTimeout  time.Duration `yaml:"readTimeout"` // Reading a request, headers included
+	WriteTimeout time.Duration `yaml:"writeTimeout"`
+	IdleTimeout  time.Duration `yaml:"idleTimeout"` // Keep-alive connections between requests
+
+	ShutdownCountdown time.Duration `yaml:"shutdownCountdown"` // Warning players get before connections are closed
+	ShutdownTimeout   time.Duration `yaml:"shutdownTimeout"`   // Longest a shutdown may take before the process exits anyway
+
+	LobbyTTL        time.Duration `yaml:"lobbyTtl"`
+	FinishedGameTTL time.Duration `yaml:"finishedGameTtl"`
+	IdleGameTTL     time.Duration `yaml:"idleGameTtl"`
+	SweepInterval   time.Duration `yaml:"sweepInterval"`
+
+	TurnTimeout    time.Duration `yaml:"turnTimeout"` // 0 disables turn timers
+	UndoVoteWindow time.Duration `yaml:"undoVoteWindow"`
+	SeatHold       time.Duration `yaml:"seatHold"`
+	DefaultRules   string        `yaml:"defaultRules"` // Rule set preset new rooms start with
+}
+
+// Default returns the configuration used when nothing is set
+func Default() Config {
+	return Config{
+		Env:               EnvDevelopment,
+		Listen:            ":8080",
+		LogLevel:          LogInfo,
+		LogFormat:         LogText,
+		MaxRooms:          1000,
+		MaxConnsPerIP:     20,
+		MaxLoginsPerIP:    10,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      15 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		ShutdownCountdown: 10 * time.Second,
+		ShutdownTimeout:   30 * time.Second,
+		LobbyTTL:          30 * time.Minute,
+		FinishedGameTTL:   10 * time.Minute,
+		IdleGameTTL:       2 * time.Hour,
+		SweepInterval:     time.Minute,
+		UndoVoteWindow:    8 * time.Second,
+		SeatHold:          models.DefaultSeatHoldDuration,
+		DefaultRules:      engine.RulesStandard,
+		TokenTTL:          30 * 24 * time.Hour,
+	}
+}
+
+// Load builds the configuration from the command line arguments (without the program name) and the environment
+// The YAML file is named by -config or CONFIG_FILE
+func Load(args []string, getenv func(string) string) (Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("clearthedeck", flag.ContinueOnError)
+	configFile := fs.String("config", getenv("CONFIG_FILE"), "YAML configuration file")
+	flags := newFlagValues(fs)
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile); err != nil {
+			return Config{}, err
+		}
+	}
+	if err := cfg.loadEnv(getenv); err != nil {
+		return Config{}, err
+	}
+	flags.apply(fs, &cfg)
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv applies the environment variables that are set
+func (c *Config) loadEnv(getenv func(string) string) error {
+	var errs []error
+	str := func(name string, dst *string) {
+		if v := getenv(name); v != "" {
+			*dst = v
+		}
+	}
+	num := func(name string, dst *int) {
+		if v := getenv(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not a whole number", name, v))
+				return
+			}
+			*dst = n
+		}
+	}
+	dur := func(name string, dst *time.Duration) {
+		if v := getenv(name); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not a duration like 30s or 10m", name, v))
+				return
+			}
+			*dst = d
+		}
+	}
+
+	// PORT and SERVER_PORT are the older ways of choosing where to listen
+	for _, name := range []string{"SERVER_PORT", "PORT"} {
+		if port := getenv(name); port != "" {
+			c.Listen = ":" + port
+		}
+	}
+	str("LISTEN_ADDR", &c.Listen)
+	str("ENV", &c.Env)
+	str("TLS_CERT_FILE", &c.TLSCert)
+	str("TLS_KEY_FILE", &c.TLSKey)
+	if v := getenv("ALLOWED_ORIGINS"); v != "" {
+		c.Origins = splitList(v)
+	}
+	str("PUBLIC_WS_URL", &c.PublicWSURL)
+	str("LOG_LEVEL", &c.LogLevel)
+	str("LOG_FORMAT", &c.LogFormat)
+	str("DATA_DIR", &c.DataDir)
+	str("AUTH_SECRET", &c.AuthSecret)
+	dur("TOKEN_TTL", &c.TokenTTL)
+	num("MAX_ROOMS", &c.MaxRooms)
+	num("MAX_CONNECTIONS_PER_IP", &c.MaxConnsPerIP)
+	num("MAX_LOGINS_PER_IP", &c.MaxLoginsPerIP)
+	dur("READ_TIMEOUT", &c.ReadTimeout)
+	dur("WRITE_TIMEOUT", &c.WriteTimeout)
+	dur("IDLE_TIMEOUT", &c.IdleTimeout)
+	dur("SHUTDOWN_COUNTDOWN", &c.ShutdownCountdown)
+	dur("SHUTDOWN_TIMEOUT", &c.ShutdownTimeout)
+	dur("LOBBY_TTL", &c.LobbyTTL)
+	dur("FINISHED_GAME_TTL", &c.FinishedGameTTL)
+	dur("IDLE_GAME_TTL", &c.IdleGameTTL)
+	dur("SWEEP_INTERVAL", &c.SweepInterval)
+	dur("TURN_TIMEOUT", &c.TurnTimeout)
+	dur("UNDO_VOTE_WINDOW", &c.UndoVoteWindow)
+	dur("SEAT_HOLD", &c.SeatHold)
+	str("DEFAULT_RULES", &c.DefaultRules)
+
+	return errors.Join(errs...)
+}
+
+// flagValues holds the command line flags until it is known which were set
+type flagValues struct {
+	listen, tlsCert, tlsKey, origins, publicWSURL, logLevel, logFormat, env, defaultRules, dataDir             *string
+	maxRooms, maxConnsPerIP, maxLoginsPerIP                                                                    *int
+	lobbyTTL, finishedGameTTL, idleGameTTL, turnTimeout, undoVoteWindow, seatHold, shutdownCountdown, tokenTTL *time.Duration
+}
+
+func newFlagValues(fs *flag.FlagSet) *flagValues {
+	d := Default()
+	return &flagValues{
+		listen:            fs.String("listen", d.Listen, "address to listen on"),
+		tlsCert:           fs.String("tls-cert", "", "TLS certificate file"),
+		tlsKey:            fs.String("tls-key", "", "TLS private key file"),
+		origins:           fs.String("origins", "", "comma-separated origins allowed to connect"),
+		publicWSURL:       fs.String("public-ws-url", "", "WebSocket URL given to the embedded client"),
+		logLevel:          fs.String("log-level", d.LogLevel, "debug, info, warn or error"),
+		logFormat:         fs.String("log-format", d.LogFormat, "text or json"),
+		env:               fs.String("env", d.Env, "development or production"),
+		defaultRules:      fs.String("default-rules", d.DefaultRules, "rule set preset new rooms start with"),
+		dataDir:           fs.String("data-dir", "", "directory games are saved to at shutdown"),
+		maxRooms:          fs.Int("max-rooms", d.MaxRooms, "rooms open at once, 0 for no limit"),
+		maxConnsPerIP:     fs.Int("max-conns-per-ip", d.MaxConnsPerIP, "WebSockets from one address, 0 for no limit"),
+		maxLoginsPerIP:    fs.Int("max-logins-per-ip", d.MaxLoginsPerIP, "register and login requests from one address each minute, 0 for no limit"),
+		lobbyTTL:          fs.Duration("lobby-ttl", d.LobbyTTL, "idle time before a lobby is closed"),
+		finishedGameTTL:   fs.Duration("finished-game-ttl", d.FinishedGameTTL, "idle time before a finished game is closed"),
+		idleGameTTL:       fs.Duration("idle-game-ttl", d.IdleGameTTL, "idle time before a running game is closed"),
+		turnTimeout:       fs.Duration("turn-timeout", 0, "time a player has for a turn before a bot plays it, 0 to disable"),
+		undoVoteWindow:    fs.Duration("undo-vote-window", d.UndoVoteWindow, "time the table has to approve an undo"),
+		seatHold:          fs.Duration("seat-hold", d.SeatHold, "how long a departed player's seat is held"),
+		shutdownCountdown: fs.Duration("shutdown-countdown", d.ShutdownCountdown, "warning players get before a shutdown closes connections"),
+		tokenTTL:          fs.Duration("token-ttl", d.TokenTTL, "how long a sign-in lasts"),
+	}
+}
+
+// apply copies the flags given on the command line into the configuration
+func (f *flagValues) apply(fs *flag.FlagSet, c *Config) {
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "listen":
+			c.Listen = *f.listen
+		case "tls-cert":
+			c.TLSCert = *f.tlsCert
+		case "tls-key":
+			c.TLSKey = *f.tlsKey
+		case "origins":
+			c.Origins = splitList(*f.origins)
+		case "public-ws-url":
+			c.PublicWSURL = *f.publicWSURL
+		case "log-level":
+			c.LogLevel = *f.logLevel
+		case "log-format":
+			c.LogFormat = *f.logFormat
+		case "env":
+			c.Env = *f.env
+		case "default-rules":
+			c.DefaultRules = *f.defaultRules
+		case "max-rooms":
+			c.MaxRooms = *f.maxRooms
+		case "max-conns-per-ip":
+			c.MaxConnsPerIP = *f.maxConnsPerIP
+		case "max-logins-per-ip":
+			c.MaxLoginsPerIP = *f.maxLoginsPerIP
+		case "lobby-ttl":
+			c.LobbyTTL = *f.lobbyTTL
+		case "finished-game-ttl":
+			c.FinishedGameTTL = *f.finishedGameTTL
+		case "idle-game-ttl":
+			c.IdleGameTTL = *f.idleGameTTL
+		case "turn-timeout":
+			c.TurnTimeout = *f.turnTimeout
+		case "undo-vote-window":
+			c.UndoVoteWindow = *f.undoVoteWindow
+		case "seat-hold":
+			c.SeatHold = *f.seatHold
+		case "data-dir":
+			c.DataDir = *f.dataDir
+		case "shutdown-countdown":
+			c.ShutdownCountdown = *f.shutdownCountdown
+		case "token-ttl":
+			c.TokenTTL = *f.tokenTTL
+		}
+	})
+}
+
+// Validate reports every problem with the configuration at once
+func (c Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	switch c.Env {
+	case EnvDevelopment, EnvProduction:
+	default:
+		fail("env: %q must be %s or %s", c.Env, EnvDevelopment, EnvProduction)
+	}
+	if _, port, err := net.SplitHostPort(c.Listen); err != nil {
+		fail("listen: %q must be host:port, e.g. :8080", c.Listen)
+	} else if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		fail("listen: port %q must be a number from 0 to 65535", port)
+	}
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		fail("tls: a certificate and a key must be given together")
+	}
+	for _, file := range []string{c.TLSCert, c.TLSKey} {
+		if file == "" {
+			continue
+		}
+		if _, err := os.Stat(file); err != nil {
+			fail("tls: %v", err)
+		}
+	}
+	if c.Env == EnvProduction && len(c.Origins) == 0 {
+		fail("origins: at least one allowed origin is required in production")
+	}
+	switch c.LogLevel {
+	case LogDebug, LogInfo, LogWarn, LogError:
+	default:
+		fail("logLevel: %q must be debug, info, warn or error", c.LogLevel)
+	}
+	switch c.LogFormat {
+	case LogText, LogJSON:
+	default:
+		fail("logFormat: %q must be %s or %s", c.LogFormat, LogText, LogJSON)
+	}
+
+	if c.MaxRooms < 0 {
+		fail("maxRooms: must not be negative")
+	}
+	if c.MaxConnsPerIP < 0 {
+		fail("maxConnsPerIp: must not be negative")
+	}
+	if c.MaxLoginsPerIP < 0 {
+		fail("maxLoginsPerIp: must not be negative")
+	}
+
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"readTimeout", c.ReadTimeout},
+		{"writeTimeout", c.WriteTimeout},
+		{"idleTimeout", c.IdleTimeout},
+		{"shutdownTimeout", c.ShutdownTimeout},
+		{"lobbyTtl", c.LobbyTTL},
+		{"finishedGameTtl", c.FinishedGameTTL},
+		{"idleGameTtl", c.IdleGameTTL},
+		{"sweepInterval", c.SweepInterval},
+		{"undoVoteWindow", c.UndoVoteWindow},
+		{"seatHold", c.SeatHold},
+		{"tokenTtl", c.TokenTTL},
+	} {
+		if d.value <= 0 {
+			fail("%s: must be greater than zero", d.name)
+		}
+	}
+	if c.ShutdownCountdown < 0 {
+		fail("shutdownCountdown: must not be negative")
+	} else if c.ShutdownCountdown >= c.ShutdownTimeout {
+		fail("shutdownCountdown: %s must be shorter than shutdownTimeout (%s) to leave time to save games", c.ShutdownCountdown, c.ShutdownTimeout)
+	}
+	if c.TurnTimeout < 0 {
+		fail("turnTimeout: must not be negative")
+	} else if c.TurnTimeout > 0 && c.TurnTimeout < 5*time.Second {
+		fail("turnTimeout: %s is too short to play a turn; use at least 5s", c.TurnTimeout)
+	}
+	if c.AuthSecret != "" && len(c.AuthSecret) < 32 {
+		fail("authSecret: must be at least 32 characters")
+	}
+	if _, ok := engine.LookupRuleSet(c.DefaultRules); !ok || c.DefaultRules == engine.RulesCustom {
+		fail("defaultRules: unknown rule set %q", c.DefaultRules)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration:\n  %w", joinLines(errs))
+}
+
+// Rules returns the rule set new rooms start with
+func (c Config) Rules() engine.RuleSet {
+	rules, _ := engine.LookupRuleSet(c.DefaultRules)
+	return rules
+}
+
+// Logger builds the server's logger from the log level and format
+func (c Config) Logger(w io.Writer) *slog.Logger {
+	level := slog.LevelInfo
+	switch c.LogLevel {
+	case LogDebug:
+		level = slog.LevelDebug
+	case LogWarn:
+		level = slog.LevelWarn
+	case LogError:
+		level = slog.LevelError
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	if c.LogFormat == LogJSON {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+// TLS reports whether the server should serve HTTPS
+func (c Config) TLS() bool {
+	return c.TLSCert != "" && c.TLSKey != ""
+}
+
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// joinLines joins errors one per line, indented under the heading
+func joinLines(errs []error) error {
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = err.Error()
+	}
+	return errors.New(strings.Join(lines, "\n  "))
+}
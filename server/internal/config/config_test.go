@@ -0,0 +1,169 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func writeFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("defaults are valid", func(t *testing.T) {
+		cfg, err := Load(nil, env(nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, Default(), cfg)
+		assert.Equal(t, "standard", cfg.Rules().Name)
+		assert.False(t, cfg.TLS())
+	})
+
+	t.Run("flags override the environment, which overrides the file", func(t *testing.T) {
+		path := writeFile(t, "listen: \":7000\"\nmaxRooms: 10\nlobbyTtl: 5m\nturnTimeout: 30s\norigins: [\"https://file.example\"]\n")
+
+		cfg, err := Load([]string{"-config", path, "-max-rooms", "30"}, env(map[string]string{
+			"MAX_ROOMS":       "20",
+			"LOBBY_TTL":       "15m",
+			"ALLOWED_ORIGINS": "https://a.example, https://b.example",
+		}))
+
+		require.NoError(t, err)
+		assert.Equal(t, ":7000", cfg.Listen)
+		assert.Equal(t, 30, cfg.MaxRooms)
+		assert.Equal(t, 15*time.Minute, cfg.LobbyTTL)
+		assert.Equal(t, 30*time.Second, cfg.TurnTimeout)
+		assert.Equal(t, []string{"https://a.example", "https://b.example"}, cfg.Origins)
+	})
+
+	t.Run("the file can be named in the environment", func(t *testing.T) {
+		path := writeFile(t, "defaultRules: strict\n")
+
+		cfg, err := Load(nil, env(map[string]string{"CONFIG_FILE": path}))
+
+		require.NoError(t, err)
+		assert.Equal(t, "strict", cfg.Rules().Name)
+	})
+
+	t.Run("PORT still chooses the port", func(t *testing.T) {
+		cfg, err := Load(nil, env(map[string]string{"PORT": "9090"}))
+
+		require.NoError(t, err)
+		assert.Equal(t, ":9090", cfg.Listen)
+	})
+
+	t.Run("unknown file settings are rejected", func(t *testing.T) {
+		path := writeFile(t, "maxRoom: 10\n")
+
+		_, err := Load([]string{"-config", path}, env(nil))
+
+		assert.ErrorContains(t, err, "maxRoom")
+	})
+
+	t.Run("malformed environment values are reported by name", func(t *testing.T) {
+		_, err := Load(nil, env(map[string]string{"IDLE_GAME_TTL": "two hours", "MAX_ROOMS": "lots"}))
+
+		assert.ErrorContains(t, err, "IDLE_GAME_TTL")
+		assert.ErrorContains(t, err, "MAX_ROOMS")
+	})
+
+	t.Run("a missing file is an error", func(t *testing.T) {
+		_, err := Load([]string{"-config", filepath.Join(t.TempDir(), "missing.yaml")}, env(nil))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("reports every problem", func(t *testing.T) {
+		cfg := Default()
+		cfg.Env = "staging"
+		cfg.LogLevel = "loud"
+		cfg.LogFormat = "xml"
+		cfg.SeatHold = 0
+		cfg.TLSCert = "cert.pem"
+
+		err := cfg.Validate()
+
+		require.Error(t, err)
+		for _, want := range []string{"env", "logLevel", "logFormat", "seatHold", "tls: a certificate and a key must be given together"} {
+			assert.ErrorContains(t, err, want)
+		}
+	})
+
+	t.Run("production needs allowed origins", func(t *testing.T) {
+		cfg := Default()
+		cfg.Env = EnvProduction
+		assert.ErrorContains(t, cfg.Validate(), "origins")
+
+		cfg.Origins = []string{"https://cards.example"}
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("TLS files must exist", func(t *testing.T) {
+		cfg := Default()
+		cfg.TLSCert = filepath.Join(t.TempDir(), "cert.pem")
+		cfg.TLSKey = filepath.Join(t.TempDir(), "key.pem")
+
+		assert.ErrorContains(t, cfg.Validate(), "cert.pem")
+	})
+
+	t.Run("turn timers may be off but not too short", func(t *testing.T) {
+		cfg := Default()
+		cfg.TurnTimeout = 0
+		assert.NoError(t, cfg.Validate())
+
+		cfg.TurnTimeout = time.Second
+		assert.ErrorContains(t, cfg.Validate(), "turnTimeout")
+	})
+
+	t.Run("a token secret must be long enough to guard sign-ins", func(t *testing.T) {
+		cfg := Default()
+		cfg.AuthSecret = "hunter2"
+		assert.ErrorContains(t, cfg.Validate(), "authSecret")
+
+		cfg.AuthSecret = strings.Repeat("s", 32)
+		assert.NoError(t, cfg.Validate())
+	})
+}
+
+func TestLogger(t *testing.T) {
+	t.Run("json at the configured level", func(t *testing.T) {
+		cfg, err := Load([]string{"-log-format", "json"}, env(map[string]string{"LOG_LEVEL": "warn"}))
+		require.NoError(t, err)
+
+		var out bytes.Buffer
+		logger := cfg.Logger(&out)
+		logger.Info("hidden")
+		logger.Warn("shown", "room", "ABCD")
+
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+		assert.Equal(t, "shown", record["msg"])
+		assert.Equal(t, "ABCD", record["room"])
+	})
+
+	t.Run("text by default", func(t *testing.T) {
+		var out bytes.Buffer
+		Default().Logger(&out).Debug("hidden")
+		Default().Logger(&out).Info("shown", "room", "ABCD")
+
+		assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+		assert.Contains(t, out.String(), "msg=shown room=ABCD")
+	})
+}
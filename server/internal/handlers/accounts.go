@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/accounts"
+)
+
+// maxAccountRequestBytes bounds the body of a register or login request
+const maxAccountRequestBytes = 4 << 10
+
+// accountRequest is the body of a register or login request
+type accountRequest struct {
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	DisplayName string `json:"displayName"`
+}
+
+// HandleRegister creates an account from a JSON body and answers with a session token
+// Registering and signing in happen over HTTP rather than the WebSocket because password
+// hashing is slow on purpose and must not hold up the tables.
+func (h *RoomHandler) HandleRegister(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.readAccountRequest(w, r)
+	if !ok {
+		return
+	}
+	account, err := h.accounts.Register(req.Username, req.Password, req.DisplayName)
+	switch {
+	case errors.Is(err, accounts.ErrUsernameTaken):
+		writeJSONError(w, http.StatusConflict, err)
+		return
+	case errors.Is(err, accounts.ErrInvalidUsername), errors.Is(err, accounts.ErrInvalidPassword), errors.Is(err, accounts.ErrInvalidDisplayName):
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	case err != nil:
+		h.log.Error("account could not be registered", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, errors.New("account could not be registered"))
+		return
+	}
+	h.log.Info("account registered", "account", account.ID)
+	h.writeSession(w, http.StatusCreated, account)
+}
+
+// HandleLogin checks a username and password from a JSON body and answers with a session token
+func (h *RoomHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.readAccountRequest(w, r)
+	if !ok {
+		return
+	}
+	account, err := h.accounts.Login(req.Username, req.Password)
+	if errors.Is(err, accounts.ErrWrongPassword) {
+		writeJSONError(w, http.StatusUnauthorized, err)
+		return
+	}
+	if err != nil {
+		h.log.Error("login failed", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, errors.New("login failed"))
+		return
+	}
+	h.writeSession(w, http.StatusOK, account)
+}
+
+// readAccountRequest applies the CORS rules and decodes the request body, answering the request itself when it cannot go on
+func (h *RoomHandler) readAccountRequest(w http.ResponseWriter, r *http.Request) (accountRequest, bool) {
+	var req accountRequest
+	if !h.allowCORS(w, r) {
+		return req, false
+	}
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("Access-Control-Allow-Methods", "POST")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.WriteHeader(http.StatusNoContent)
+		return req, false
+	case http.MethodPost:
+	default:
+		w.Header().Set("Allow", "POST")
+		writeJSONError(w, http.StatusMethodNotAllowed, errors.New("use POST"))
+		return req, false
+	}
+	if !h.allowLogin(remoteIP(r), time.Now()) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(loginWindow.Seconds())))
+		writeJSONError(w, http.StatusTooManyRequests, errors.New("too many attempts, try again in a minute"))
+		return req, false
+	}
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAccountRequestBytes)).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, errors.New("body must be a JSON object with a username and password"))
+		return req, false
+	}
+	return req, true
+}
+
+// writeSession answers with a new session token and the account it is for
+func (h *RoomHandler) writeSession(w http.ResponseWriter, status int, account *accounts.Account) {
+	token, err := h.accounts.IssueToken(account)
+	if err != nil {
+		h.log.Error("session token could not be issued", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, errors.New("session token could not be issued"))
+		return
+	}
+	writeJSON(w, status, map[string]interface{}{
+		"token":   token,
+		"account": account.Public(),
+	})
+}
+
+// allowCORS lets the allowed origins read the answer, refusing requests from any other origin
+func (h *RoomHandler) allowCORS(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if !h.upgrader.CheckOrigin(r) {
+		writeJSONError(w, http.StatusForbidden, errors.New("origin not allowed"))
+		return false
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Set("Vary", "Origin")
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]interface{}{"error": err.Error()})
+}
+
+// requestToken returns the session token sent with a request, from an Authorization: Bearer
+// header or, since browsers cannot set headers on WebSockets, a token query parameter
+func requestToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return r.URL.Query().Get("token")
+}
+
+// handleAuthenticate signs an open connection in with a session token
+// Players usually send the token on the handshake; this covers signing in after connecting.
+func (h *RoomHandler) handleAuthenticate(sess Session, msg map[string]interface{}) {
+	token, ok := msg["token"].(string)
+	if !ok || token == "" {
+		h.sendError(sess, ErrCodeBadRequest, "Session token is required")
+		return
+	}
+	if _, seated := h.connInfo[sess]; seated {
+		h.sendError(sess, ErrCodeWrongPhase, "Cannot sign in while seated at a table")
+		return
+	}
+	account, err := h.accounts.Authenticate(token)
+	if err != nil {
+		h.sendErr(sess, err)
+		return
+	}
+	h.signedIn[sess] = account
+	h.sessionLog(sess).Info("signed in", "account", account.ID)
+	sess.Send(map[string]interface{}{
+		"type":    TypeAuthenticated,
+		"account": account.Public(),
+	})
+}
+
+// seatFor returns the player a session sits down as: its account when signed in, otherwise a guest
+// Signed-in players always use their display name, so the name they send only matters for guests.
+func (h *RoomHandler) seatFor(sess Session, playerName string) *engine.Player {
+	if account, ok := h.signedIn[sess]; ok {
+		return &engine.Player{ID: account.ID, Name: account.DisplayName, Registered: true}
+	}
+	return &engine.Player{ID: uuid.New().String(), Name: playerName}
+}
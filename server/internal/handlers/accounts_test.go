@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thben/clearthedeck/internal/accounts"
+)
+
+// postJSON sends a JSON body to an account endpoint and decodes the answer
+func postJSON(t *testing.T, handler http.HandlerFunc, body string) (int, map[string]interface{}) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/api/account", strings.NewReader(body)))
+	var answer map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &answer))
+	return rec.Code, answer
+}
+
+// signIn registers an account and returns it with a session token
+func signIn(t *testing.T, h *RoomHandler, username, displayName string) (*accounts.Account, string) {
+	t.Helper()
+	account, err := h.accounts.Register(username, "password1", displayName)
+	require.NoError(t, err)
+	token, err := h.accounts.IssueToken(account)
+	require.NoError(t, err)
+	return account, token
+}
+
+func TestAccountEndpoints(t *testing.T) {
+	h := NewRoomHandler()
+
+	status, answer := postJSON(t, h.HandleRegister, `{"username":"alice","password":"wonderland","displayName":"Alice"}`)
+	require.Equal(t, http.StatusCreated, status)
+	token, _ := answer["token"].(string)
+	require.NotEmpty(t, token)
+	account := answer["account"].(map[string]interface{})
+	assert.Equal(t, "Alice", account["displayName"])
+	assert.NotContains(t, account, "passwordHash")
+
+	t.Run("login returns a token for the same player", func(t *testing.T) {
+		status, answer := postJSON(t, h.HandleLogin, `{"username":"Alice","password":"wonderland"}`)
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, account["id"], answer["account"].(map[string]interface{})["id"])
+	})
+
+	t.Run("wrong passwords and taken usernames are refused", func(t *testing.T) {
+		status, _ := postJSON(t, h.HandleLogin, `{"username":"alice","password":"looking-glass"}`)
+		assert.Equal(t, http.StatusUnauthorized, status)
+
+		status, _ = postJSON(t, h.HandleRegister, `{"username":"ALICE","password":"looking-glass"}`)
+		assert.Equal(t, http.StatusConflict, status)
+
+		status, answer := postJSON(t, h.HandleRegister, `{"username":"bob","password":"short"}`)
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Contains(t, answer["error"], "password")
+	})
+
+	t.Run("only POST is accepted", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.HandleLogin(rec, httptest.NewRequest(http.MethodGet, "/api/login", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
+
+func TestLoginLimit(t *testing.T) {
+	h := NewRoomHandlerWithOptions(Options{MaxLoginsPerIP: 2})
+
+	for i := 0; i < 2; i++ {
+		status, _ := postJSON(t, h.HandleLogin, `{"username":"alice","password":"wonderland"}`)
+		assert.Equal(t, http.StatusUnauthorized, status)
+	}
+	status, answer := postJSON(t, h.HandleRegister, `{"username":"alice","password":"wonderland"}`)
+	assert.Equal(t, http.StatusTooManyRequests, status)
+	assert.Contains(t, answer["error"], "too many attempts")
+
+	// Other addresses have their own allowance, and an address gets a new one each minute
+	assert.True(t, h.allowLogin("198.51.100.7", time.Now()))
+	h.mu.Lock()
+	h.sweep(DefaultLifecycleConfig(), time.Now().Add(loginWindow))
+	h.mu.Unlock()
+	status, _ = postJSON(t, h.HandleRegister, `{"username":"alice","password":"wonderland"}`)
+	assert.Equal(t, http.StatusCreated, status)
+}
+
+func TestWebSocketSignIn(t *testing.T) {
+	h := NewRoomHandler()
+	_, token := signIn(t, h, "alice", "Alice")
+	server := httptest.NewServer(http.HandlerFunc(h.HandleWebSocket))
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	t.Run("a token on the handshake signs the connection in", func(t *testing.T) {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?token="+token, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var welcome map[string]interface{}
+		require.NoError(t, conn.ReadJSON(&welcome))
+		require.Contains(t, welcome, "account")
+		assert.Equal(t, "Alice", welcome["account"].(map[string]interface{})["displayName"])
+	})
+
+	t.Run("a bearer header works too", func(t *testing.T) {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Authorization": {"Bearer " + token}})
+		require.NoError(t, err)
+		conn.Close()
+	})
+
+	t.Run("a bad token is refused before upgrading", func(t *testing.T) {
+		_, resp, err := websocket.DefaultDialer.Dial(wsURL+"?token=forged", nil)
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestAccountSeats(t *testing.T) {
+	t.Run("signed-in players keep their ID and name from table to table", func(t *testing.T) {
+		h := NewRoomHandler()
+		account, token := signIn(t, h, "alice", "Alice")
+
+		sess := NewMemorySession("alice")
+		h.Connect(sess)
+		deliver(t, h, sess, map[string]interface{}{"type": TypeAuthenticate, "token": token})
+		require.NotNil(t, sess.Last(TypeAuthenticated))
+
+		deliver(t, h, sess, map[string]interface{}{"type": TypeCreateRoom, "playerName": "Someone else"})
+		created := sess.Last(TypeRoomCreated)
+		require.NotNil(t, created)
+		assert.Equal(t, account.ID, created["playerId"])
+		players := created["room"].(map[string]interface{})["players"].([]interface{})
+		seat := players[0].(map[string]interface{})
+		assert.Equal(t, "Alice", seat["name"])
+		assert.Equal(t, true, seat["registered"])
+
+		// A second table at once is refused
+		other := NewMemorySession("alice-tab")
+		h.ConnectAs(other, account)
+		deliver(t, h, other, map[string]interface{}{"type": TypeCreateRoom})
+		assert.Contains(t, other.Last(TypeError)["message"], "another table")
+
+		deliver(t, h, sess, map[string]interface{}{"type": TypeLeaveRoom, "roomCode": created["roomCode"], "playerId": account.ID})
+		deliver(t, h, other, map[string]interface{}{"type": TypeCreateRoom})
+		again := other.Last(TypeRoomCreated)
+		require.NotNil(t, again)
+		assert.Equal(t, account.ID, again["playerId"])
+	})
+
+	t.Run("guests still play without an account", func(t *testing.T) {
+		h := NewRoomHandler()
+		_, _, roomCode := openRoom(t, h, false)
+		for _, p := range h.roomService.GetRoom(roomCode).GetPlayersInOrder() {
+			assert.False(t, p.Registered)
+		}
+	})
+
+	t.Run("a bad token is refused", func(t *testing.T) {
+		h := NewRoomHandler()
+		sess := NewMemorySession("mallory")
+		h.Connect(sess)
+		deliver(t, h, sess, map[string]interface{}{"type": TypeAuthenticate, "token": "forged"})
+		rejected := sess.Last(TypeError)
+		require.NotNil(t, rejected)
+		assert.Equal(t, ErrCodeUnauthorized, rejected["code"])
+	})
+
+	t.Run("only the account can take back its held seat", func(t *testing.T) {
+		h := NewRoomHandler()
+		host := NewMemorySession("host")
+		h.Connect(host)
+		deliver(t, h, host, map[string]interface{}{"type": TypeCreateRoom, "playerName": "Host"})
+		roomCode := host.Last(TypeRoomCreated)["roomCode"].(string)
+
+		account, _ := signIn(t, h, "bob", "Bob")
+		bob := NewMemorySession("bob")
+		h.ConnectAs(bob, account)
+		deliver(t, h, bob, map[string]interface{}{"type": TypeJoinRoom, "roomCode": roomCode})
+		require.NotNil(t, bob.Last(TypeRoomJoined))
+		deliver(t, h, host, map[string]interface{}{"type": TypeStartGame, "roomCode": roomCode, "playerId": host.Last(TypeRoomCreated)["playerId"]})
+		require.NotNil(t, bob.Last(TypeGameStarted))
+		h.Disconnect(bob)
+
+		guest := NewMemorySession("impostor")
+		h.Connect(guest)
+		deliver(t, h, guest, map[string]interface{}{"type": TypeRejoinRoom, "roomCode": roomCode, "playerId": account.ID})
+		rejected := guest.Last(TypeError)
+		require.NotNil(t, rejected)
+		assert.Equal(t, ErrCodeUnauthorized, rejected["code"])
+
+		back := NewMemorySession("bob-again")
+		h.ConnectAs(back, account)
+		deliver(t, h, back, map[string]interface{}{"type": TypeRejoinRoom, "roomCode": roomCode, "playerId": account.ID})
+		assert.Nil(t, back.Last(TypeError))
+		assert.NotNil(t, back.Last(TypeRoomJoined))
+	})
+}
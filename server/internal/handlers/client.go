@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// Time allowed to write a message to the peer
+	writeWait = 10 * time.Second
+	// Time allowed to read the next message or pong from the peer
+	pongWait = 60 * time.Second
+	// Pings are sent often enough that a live peer always answers within pongWait
+	pingPeriod = pongWait * 9 / 10
+	// Largest message accepted from a peer
+	maxMessageSize = 64 * 1024
+	// Outbound messages queued per connection before the peer is considered too slow
+	sendBufferSize = 256
+)
+
+// client is the Session for one WebSocket connection
+// It owns the writing side of the connection
+// gorilla/websocket allows a single concurrent writer, so every outbound message goes through
+// the send queue and is written by the client's own writePump goroutine
+type client struct {
+	id   string
+	conn *websocket.Conn
+	send chan []byte
+	done chan struct{}
+	once sync.Once
+	log  *slog.Logger // Tagged with the session ID
+}
+
+func newClient(conn *websocket.Conn, logger *slog.Logger) *client {
+	id := uuid.New().String()
+	return &client{
+		id:   id,
+		conn: conn,
+		send: make(chan []byte, sendBufferSize),
+		done: make(chan struct{}),
+		log:  logger.With("session", id),
+	}
+}
+
+// enqueue queues a message for the peer without blocking
+// A peer whose queue is full is dropped rather than holding up the rest of the room
+func (c *client) enqueue(msg interface{}) bool {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		c.log.Error("failed to encode message", "error", err)
+		return false
+	}
+
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+
+	select {
+	case c.send <- data:
+		return true
+	default:
+		c.log.Warn("dropping slow client")
+		c.close()
+		return false
+	}
+}
+
+// close stops the write pump and closes the connection, which also ends the read loop
+func (c *client) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// closeWith sends a close frame with the code and reason before closing
+// Control frames may be written alongside the write pump, so this does not need to go through the queue
+func (c *client) closeWith(code int, reason string) {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(writeWait))
+		c.conn.Close()
+	})
+}
+
+// configureReads applies the read limit and keeps the read deadline moving while pongs arrive
+func (c *client) configureReads() {
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+}
+
+// writePump writes queued messages and keepalive pings until the client is closed or a write fails
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.close()
+	}()
+
+	for {
+		select {
+		case data := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				c.log.Debug("write failed", "error", err)
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				c.log.Debug("ping failed", "error", err)
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// ID returns the connection's session ID
+func (c *client) ID() string {
+	return c.id
+}
+
+// Send queues a message for the peer
+func (c *client) Send(msg interface{}) bool {
+	return c.enqueue(msg)
+}
+
+// Close closes the connection
+func (c *client) Close() {
+	c.close()
+}
+
+// CloseWith closes the connection with a close code
+func (c *client) CloseWith(code int, reason string) {
+	c.closeWith(code, reason)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialClient returns a server-side client and the peer connected to it
+func dialClient(t *testing.T) (*client, *websocket.Conn) {
+	serverConns := make(chan *websocket.Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := newUpgrader(nil)
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		serverConns <- conn
+	}))
+	t.Cleanup(server.Close)
+
+	peer, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { peer.Close() })
+
+	c := newClient(<-serverConns, slog.Default())
+	t.Cleanup(c.close)
+	return c, peer
+}
+
+func TestClient(t *testing.T) {
+	t.Run("queued messages are written in order", func(t *testing.T) {
+		c, peer := dialClient(t)
+		go c.writePump()
+
+		for i := 0; i < 3; i++ {
+			require.True(t, c.enqueue(map[string]interface{}{"type": "TEST", "n": i}))
+		}
+		for i := 0; i < 3; i++ {
+			var msg map[string]interface{}
+			require.NoError(t, peer.SetReadDeadline(time.Now().Add(time.Second)))
+			require.NoError(t, peer.ReadJSON(&msg))
+			assert.Equal(t, float64(i), msg["n"])
+		}
+	})
+
+	t.Run("slow client is dropped instead of blocking", func(t *testing.T) {
+		c, _ := dialClient(t)
+		// No write pump is running, so nothing drains the queue
+		for i := 0; i < sendBufferSize; i++ {
+			require.True(t, c.enqueue(map[string]interface{}{"type": "TEST"}))
+		}
+		assert.False(t, c.enqueue(map[string]interface{}{"type": "TEST"}))
+
+		select {
+		case <-c.done:
+		default:
+			t.Fatal("expected the client to be closed")
+		}
+		assert.False(t, c.enqueue(map[string]interface{}{"type": "TEST"}))
+	})
+
+	t.Run("oversized messages close the connection", func(t *testing.T) {
+		handler := NewRoomHandler()
+		server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+		defer server.Close()
+
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		_, _, _ = conn.ReadMessage() // welcome
+
+		huge := strings.Repeat("x", maxMessageSize+1)
+		require.NoError(t, conn.WriteJSON(map[string]interface{}{"type": "CREATE_ROOM", "playerName": huge}))
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		_, _, err = conn.ReadMessage()
+		assert.Error(t, err)
+	})
+}
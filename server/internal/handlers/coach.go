@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/bot"
+)
+
+// handleRequestHint sends the player the coach's suggestion for their turn
+func (h *RoomHandler) handleRequestHint(sess Session, msg map[string]interface{}) {
+	connInfo, ok := h.connInfo[sess]
+	if !ok {
+		h.sendError(sess, ErrCodeNotFound, "Connection not registered")
+		return
+	}
+
+	room := h.roomService.GetRoom(connInfo.RoomCode)
+	if room == nil {
+		h.sendError(sess, ErrCodeNotFound, "Room not found")
+		return
+	}
+	if !room.GetSettings().Coach {
+		h.sendError(sess, ErrCodeForbidden, "The coach is off at this table")
+		return
+	}
+
+	game, ok := h.games[connInfo.RoomCode]
+	if !ok || game == nil {
+		h.sendError(sess, ErrCodeWrongPhase, "Game not started")
+		return
+	}
+	if current := game.GetCurrentPlayer(); current == nil || current.ID != connInfo.PlayerID {
+		h.sendError(sess, ErrCodeNotYourTurn, "not your turn")
+		return
+	}
+
+	// The search runs on a copy without the lock; the hint is only sent if the turn is still theirs
+	snapshot := game.Clone()
+	roomCode := connInfo.RoomCode
+	key := turnKey{round: game.Round, actions: game.Progress.Actions, playerID: connInfo.PlayerID}
+	go func() {
+		suggestion, err := h.coach.Suggest(snapshot, key.playerID)
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if err != nil {
+			h.sendErr(sess, err)
+			return
+		}
+		current := game.GetCurrentPlayer()
+		if h.games[roomCode] != game || current == nil || (turnKey{game.Round, game.Progress.Actions, current.ID}) != key {
+			h.sendError(sess, ErrCodeNotYourTurn, "The turn moved on before the hint was ready")
+			return
+		}
+		sess.Send(map[string]interface{}{
+			"type":       TypeHint,
+			"suggestion": suggestion,
+		})
+	}()
+}
+
+// coachRound gathers the coach's reviews of one round, which are searched in the background
+type coachRound struct {
+	roomCode string
+	round    int
+	pending  map[*coachReview]bool // Reviews still searching
+	mistakes map[string][]bot.Mistake
+	ended    bool // The round is over; the reviews go out once none are pending
+}
+
+// coachReview is a move waiting on the coach's verdict
+type coachReview struct {
+	playerID string
+	move     int
+}
+
+// coachSnapshot copies the game before a move the coach should review; nil when the coach is off or the move is a bot's
+func (h *RoomHandler) coachSnapshot(roomCode string, game *engine.Game, action engine.Action) *engine.Game {
+	if room := h.roomService.GetRoom(roomCode); room == nil || !room.GetSettings().Coach {
+		return nil
+	}
+	player := game.GetCurrentPlayer()
+	if player == nil || player.ID != action.PlayerID || player.IsBot {
+		return nil
+	}
+	return game.Clone()
+}
+
+// reviewMove has the coach review a move that was made from before, outside the lock
+// The mistake, if any, is kept for the player's review at the end of the round.
+func (h *RoomHandler) reviewMove(roomCode string, before *engine.Game, action engine.Action) {
+	if before == nil {
+		return
+	}
+	notes := h.coachNotes[roomCode]
+	if notes == nil || notes.round != before.Round {
+		notes = &coachRound{
+			roomCode: roomCode,
+			round:    before.Round,
+			pending:  make(map[*coachReview]bool),
+			mistakes: make(map[string][]bot.Mistake),
+		}
+		h.coachNotes[roomCode] = notes
+	}
+	review := &coachReview{playerID: action.PlayerID, move: before.Progress.Actions + 1}
+	notes.pending[review] = true
+
+	go func() {
+		mistake, err := h.coach.Review(before, action.PlayerID, action)
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if !notes.pending[review] {
+			return // The move was undone or the game was replaced
+		}
+		delete(notes.pending, review)
+		if err != nil {
+			h.roomLog(roomCode).Warn("coach could not review move", "player", action.PlayerID, "error", err)
+		} else if mistake != nil {
+			notes.mistakes[action.PlayerID] = append(notes.mistakes[action.PlayerID], *mistake)
+		}
+		h.deliverCoachReviews(notes)
+	}()
+}
+
+// forgetMistakesAfter drops mistakes, and reviews still searching, from moves that were undone
+func (h *RoomHandler) forgetMistakesAfter(roomCode string, game *engine.Game) {
+	notes := h.coachNotes[roomCode]
+	if notes == nil || notes.round != game.Round {
+		return
+	}
+	for playerID, mistakes := range notes.mistakes {
+		kept := mistakes[:0]
+		for _, m := range mistakes {
+			if m.Move <= game.Progress.Actions {
+				kept = append(kept, m)
+			}
+		}
+		notes.mistakes[playerID] = kept
+	}
+	for review := range notes.pending {
+		if review.move > game.Progress.Actions {
+			delete(notes.pending, review)
+		}
+	}
+}
+
+// sendCoachReviews ends the round's reviews; each player at a coached table gets theirs once every review is back
+func (h *RoomHandler) sendCoachReviews(roomCode string, game *engine.Game) {
+	notes := h.coachNotes[roomCode]
+	delete(h.coachNotes, roomCode)
+	if room := h.roomService.GetRoom(roomCode); room == nil || !room.GetSettings().Coach {
+		return
+	}
+	if notes == nil || notes.round != game.Round {
+		notes = &coachRound{roomCode: roomCode, round: game.Round}
+	}
+	notes.ended = true
+	h.deliverCoachReviews(notes)
+}
+
+// deliverCoachReviews sends each player in the room their mistakes once the round is over and reviewed
+func (h *RoomHandler) deliverCoachReviews(notes *coachRound) {
+	if !notes.ended || len(notes.pending) > 0 || h.closing {
+		return
+	}
+	for sess := range h.roomConnections[notes.roomCode] {
+		info, ok := h.connInfo[sess]
+		if !ok {
+			continue
+		}
+		mistakes := notes.mistakes[info.PlayerID]
+		if mistakes == nil {
+			mistakes = []bot.Mistake{}
+		}
+		sess.Send(map[string]interface{}{
+			"type":     TypeCoachReview,
+			"round":    notes.round,
+			"mistakes": mistakes,
+		})
+	}
+}
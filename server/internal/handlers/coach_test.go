@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/bot"
+)
+
+const coachLesson = `
+current: Alice
+pile: [9H]
+players:
+  - {name: Alice, hand: [7H]}
+  - {name: Bob, hand: [3C]}
+`
+
+func TestCoach(t *testing.T) {
+	t.Run("Hints need the coach", func(t *testing.T) {
+		h := NewRoomHandler()
+		host, _, _ := openRoom(t, h, true)
+		deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": coachLesson})
+
+		deliver(t, h, host, map[string]interface{}{"type": TypeRequestHint})
+		rejected := host.Last(TypeError)
+		require.NotNil(t, rejected)
+		assert.Equal(t, ErrCodeForbidden, rejected["code"])
+	})
+
+	t.Run("Players get hints on their turn", func(t *testing.T) {
+		h := NewRoomHandler()
+		h.coach = bot.NewCoachWithSearch(bot.NewMCTS(bot.Budget{Iterations: 100}, 1))
+		host, guest, _ := openRoom(t, h, true)
+		deliver(t, h, host, map[string]interface{}{"type": TypeUpdateSettings, "coach": true})
+		deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": coachLesson})
+
+		deliver(t, h, guest, map[string]interface{}{"type": TypeRequestHint})
+		assert.Equal(t, ErrCodeNotYourTurn, guest.Last(TypeError)["code"])
+
+		deliver(t, h, host, map[string]interface{}{"type": TypeRequestHint})
+		hint := awaitMessage(t, host, TypeHint)
+		assert.Equal(t, "play 7", hint["suggestion"].(map[string]interface{})["description"])
+		assert.Nil(t, guest.Last(TypeHint))
+	})
+
+	t.Run("Each player gets a review when the round ends", func(t *testing.T) {
+		h := NewRoomHandler()
+		h.coach = bot.NewCoachWithSearch(bot.NewMCTS(bot.Budget{Iterations: 100}, 1))
+		host, guest, roomCode := openRoom(t, h, true)
+		deliver(t, h, host, map[string]interface{}{"type": TypeUpdateSettings, "coach": true})
+		deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": coachLesson})
+		game := h.games[roomCode]
+		bob := game.Players[1]
+
+		// Alice picks up instead of going out, then Bob goes out
+		deliver(t, h, host, map[string]interface{}{"type": TypePickupPile})
+		deliver(t, h, host, map[string]interface{}{"type": TypePlayCards, "cardIds": []string{"pile-0"}})
+		deliver(t, h, guest, map[string]interface{}{"type": TypePlayCards, "cardIds": []string{bob.ID + "-hand-0"}})
+		require.True(t, game.RoundOver())
+
+		review := awaitMessage(t, host, TypeCoachReview)
+		mistakes := review["mistakes"].([]interface{})
+		require.NotEmpty(t, mistakes)
+		first := mistakes[0].(map[string]interface{})
+		assert.Equal(t, "pick up the pile", first["played"])
+		assert.Equal(t, "play 7", first["suggested"])
+
+		theirs := awaitMessage(t, guest, TypeCoachReview)
+		assert.Empty(t, theirs["mistakes"])
+	})
+
+	t.Run("Reviews of undone moves are dropped", func(t *testing.T) {
+		h := NewRoomHandler()
+		h.coach = bot.NewCoachWithSearch(bot.NewMCTS(bot.Budget{Iterations: 100}, 1))
+		host, _, roomCode := openRoom(t, h, true)
+		deliver(t, h, host, map[string]interface{}{"type": TypeUpdateSettings, "coach": true})
+		deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": coachLesson})
+		game := h.games[roomCode]
+		alice := game.Players[0]
+
+		// Alice's pickup is sent for review, then taken back before the search is done
+		h.mu.Lock()
+		pickup := engine.Action{Type: engine.ActionPickup, PlayerID: alice.ID}
+		h.reviewMove(roomCode, h.coachSnapshot(roomCode, game, pickup), pickup)
+		h.forgetMistakesAfter(roomCode, game)
+		h.mu.Unlock()
+
+		deliver(t, h, host, map[string]interface{}{"type": TypePlayCards, "cardIds": []string{alice.ID + "-hand-0"}})
+		review := awaitMessage(t, host, TypeCoachReview)
+		assert.Empty(t, review["mistakes"])
+	})
+
+	t.Run("Hints for a turn that has passed are not sent", func(t *testing.T) {
+		h := NewRoomHandler()
+		h.coach = bot.NewCoachWithSearch(bot.NewMCTS(bot.Budget{Iterations: 100}, 1))
+		host, _, roomCode := openRoom(t, h, true)
+		deliver(t, h, host, map[string]interface{}{"type": TypeUpdateSettings, "coach": true})
+		deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": coachLesson})
+		game := h.games[roomCode]
+
+		// Alice asks, then her turn is over before the coach answers
+		h.mu.Lock()
+		h.handleRequestHint(host, map[string]interface{}{"type": TypeRequestHint})
+		game.NextPlayer()
+		h.mu.Unlock()
+
+		rejected := awaitMessage(t, host, TypeError)
+		assert.Equal(t, ErrCodeNotYourTurn, rejected["code"])
+		assert.Nil(t, host.Last(TypeHint))
+	})
+}
+
+// awaitMessage waits for a message the handler sends once a background search is done
+func awaitMessage(t *testing.T, sess *MemorySession, msgType string) map[string]interface{} {
+	var msg map[string]interface{}
+	require.Eventually(t, func() bool {
+		msg = sess.Last(msgType)
+		return msg != nil
+	}, 5*time.Second, 5*time.Millisecond, "no %s", msgType)
+	return msg
+}
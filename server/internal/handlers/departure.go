@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/bot"
+	"github.com/thben/clearthedeck/internal/models"
+	"github.com/thben/clearthedeck/internal/services"
+)
+
+// maxBotMoves bounds how many consecutive bot turns are played in one go
+const maxBotMoves = 500
+
+// departPlayer drops a player's connection and applies the room's departure policy if a game is running
+func (h *RoomHandler) departPlayer(sess Session, room *models.Room, player *engine.Player) {
+	h.removeConnection(sess, room.Code)
+
+	game := h.games[room.Code]
+	if game != nil && game.IsStarted && !game.IsFinished && game.PlayerIndex(player.ID) >= 0 {
+		h.departMidGame(room, game, player, room.GetSettings().DeparturePolicy)
+		return
+	}
+
+	if err := h.roomService.LeaveRoom(room.Code, player.ID); err != nil {
+		h.sendErr(sess, err)
+		return
+	}
+	if h.roomService.GetRoom(room.Code) == nil {
+		// The last player left, so nothing of the room should outlive it
+		h.closeRoom(room.Code)
+		return
+	}
+	h.roomLog(room.Code).Info("player left", "player", player.ID)
+	h.broadcastPlayerLeft(room, player, "")
+}
+
+// departMidGame applies a departure policy to a player who left a running game
+// Room membership, game seats, turn order and dealer position are updated together
+func (h *RoomHandler) departMidGame(room *models.Room, game *engine.Game, player *engine.Player, policy models.DeparturePolicy) {
+	roomCode := room.Code
+	h.clearUndo(roomCode, undoSuperseded)
+
+	// The player has already gone, so failures can only be logged
+	log := h.roomLog(roomCode).With("player", player.ID)
+	switch policy {
+	case models.DepartureBot:
+		if err := services.ReplaceWithBot(game, player.ID); err != nil {
+			log.Error("could not hand seat to a bot", "error", err)
+		}
+	case models.DepartureRedistribute, models.DepartureForfeit:
+		h.cancelSeatHold(player.ID)
+		if err := services.RemovePlayer(game, player.ID, policy); err != nil {
+			log.Error("could not remove player from the game", "error", err)
+		}
+		if err := h.roomService.LeaveRoom(roomCode, player.ID); err != nil {
+			log.Error("could not remove player from the room", "error", err)
+		}
+	default:
+		policy = models.DepartureHoldSeat
+		hold := room.GetSettings().SeatHoldDuration
+		if err := services.HoldSeat(game, player.ID, time.Now().Add(hold)); err != nil {
+			log.Error("could not hold seat", "error", err)
+		}
+		h.startSeatHold(roomCode, player.ID, hold)
+	}
+
+	// Hosting passes to someone still sitting at the table
+	if host, ok := room.GetPlayer(room.GetHostID()); !ok || !host.IsPresent() {
+		if next := room.NextHost(); next != "" {
+			room.SetHostID(next)
+		}
+	}
+
+	if room.GetPresentCount() == 0 && !room.HasHeldSeats() {
+		h.closeRoom(roomCode)
+		return
+	}
+
+	h.roomLog(roomCode).Info("player left mid-game", "player", player.ID, "policy", policy)
+	h.broadcastPlayerLeft(room, player, policy)
+
+	if winner := services.LastPlayerStanding(game); winner != nil {
+		engine.EndRound(game, winner.ID)
+		game.Finish()
+		h.recordRoundEnd(roomCode, game, engine.RoundEndWon, winner.ID)
+		h.recordMatchRound(roomCode, game)
+		h.broadcastRoundEnd(roomCode, game, winner)
+		h.finishGame(roomCode, game)
+		return
+	}
+
+	h.broadcastGameState(roomCode, game)
+	h.playBotTurns(roomCode, game)
+}
+
+// startSeatHold schedules a held seat to be forfeited if the player does not come back in time
+func (h *RoomHandler) startSeatHold(roomCode, playerID string, hold time.Duration) {
+	h.cancelSeatHold(playerID)
+	h.seatTimers[playerID] = time.AfterFunc(hold, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.expireSeatHold(roomCode, playerID)
+	})
+}
+
+// cancelSeatHold stops a pending seat-hold timer
+func (h *RoomHandler) cancelSeatHold(playerID string) {
+	if timer, ok := h.seatTimers[playerID]; ok {
+		timer.Stop()
+		delete(h.seatTimers, playerID)
+	}
+}
+
+// expireSeatHold forfeits a held seat whose player never came back
+func (h *RoomHandler) expireSeatHold(roomCode, playerID string) {
+	delete(h.seatTimers, playerID)
+
+	room := h.roomService.GetRoom(roomCode)
+	game := h.games[roomCode]
+	if room == nil || game == nil {
+		return
+	}
+	player, ok := room.GetPlayer(playerID)
+	if !ok || !player.Away {
+		return
+	}
+
+	h.departMidGame(room, game, player, models.DepartureForfeit)
+}
+
+// closeRoom removes a room along with its game, timers and connections
+func (h *RoomHandler) closeRoom(roomCode string) {
+	if room := h.roomService.GetRoom(roomCode); room != nil {
+		for _, p := range room.GetPlayersInOrder() {
+			h.cancelSeatHold(p.ID)
+		}
+	}
+	if entry, ok := h.undo[roomCode]; ok && entry.timer != nil {
+		entry.timer.Stop()
+	}
+	delete(h.undo, roomCode)
+	h.stopTurnTimer(roomCode)
+	h.endMatch(roomCode)
+	h.roomService.DeleteRoom(roomCode)
+	delete(h.games, roomCode)
+	h.roomLog(roomCode).Info("room closed")
+	delete(h.lastActivity, roomCode)
+	delete(h.roundStarted, roomCode)
+	delete(h.coachNotes, roomCode)
+	delete(h.scenarioBots, roomCode)
+	h.forgetConnections(roomCode)
+}
+
+// removeConnection detaches a connection from its room
+func (h *RoomHandler) removeConnection(sess Session, roomCode string) {
+	if connections, exists := h.roomConnections[roomCode]; exists {
+		delete(connections, sess)
+		if len(connections) == 0 {
+			delete(h.roomConnections, roomCode)
+		}
+	}
+	delete(h.connInfo, sess)
+}
+
+// broadcastPlayerLeft tells the remaining players who left and what happened to their seat
+func (h *RoomHandler) broadcastPlayerLeft(room *models.Room, player *engine.Player, policy models.DeparturePolicy) {
+	broadcast := map[string]interface{}{
+		"type":       TypePlayerLeft,
+		"playerName": player.Name,
+		"playerId":   player.ID,
+		"room":       h.serializeRoom(room),
+	}
+	if policy != "" {
+		broadcast["departurePolicy"] = string(policy)
+	}
+	h.broadcastToRoom(room.Code, broadcast, nil)
+}
+
+// handleRejoinRoom gives a held seat back to the player who left it
+func (h *RoomHandler) handleRejoinRoom(sess Session, msg map[string]interface{}) {
+	roomCode, ok := msg["roomCode"].(string)
+	if !ok || roomCode == "" {
+		h.sendError(sess, ErrCodeBadRequest, "Room code is required")
+		return
+	}
+
+	playerID, ok := msg["playerId"].(string)
+	if !ok || playerID == "" {
+		h.sendError(sess, ErrCodeBadRequest, "Player ID is required")
+		return
+	}
+
+	room := h.roomService.GetRoom(roomCode)
+	if room == nil {
+		h.sendError(sess, ErrCodeNotFound, "Room not found")
+		return
+	}
+
+	player, ok := room.GetPlayer(playerID)
+	if !ok || !player.Away {
+		h.sendError(sess, ErrCodeNotFound, "No seat is being held for this player")
+		return
+	}
+
+	// Account seats go back only to the account; a guest's unguessable ID is its proof
+	if player.Registered {
+		if account, ok := h.signedIn[sess]; !ok || account.ID != playerID {
+			h.sendError(sess, ErrCodeUnauthorized, "Sign in as this player to take back their seat")
+			return
+		}
+	}
+
+	h.cancelSeatHold(playerID)
+	game := h.games[roomCode]
+	if game != nil {
+		if err := services.ReturnToSeat(game, playerID); err != nil {
+			h.sendErr(sess, err)
+			return
+		}
+	}
+
+	h.connInfo[sess] = &ConnectionInfo{
+		RoomCode: roomCode,
+		PlayerID: playerID,
+	}
+	if h.roomConnections[roomCode] == nil {
+		h.roomConnections[roomCode] = make(map[Session]bool)
+	}
+	h.roomConnections[roomCode][sess] = true
+	h.sessionLog(sess).Info("player rejoined")
+
+	response := map[string]interface{}{
+		"type":     TypeRoomJoined,
+		"playerId": playerID,
+		"room":     h.serializeRoom(room),
+	}
+	sess.Send(response)
+
+	broadcast := map[string]interface{}{
+		"type":       TypePlayerRejoined,
+		"playerName": player.Name,
+		"playerId":   playerID,
+		"room":       h.serializeRoom(room),
+	}
+	h.broadcastToRoom(roomCode, broadcast, sess)
+
+	if game != nil {
+		h.broadcastGameState(roomCode, game)
+	}
+}
+
+// botFor returns the strategy bots use at the room's chosen level
+func (h *RoomHandler) botFor(roomCode string) bot.Strategy {
+	room := h.roomService.GetRoom(roomCode)
+	if room == nil {
+		return h.botStrategy
+	}
+	switch level := room.GetSettings().BotLevel; level {
+	case models.BotEasy, models.BotMedium, models.BotHard:
+		return bot.ForLevel(level)
+	}
+	return h.botStrategy
+}
+
+// playBotTurns lets bots take their turns until a human is up or the round ends
+// Strategies that search think about their move in the background, so the table is not locked
+// while they do; play carries on once the move is found.
+func (h *RoomHandler) playBotTurns(roomCode string, game *engine.Game) {
+	for moves := 0; moves < maxBotMoves; moves++ {
+		current := game.GetCurrentPlayer()
+		if current == nil || !current.IsBot || game.IsFinished || game.RoundOver() {
+			return
+		}
+
+		strategy := h.botFor(roomCode)
+		if _, searches := strategy.(*bot.MCTS); searches {
+			h.searchBotTurn(roomCode, game, strategy)
+			return
+		}
+		action, err := strategy.ChooseAction(game, current.ID)
+		if !h.playBotTurn(roomCode, game, current.ID, action, err) {
+			return
+		}
+	}
+}
+
+// playBotTurn plays the move a bot chose, reporting whether the round goes on
+func (h *RoomHandler) playBotTurn(roomCode string, game *engine.Game, playerID string, action engine.Action, err error) bool {
+	events := h.playForSeat(roomCode, game, playerID, action, err)
+	h.clearUndo(roomCode, undoSuperseded)
+	if h.finishRound(roomCode, game, events) {
+		return false
+	}
+	h.broadcastGameState(roomCode, game)
+	return true
+}
+
+// playForSeat plays a move chosen for a seat, falling back to a legal move when the choice fails
+// Picking up the pile comes first, then any other legal move; skipping the turn is not allowed
+// by the rules, so it is only done when nothing else can be played.
+func (h *RoomHandler) playForSeat(roomCode string, game *engine.Game, playerID string, action engine.Action, err error) []engine.Event {
+	if err == nil {
+		action.PlayerID = playerID
+		events, applyErr := h.applyAction(game, action)
+		if applyErr == nil {
+			return events
+		}
+		err = applyErr
+	}
+	log := h.roomLog(roomCode).With("player", playerID)
+	log.Warn("chosen move could not be played, making a legal one instead", "error", err)
+
+	var fallbacks []engine.Action
+	if len(game.CenterPile) > 0 {
+		fallbacks = append(fallbacks, engine.Action{Type: engine.ActionPickup, PlayerID: playerID})
+	}
+	fallbacks = append(fallbacks, engine.LegalActions(engine.FromGame(game), playerID)...)
+	for _, fallback := range fallbacks {
+		if events, err := h.applyAction(game, fallback); err == nil {
+			return events
+		}
+	}
+	log.Error("no legal move could be played, skipping the turn")
+	game.NextPlayer()
+	return nil
+}
+
+// searchBotTurn has the current bot search a copy of the game without holding the lock
+// The move is only played if the game is still at the turn that was searched.
+func (h *RoomHandler) searchBotTurn(roomCode string, game *engine.Game, strategy bot.Strategy) {
+	key := turnKey{round: game.Round, actions: game.Progress.Actions, playerID: game.GetCurrentPlayer().ID}
+	if h.botSearches[roomCode] == key {
+		return
+	}
+	h.botSearches[roomCode] = key
+	snapshot := game.Clone()
+
+	go func() {
+		action, err := strategy.ChooseAction(snapshot, key.playerID)
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.botSearches[roomCode] == key {
+			delete(h.botSearches, roomCode)
+		}
+		current := game.GetCurrentPlayer()
+		if h.closing || h.games[roomCode] != game || current == nil ||
+			(turnKey{game.Round, game.Progress.Actions, current.ID}) != key {
+			return
+		}
+		if h.playBotTurn(roomCode, game, key.playerID, action, err) {
+			h.playBotTurns(roomCode, game)
+		}
+	}()
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thben/clearthedeck/engine"
+)
+
+type testPlayer struct {
+	conn *websocket.Conn
+	id   string
+}
+
+// setupTable creates a room with the given number of players; the first one is the host
+func setupTable(t *testing.T, wsURL string, count int) (string, []testPlayer) {
+	players := make([]testPlayer, 0, count)
+	var roomCode string
+
+	for i := 0; i < count; i++ {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		_, _, _ = conn.ReadMessage() // welcome
+
+		if i == 0 {
+			require.NoError(t, conn.WriteJSON(map[string]interface{}{"type": "CREATE_ROOM", "playerName": "Host"}))
+			created := waitForType(t, conn, "ROOM_CREATED")
+			roomCode = created["roomCode"].(string)
+			players = append(players, testPlayer{conn: conn, id: created["playerId"].(string)})
+			continue
+		}
+
+		require.NoError(t, conn.WriteJSON(map[string]interface{}{
+			"type":       "JOIN_ROOM",
+			"roomCode":   roomCode,
+			"playerName": fmt.Sprintf("Player%d", i+1),
+		}))
+		joined := waitForType(t, conn, "ROOM_JOINED")
+		players = append(players, testPlayer{conn: conn, id: joined["playerId"].(string)})
+		for _, p := range players[:i] {
+			waitForType(t, p.conn, "PLAYER_JOINED")
+		}
+	}
+
+	return roomCode, players
+}
+
+func startTableGame(t *testing.T, roomCode string, players []testPlayer) {
+	require.NoError(t, players[0].conn.WriteJSON(map[string]interface{}{
+		"type": "START_GAME", "roomCode": roomCode, "playerId": players[0].id,
+	}))
+	for _, p := range players {
+		waitForType(t, p.conn, "GAME_STARTED")
+	}
+}
+
+func TestMidGameDeparture(t *testing.T) {
+	t.Run("redistribute removes the seat from room and game", func(t *testing.T) {
+		handler := NewRoomHandler()
+		server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+		defer server.Close()
+		wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+		roomCode, players := setupTable(t, wsURL, 4)
+		require.NoError(t, players[0].conn.WriteJSON(map[string]interface{}{
+			"type": "UPDATE_SETTINGS", "departurePolicy": "redistribute",
+		}))
+		updated := waitForType(t, players[0].conn, "SETTINGS_UPDATED")
+		settings := updated["room"].(map[string]interface{})["settings"].(map[string]interface{})
+		assert.Equal(t, "redistribute", settings["departurePolicy"])
+
+		startTableGame(t, roomCode, players)
+
+		require.NoError(t, players[3].conn.WriteJSON(map[string]interface{}{
+			"type": "LEAVE_ROOM", "roomCode": roomCode, "playerId": players[3].id,
+		}))
+
+		left := waitForType(t, players[0].conn, "PLAYER_LEFT")
+		assert.Equal(t, "redistribute", left["departurePolicy"])
+		update := waitForType(t, players[0].conn, "GAME_UPDATE")
+		gamePlayers := update["game"].(map[string]interface{})["players"].([]interface{})
+		assert.Len(t, gamePlayers, 3)
+
+		room := handler.roomService.GetRoom(roomCode)
+		require.NotNil(t, room)
+		assert.Equal(t, 3, room.GetPlayerCount())
+	})
+
+	t.Run("held seat can be reclaimed with REJOIN_ROOM", func(t *testing.T) {
+		handler := NewRoomHandler()
+		server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+		defer server.Close()
+		wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+		roomCode, players := setupTable(t, wsURL, 3)
+		startTableGame(t, roomCode, players)
+
+		players[2].conn.Close()
+		left := waitForType(t, players[0].conn, "PLAYER_LEFT")
+		assert.Equal(t, "hold", left["departurePolicy"])
+
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		_, _, _ = conn.ReadMessage() // welcome
+
+		require.NoError(t, conn.WriteJSON(map[string]interface{}{
+			"type": "REJOIN_ROOM", "roomCode": roomCode, "playerId": players[2].id,
+		}))
+		joined := waitForType(t, conn, "ROOM_JOINED")
+		assert.Equal(t, players[2].id, joined["playerId"])
+
+		rejoined := waitForType(t, players[0].conn, "PLAYER_REJOINED")
+		assert.Equal(t, players[2].id, rejoined["playerId"])
+	})
+
+	t.Run("bot takes over a departed seat", func(t *testing.T) {
+		handler := NewRoomHandler()
+		server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+		defer server.Close()
+		wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+		roomCode, players := setupTable(t, wsURL, 3)
+		require.NoError(t, players[0].conn.WriteJSON(map[string]interface{}{
+			"type": "UPDATE_SETTINGS", "departurePolicy": "bot",
+		}))
+		waitForType(t, players[0].conn, "SETTINGS_UPDATED")
+		startTableGame(t, roomCode, players)
+
+		// Player 2 sits right after the host and leaves before their turn
+		require.NoError(t, players[1].conn.WriteJSON(map[string]interface{}{
+			"type": "LEAVE_ROOM", "roomCode": roomCode, "playerId": players[1].id,
+		}))
+		waitForType(t, players[0].conn, "PLAYER_LEFT")
+
+		handler.mu.Lock()
+		game := handler.games[roomCode]
+		require.NotNil(t, game)
+		var hostCard string
+		for _, card := range game.Players[0].Hand {
+			if card.Value != "10" {
+				hostCard = card.ID
+				break
+			}
+		}
+		handler.mu.Unlock()
+		require.NotEmpty(t, hostCard)
+
+		require.NoError(t, players[0].conn.WriteJSON(map[string]interface{}{
+			"type": "PLAY_CARDS", "cardIds": []string{hostCard},
+		}))
+
+		// Host's play hands the turn to the bot, which plays until it passes to Player 3
+		var state struct {
+			CurrentPlayerIndex int `json:"currentPlayerIndex"`
+			Players            []struct {
+				IsBot bool `json:"isBot"`
+			} `json:"players"`
+		}
+		for state.CurrentPlayerIndex != 2 {
+			update := waitForType(t, players[0].conn, "GAME_UPDATE")
+			raw, err := json.Marshal(update["game"])
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(raw, &state))
+		}
+		assert.True(t, state.Players[1].IsBot)
+	})
+}
+
+func TestRoundEndSummary(t *testing.T) {
+	handler := NewRoomHandler()
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	roomCode, players := setupTable(t, wsURL, 2)
+	require.NoError(t, players[0].conn.WriteJSON(map[string]interface{}{
+		"type": "UPDATE_SETTINGS", "departurePolicy": "forfeit",
+	}))
+	waitForType(t, players[0].conn, "SETTINGS_UPDATED")
+	startTableGame(t, roomCode, players)
+
+	require.NoError(t, players[1].conn.WriteJSON(map[string]interface{}{
+		"type": "LEAVE_ROOM", "roomCode": roomCode, "playerId": players[1].id,
+	}))
+
+	roundEnd := waitForType(t, players[0].conn, "ROUND_END")
+	winner := roundEnd["winner"].(map[string]interface{})
+	assert.Equal(t, players[0].id, winner["id"])
+	assert.NotContains(t, winner, "hand")
+
+	scores := roundEnd["scores"].([]interface{})
+	require.Len(t, scores, 2)
+	first := scores[0].(map[string]interface{})
+	assert.Equal(t, players[0].id, first["id"])
+	assert.Equal(t, float64(1), first["rank"])
+	for _, key := range []string{"hand", "faceUp", "faceDown", "handPoints", "faceUpPoints", "faceDownPoints", "tensPenalty", "roundScore", "totalScore"} {
+		assert.Contains(t, first, key)
+	}
+	second := scores[1].(map[string]interface{})
+	assert.Equal(t, players[1].id, second["id"])
+	assert.Equal(t, true, second["forfeited"])
+	assert.Greater(t, second["totalScore"].(float64), float64(0))
+
+	history := roundEnd["history"].([]interface{})
+	require.Len(t, history, 1)
+	sheet := history[0].(map[string]interface{})
+	assert.Equal(t, players[0].id, sheet["winnerId"])
+	assert.Contains(t, sheet["scores"], players[1].id)
+}
+
+// brokenStrategy never finds a move
+type brokenStrategy struct{}
+
+func (brokenStrategy) ChooseAction(*engine.Game, string) (engine.Action, error) {
+	return engine.Action{}, errors.New("no idea")
+}
+
+func TestBotFallback(t *testing.T) {
+	load := func(t *testing.T, pile string) *engine.Game {
+		h := NewRoomHandler()
+		h.botStrategy = brokenStrategy{}
+		host, _, roomCode := openRoom(t, h, true)
+		deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": `
+current: Carol
+pile: ` + pile + `
+players:
+  - {name: Alice, hand: [5H, 9C]}
+  - {name: Bob, hand: [KS, 3D]}
+  - {name: Carol, hand: [4C, 6D]}
+`})
+		require.Nil(t, host.Last(TypeError))
+		return h.games[roomCode]
+	}
+
+	t.Run("a bot that cannot choose picks up the pile", func(t *testing.T) {
+		game := load(t, "[9H]")
+		carol := game.Players[2]
+		var ids []string
+		for _, c := range carol.Hand {
+			ids = append(ids, c.ID)
+		}
+		assert.Contains(t, ids, "pile-0")
+		assert.Equal(t, "Alice", game.GetCurrentPlayer().Name)
+	})
+
+	t.Run("with nothing to pick up it makes any legal move", func(t *testing.T) {
+		game := load(t, "[]")
+		carol := game.Players[2]
+		assert.Len(t, carol.Hand, 1)
+		assert.Len(t, game.CenterPile, 1)
+		assert.Equal(t, "Alice", game.GetCurrentPlayer().Name)
+	})
+}
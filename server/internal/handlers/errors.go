@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/accounts"
+	"github.com/thben/clearthedeck/internal/services"
+)
+
+// Codes sent with ERROR messages so clients and metrics can group errors without matching on text
+const (
+	ErrCodeBadRequest   = "bad_request"
+	ErrCodeNotFound     = "not_found"
+	ErrCodeForbidden    = "forbidden"
+	ErrCodeUnauthorized = "unauthorized"
+	ErrCodeNotYourTurn  = "not_your_turn"
+	ErrCodeInvalidMove  = "invalid_move"
+	ErrCodeWrongPhase   = "wrong_phase"
+	ErrCodeRoomFull     = "room_full"
+	ErrCodeUnavailable  = "unavailable"
+	ErrCodeUnclassified = "other"
+)
+
+// errorCodes map the errors returned by the rules, rooms and accounts to codes
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{engine.ErrNotYourTurn, ErrCodeNotYourTurn},
+	{engine.ErrInvalidPlay, ErrCodeInvalidMove},
+	{engine.ErrMixedValues, ErrCodeInvalidMove},
+	{engine.ErrFaceUpTooEarly, ErrCodeInvalidMove},
+	{engine.ErrFlipBlocked, ErrCodeInvalidMove},
+	{engine.ErrFlipOneCard, ErrCodeInvalidMove},
+	{engine.ErrPileEmpty, ErrCodeInvalidMove},
+	{engine.ErrRoundOver, ErrCodeWrongPhase},
+	{engine.ErrRoundNotOver, ErrCodeWrongPhase},
+	{engine.ErrGameOver, ErrCodeWrongPhase},
+	{engine.ErrNoGame, ErrCodeWrongPhase},
+	{services.ErrInvalidPlayerCount, ErrCodeWrongPhase},
+	{engine.ErrPlayerNotFound, ErrCodeNotFound},
+	{engine.ErrCardNotFound, ErrCodeNotFound},
+	{services.ErrSeatNotHeld, ErrCodeNotFound},
+	{services.ErrRoomNotFound, ErrCodeNotFound},
+	{services.ErrRoomFull, ErrCodeRoomFull},
+	{services.ErrTooManyRooms, ErrCodeUnavailable},
+	{services.ErrAlreadySeated, ErrCodeBadRequest},
+	{services.ErrPlayerNameEmpty, ErrCodeBadRequest},
+	{services.ErrPlayerNameExists, ErrCodeBadRequest},
+	{engine.ErrInvalidPlayerCount, ErrCodeBadRequest},
+	{engine.ErrUnknownVariant, ErrCodeBadRequest},
+	{engine.ErrInvalidDeal, ErrCodeBadRequest},
+	{engine.ErrUnknownAction, ErrCodeBadRequest},
+	{accounts.ErrInvalidToken, ErrCodeUnauthorized},
+}
+
+// errorCode classifies an error by the error it wraps
+func errorCode(err error) string {
+	for _, rule := range errorCodes {
+		if errors.Is(err, rule.err) {
+			return rule.code
+		}
+	}
+	return ErrCodeUnclassified
+}
@@ -1,9 +1,9 @@
 package handlers
 
 import (
-	"github.com/gorilla/websocket"
-	"github.com/thben/clearthedeck/internal/models"
-	"github.com/thben/clearthedeck/internal/services"
+	"time"
+
+	"github.com/thben/clearthedeck/engine"
 )
 
 // PlayCardsMessage represents the PLAY_CARDS message from client
@@ -20,24 +20,24 @@ type FlipFaceDownMessage struct {
 // GameResponse represents the game state response sent to clients
 type GameResponse struct {
 	Type  string                 `json:"type"`
-	Game  *models.Game           `json:"game,omitempty"`
+	Game  *engine.Game           `json:"game,omitempty"`
 	Room  map[string]interface{} `json:"room,omitempty"`
 	Error string                 `json:"error,omitempty"`
 }
 
 // HandlePlayCards processes PLAY_CARDS WebSocket message
-func (h *RoomHandler) handlePlayCards(conn *websocket.Conn, msg map[string]interface{}) {
+func (h *RoomHandler) handlePlayCards(sess Session, msg map[string]interface{}) {
 	// Get connection info
-	connInfo, ok := h.connInfo[conn]
+	connInfo, ok := h.connInfo[sess]
 	if !ok {
-		h.sendError(conn, "Connection not registered")
+		h.sendError(sess, ErrCodeNotFound, "Connection not registered")
 		return
 	}
 
 	// Parse card IDs
 	cardIDsRaw, ok := msg["cardIds"].([]interface{})
 	if !ok {
-		h.sendError(conn, "cardIds field is required")
+		h.sendError(sess, ErrCodeBadRequest, "cardIds field is required")
 		return
 	}
 
@@ -45,7 +45,7 @@ func (h *RoomHandler) handlePlayCards(conn *websocket.Conn, msg map[string]inter
 	for i, idRaw := range cardIDsRaw {
 		id, ok := idRaw.(string)
 		if !ok {
-			h.sendError(conn, "Invalid card ID format")
+			h.sendError(sess, ErrCodeBadRequest, "Invalid card ID format")
 			return
 		}
 		cardIDs[i] = id
@@ -55,148 +55,274 @@ func (h *RoomHandler) handlePlayCards(conn *websocket.Conn, msg map[string]inter
 
 	room := h.roomService.GetRoom(connInfo.RoomCode)
 	if room == nil {
-		h.sendError(conn, "Room not found")
+		h.sendError(sess, ErrCodeNotFound, "Room not found")
 		return
 	}
 
 	game, ok := h.games[connInfo.RoomCode]
 	if !ok || game == nil {
-		h.sendError(conn, "Game not started")
+		h.sendError(sess, ErrCodeWrongPhase, "Game not started")
 		return
 	}
 
 	// Play the cards
-	err := services.PlayCards(game, connInfo.PlayerID, cardIDs, afterPickup)
+	action := engine.Action{
+		Type:        engine.ActionPlay,
+		PlayerID:    connInfo.PlayerID,
+		CardIDs:     cardIDs,
+		AfterPickup: afterPickup,
+	}
+	before := h.coachSnapshot(connInfo.RoomCode, game, action)
+	snapshot := game.Snapshot()
+	events, err := h.applyAction(game, action)
 	if err != nil {
-		h.sendError(conn, err.Error())
+		h.sendErr(sess, err)
 		return
 	}
+	h.recordAction(connInfo.RoomCode, connInfo.PlayerID, snapshot)
+	h.reviewMove(connInfo.RoomCode, before, action)
 
-	// Check for winner
-	var winner *models.Player
-	for _, player := range game.Players {
-		if services.CheckWinCondition(player) {
-			winner = player
-			break
-		}
+	if h.finishRound(connInfo.RoomCode, game, events) {
+		return
 	}
 
-	if winner != nil {
-		// End the round
-		services.EndRound(game, winner.ID)
-		h.broadcastRoundEnd(connInfo.RoomCode, game, winner)
-	} else {
-		// Broadcast game state to all players in room
-		h.broadcastGameState(connInfo.RoomCode, game)
-	}
+	// Broadcast game state to all players in room
+	h.broadcastGameState(connInfo.RoomCode, game)
+	h.playBotTurns(connInfo.RoomCode, game)
 }
 
 // HandleFlipFaceDown processes FLIP_FACE_DOWN WebSocket message
-func (h *RoomHandler) handleFlipFaceDown(conn *websocket.Conn, msg map[string]interface{}) {
+func (h *RoomHandler) handleFlipFaceDown(sess Session, msg map[string]interface{}) {
 	// Get connection info
-	connInfo, ok := h.connInfo[conn]
+	connInfo, ok := h.connInfo[sess]
 	if !ok {
-		h.sendError(conn, "Connection not registered")
+		h.sendError(sess, ErrCodeNotFound, "Connection not registered")
 		return
 	}
 
 	// Parse card ID
 	cardID, ok := msg["cardId"].(string)
 	if !ok {
-		h.sendError(conn, "cardId field is required")
+		h.sendError(sess, ErrCodeBadRequest, "cardId field is required")
 		return
 	}
 
 	room := h.roomService.GetRoom(connInfo.RoomCode)
 	if room == nil {
-		h.sendError(conn, "Room not found")
+		h.sendError(sess, ErrCodeNotFound, "Room not found")
 		return
 	}
 
 	game, ok := h.games[connInfo.RoomCode]
 	if !ok || game == nil {
-		h.sendError(conn, "Game not started")
+		h.sendError(sess, ErrCodeWrongPhase, "Game not started")
 		return
 	}
 
 	// Flip the face-down card
-	err := services.FlipFaceDown(game, connInfo.PlayerID, cardID)
+	action := engine.Action{
+		Type:     engine.ActionFlip,
+		PlayerID: connInfo.PlayerID,
+		CardIDs:  []string{cardID},
+	}
+	before := h.coachSnapshot(connInfo.RoomCode, game, action)
+	events, err := h.applyAction(game, action)
 	if err != nil {
-		h.sendError(conn, err.Error())
+		h.sendErr(sess, err)
 		return
 	}
+	h.recordReveal(connInfo.RoomCode, connInfo.PlayerID)
+	h.reviewMove(connInfo.RoomCode, before, action)
 
-	// Check for winner
-	var winner *models.Player
-	for _, player := range game.Players {
-		if services.CheckWinCondition(player) {
-			winner = player
-			break
-		}
+	if h.finishRound(connInfo.RoomCode, game, events) {
+		return
 	}
 
-	if winner != nil {
-		// End the round
-		services.EndRound(game, winner.ID)
-		h.broadcastRoundEnd(connInfo.RoomCode, game, winner)
-	} else {
-		// Broadcast game state to all players in room
-		h.broadcastGameState(connInfo.RoomCode, game)
+	// Broadcast game state to all players in room
+	h.broadcastGameState(connInfo.RoomCode, game)
+	h.playBotTurns(connInfo.RoomCode, game)
+}
+
+// handlePickupPile processes PICKUP_PILE WebSocket message
+func (h *RoomHandler) handlePickupPile(sess Session, msg map[string]interface{}) {
+	// Get connection info
+	connInfo, ok := h.connInfo[sess]
+	if !ok {
+		h.sendError(sess, ErrCodeNotFound, "Connection not registered")
+		return
 	}
+
+	game, ok := h.games[connInfo.RoomCode]
+	if !ok || game == nil {
+		h.sendError(sess, ErrCodeWrongPhase, "Game not started")
+		return
+	}
+
+	action := engine.Action{Type: engine.ActionPickup, PlayerID: connInfo.PlayerID}
+	before := h.coachSnapshot(connInfo.RoomCode, game, action)
+	snapshot := game.Snapshot()
+	events, err := h.applyAction(game, action)
+	if err != nil {
+		h.sendErr(sess, err)
+		return
+	}
+	h.recordAction(connInfo.RoomCode, connInfo.PlayerID, snapshot)
+	h.reviewMove(connInfo.RoomCode, before, action)
+
+	if h.finishRound(connInfo.RoomCode, game, events) {
+		return
+	}
+
+	h.broadcastGameState(connInfo.RoomCode, game)
+}
+
+// applyAction runs an action through the rules engine and writes the result back into the room's game
+// The game's player objects are kept, since the room shares them
+func (h *RoomHandler) applyAction(game *engine.Game, action engine.Action) ([]engine.Event, error) {
+	next, events, err := engine.Apply(engine.FromGame(game), action)
+	if err != nil {
+		return nil, err
+	}
+	next.CopyInto(game)
+	h.logEvents(game, events)
+	h.followMatch(game.RoomCode, next, events)
+	return events, nil
+}
+
+// finishRound broadcasts the results if the action's events ended the round
+func (h *RoomHandler) finishRound(roomCode string, game *engine.Game, events []engine.Event) bool {
+	for _, event := range events {
+		if event.Type != engine.EventRoundEnded {
+			continue
+		}
+		h.clearUndo(roomCode, undoSuperseded)
+		// The game ends before the round is recorded, so the last round is logged and sent as its end
+		if h.roundLimitReached(roomCode, game) {
+			game.Finish()
+		}
+		h.recordRoundEnd(roomCode, game, event.Reason, event.PlayerID)
+		h.recordMatchRound(roomCode, game)
+
+		// Stalemated rounds have no winner
+		var winner *engine.Player
+		if i := game.PlayerIndex(event.PlayerID); event.PlayerID != "" && i >= 0 {
+			winner = game.Players[i]
+		}
+		h.broadcastRoundEnd(roomCode, game, winner)
+		h.sendCoachReviews(roomCode, game)
+		if game.IsFinished {
+			h.finishGame(roomCode, game)
+		}
+		return true
+	}
+	return false
 }
 
 // broadcastGameState broadcasts the current game state to all players in the room
-func (h *RoomHandler) broadcastGameState(roomCode string, game *models.Game) {
+func (h *RoomHandler) broadcastGameState(roomCode string, game *engine.Game) {
 	response := map[string]interface{}{
 		"type": "GAME_UPDATE",
 		"game": h.serializeGame(game),
 	}
 
 	h.broadcastToRoom(roomCode, response, nil)
+	h.scheduleTurn(roomCode, game)
 }
 
-// broadcastRoundEnd broadcasts round end with scores to all players
-func (h *RoomHandler) broadcastRoundEnd(roomCode string, game *models.Game, winner *models.Player) {
+// broadcastRoundEnd broadcasts the round summary and the score sheet so far to all players
+func (h *RoomHandler) broadcastRoundEnd(roomCode string, game *engine.Game, winner *engine.Player) {
+	var scores []engine.PlayerResult
+	reason := engine.RoundEndWon
+	if result := game.LastResult(); result != nil {
+		scores = result.Players
+		reason = result.Reason
+	}
+
+	// Stalemated rounds have no winner
+	var winnerInfo map[string]interface{}
+	if winner != nil {
+		winnerInfo = map[string]interface{}{
+			"id":   winner.ID,
+			"name": winner.Name,
+		}
+	}
+
 	response := map[string]interface{}{
-		"type":   "ROUND_END",
-		"winner": winner,
-		"scores": game.Players,
-		"round":  game.Round,
-		"game":   h.serializeGame(game),
+		"type":    "ROUND_END",
+		"winner":  winnerInfo,
+		"reason":  reason,
+		"scores":  scores,
+		"history": serializeHistory(game.History),
+		"round":   game.Round,
+		"scoring": game.ActiveScoring(),
+		"game":    h.serializeGame(game),
 	}
 
+	h.stopTurnTimer(roomCode)
 	h.broadcastToRoom(roomCode, response, nil)
 }
 
+// serializeHistory reduces the round history to a score sheet: round scores and totals per player
+func serializeHistory(history []engine.RoundResult) []map[string]interface{} {
+	sheet := make([]map[string]interface{}, 0, len(history))
+	for _, result := range history {
+		scores := make(map[string]interface{}, len(result.Players))
+		for _, p := range result.Players {
+			scores[p.ID] = map[string]interface{}{
+				"roundScore": p.RoundScore,
+				"totalScore": p.TotalScore,
+			}
+		}
+		sheet = append(sheet, map[string]interface{}{
+			"round":    result.Round,
+			"winnerId": result.WinnerID,
+			"reason":   result.Reason,
+			"scoring":  result.Scoring,
+			"scores":   scores,
+		})
+	}
+	return sheet
+}
+
 // handleNextRound processes NEXT_ROUND WebSocket message
-func (h *RoomHandler) handleNextRound(conn *websocket.Conn, msg map[string]interface{}) {
+func (h *RoomHandler) handleNextRound(sess Session, msg map[string]interface{}) {
 	// Get connection info
-	connInfo, ok := h.connInfo[conn]
+	connInfo, ok := h.connInfo[sess]
 	if !ok {
-		h.sendError(conn, "Connection not registered")
+		h.sendError(sess, ErrCodeNotFound, "Connection not registered")
 		return
 	}
 
 	room := h.roomService.GetRoom(connInfo.RoomCode)
 	if room == nil {
-		h.sendError(conn, "Room not found")
+		h.sendError(sess, ErrCodeNotFound, "Room not found")
 		return
 	}
 
 	// Only host can start next round
 	if room.GetHostID() != connInfo.PlayerID {
-		h.sendError(conn, "Only host can start next round")
+		h.sendError(sess, ErrCodeForbidden, "Only host can start next round")
 		return
 	}
 
 	game, ok := h.games[connInfo.RoomCode]
 	if !ok || game == nil {
-		h.sendError(conn, "Game not started")
+		h.sendError(sess, ErrCodeWrongPhase, "Game not started")
+		return
+	}
+
+	if game.IsFinished {
+		h.sendError(sess, ErrCodeWrongPhase, "Game is over")
 		return
 	}
 
 	// Start next round
-	services.StartNextRound(game)
+	if _, err := h.applyAction(game, engine.Action{Type: engine.ActionNextRound}); err != nil {
+		h.sendErr(sess, err)
+		return
+	}
+	h.roundStarted[connInfo.RoomCode] = time.Now()
+	h.roomLog(connInfo.RoomCode).Info("round started", "round", game.Round)
 
 	// Broadcast new round started
 	response := map[string]interface{}{
@@ -205,4 +331,74 @@ func (h *RoomHandler) handleNextRound(conn *websocket.Conn, msg map[string]inter
 	}
 
 	h.broadcastToRoom(connInfo.RoomCode, response, nil)
+	h.scheduleTurn(connInfo.RoomCode, game)
+	h.playBotTurns(connInfo.RoomCode, game)
+}
+
+// handleEndGame processes END_GAME WebSocket message
+// The host ends the game between rounds; it counts as played to the end, so the match is rated.
+func (h *RoomHandler) handleEndGame(sess Session, msg map[string]interface{}) {
+	connInfo, ok := h.connInfo[sess]
+	if !ok {
+		h.sendError(sess, ErrCodeNotFound, "Connection not registered")
+		return
+	}
+
+	room := h.roomService.GetRoom(connInfo.RoomCode)
+	if room == nil {
+		h.sendError(sess, ErrCodeNotFound, "Room not found")
+		return
+	}
+
+	if room.GetHostID() != connInfo.PlayerID {
+		h.sendError(sess, ErrCodeForbidden, "Only host can end the game")
+		return
+	}
+
+	game, ok := h.games[connInfo.RoomCode]
+	if !ok || game == nil {
+		h.sendError(sess, ErrCodeWrongPhase, "Game not started")
+		return
+	}
+
+	if game.IsFinished {
+		h.sendError(sess, ErrCodeWrongPhase, "Game is over")
+		return
+	}
+
+	if !game.RoundOver() {
+		h.sendError(sess, ErrCodeWrongPhase, "The game can only be ended between rounds")
+		return
+	}
+
+	h.finishGame(connInfo.RoomCode, game)
+}
+
+// roundLimitReached reports whether the game has played the rounds its room was set to
+func (h *RoomHandler) roundLimitReached(roomCode string, game *engine.Game) bool {
+	room := h.roomService.GetRoom(roomCode)
+	if room == nil {
+		return false
+	}
+	limit := room.GetSettings().RoundLimit
+	return limit > 0 && game.Round >= limit
+}
+
+// finishGame ends a game that was played to its end, saving its match as finished, and sends everyone the final standings
+func (h *RoomHandler) finishGame(roomCode string, game *engine.Game) {
+	game.Finish()
+	h.stopTurnTimer(roomCode)
+	h.endMatch(roomCode)
+	h.roomLog(roomCode).Info("game over", "rounds", len(game.History))
+
+	var standings []engine.PlayerResult
+	if result := game.LastResult(); result != nil {
+		standings = result.Players
+	}
+	h.broadcastToRoom(roomCode, map[string]interface{}{
+		"type":      TypeGameOver,
+		"standings": standings,
+		"history":   serializeHistory(game.History),
+		"game":      h.serializeGame(game),
+	}, nil)
 }
@@ -0,0 +1,125 @@
+package handlers
+
+import "time"
+
+// Room closure reasons sent with ROOM_CLOSED
+const (
+	closeIdleLobby    = "idle"
+	closeGameFinished = "finished"
+	closeAbandoned    = "abandoned"
+)
+
+// LifecycleConfig sets how long rooms may sit idle before they are closed
+type LifecycleConfig struct {
+	LobbyTTL        time.Duration // Rooms without a game
+	FinishedGameTTL time.Duration // Rooms whose game is over
+	IdleGameTTL     time.Duration // Rooms whose game is still running
+	SweepInterval   time.Duration // How often idle rooms are looked for
+}
+
+// DefaultLifecycleConfig returns the idle limits used unless configured otherwise
+func DefaultLifecycleConfig() LifecycleConfig {
+	return LifecycleConfig{
+		LobbyTTL:        30 * time.Minute,
+		FinishedGameTTL: 10 * time.Minute,
+		IdleGameTTL:     2 * time.Hour,
+		SweepInterval:   time.Minute,
+	}
+}
+
+// StartLifecycle periodically closes idle rooms; call the returned function to stop it
+func (h *RoomHandler) StartLifecycle(cfg LifecycleConfig) func() {
+	ticker := time.NewTicker(cfg.SweepInterval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				h.mu.Lock()
+				h.sweep(cfg, now)
+				h.mu.Unlock()
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// touch records activity in a room
+func (h *RoomHandler) touch(roomCode string) {
+	h.lastActivity[roomCode] = time.Now()
+}
+
+// sweep closes rooms that have been idle longer than their TTL and drops state left behind by closed rooms
+func (h *RoomHandler) sweep(cfg LifecycleConfig, now time.Time) {
+	for _, code := range h.roomService.RoomCodes() {
+		last, ok := h.lastActivity[code]
+		if !ok {
+			// A room nobody has acted in yet, such as a restored one, is idle from now on
+			h.lastActivity[code] = now
+			continue
+		}
+		idle := now.Sub(last)
+		game := h.games[code]
+		switch {
+		case game == nil:
+			if idle >= cfg.LobbyTTL {
+				h.expireRoom(code, closeIdleLobby)
+			}
+		case game.IsFinished:
+			if idle >= cfg.FinishedGameTTL {
+				h.expireRoom(code, closeGameFinished)
+			}
+		default:
+			if idle >= cfg.IdleGameTTL {
+				h.expireRoom(code, closeAbandoned)
+			}
+		}
+	}
+
+	// Games, connections and activity for rooms that no longer exist
+	for code := range h.games {
+		if h.roomService.GetRoom(code) == nil {
+			h.closeRoom(code)
+		}
+	}
+	for code := range h.roomConnections {
+		if h.roomService.GetRoom(code) == nil {
+			h.closeRoom(code)
+		}
+	}
+	for code := range h.lastActivity {
+		if h.roomService.GetRoom(code) == nil {
+			delete(h.lastActivity, code)
+		}
+	}
+	for ip, c := range h.loginsPerIP {
+		if now.Sub(c.start) >= loginWindow {
+			delete(h.loginsPerIP, ip)
+		}
+	}
+}
+
+// expireRoom tells everyone still connected that the room is closing, then closes it
+func (h *RoomHandler) expireRoom(roomCode, reason string) {
+	h.roomLog(roomCode).Info("room expired", "reason", reason)
+	broadcast := map[string]interface{}{
+		"type":     TypeRoomClosed,
+		"roomCode": roomCode,
+		"reason":   reason,
+	}
+	h.broadcastToRoom(roomCode, broadcast, nil)
+	h.closeRoom(roomCode)
+}
+
+// forgetConnections detaches every connection from a room so they can create or join another
+func (h *RoomHandler) forgetConnections(roomCode string) {
+	for conn := range h.roomConnections[roomCode] {
+		if info, ok := h.connInfo[conn]; ok && info.RoomCode == roomCode {
+			delete(h.connInfo, conn)
+		}
+	}
+	delete(h.roomConnections, roomCode)
+}
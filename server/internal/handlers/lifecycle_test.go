@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLifecycle(t *testing.T) {
+	cfg := LifecycleConfig{
+		LobbyTTL:        time.Minute,
+		FinishedGameTTL: 2 * time.Minute,
+		IdleGameTTL:     time.Hour,
+		SweepInterval:   time.Minute,
+	}
+
+	newServer := func(t *testing.T) (*RoomHandler, string) {
+		handler := NewRoomHandler()
+		server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+		t.Cleanup(server.Close)
+		return handler, "ws" + strings.TrimPrefix(server.URL, "http")
+	}
+
+	t.Run("idle lobby is closed and its connections notified", func(t *testing.T) {
+		handler, wsURL := newServer(t)
+		roomCode, players := setupTable(t, wsURL, 2)
+
+		handler.mu.Lock()
+		handler.sweep(cfg, time.Now().Add(30*time.Second))
+		require.NotNil(t, handler.roomService.GetRoom(roomCode), "active lobby should be kept")
+		handler.sweep(cfg, time.Now().Add(2*time.Minute))
+		handler.mu.Unlock()
+
+		for _, p := range players {
+			closed := waitForType(t, p.conn, "ROOM_CLOSED")
+			assert.Equal(t, "idle", closed["reason"])
+		}
+
+		handler.mu.Lock()
+		defer handler.mu.Unlock()
+		assert.Nil(t, handler.roomService.GetRoom(roomCode))
+		assert.NotContains(t, handler.roomConnections, roomCode)
+		assert.NotContains(t, handler.lastActivity, roomCode)
+		assert.Empty(t, handler.connInfo)
+	})
+
+	t.Run("running game outlives the lobby TTL but finished game does not", func(t *testing.T) {
+		handler, wsURL := newServer(t)
+		roomCode, players := setupTable(t, wsURL, 2)
+		startTableGame(t, roomCode, players)
+
+		handler.mu.Lock()
+		handler.sweep(cfg, time.Now().Add(5*time.Minute))
+		require.NotNil(t, handler.roomService.GetRoom(roomCode))
+		handler.games[roomCode].Finish()
+		handler.sweep(cfg, time.Now().Add(5*time.Minute))
+		handler.mu.Unlock()
+
+		closed := waitForType(t, players[0].conn, "ROOM_CLOSED")
+		assert.Equal(t, "finished", closed["reason"])
+
+		handler.mu.Lock()
+		defer handler.mu.Unlock()
+		assert.NotContains(t, handler.games, roomCode)
+	})
+
+	t.Run("last player leaving a lobby removes all of its state", func(t *testing.T) {
+		handler, wsURL := newServer(t)
+		roomCode, players := setupTable(t, wsURL, 1)
+
+		require.NoError(t, players[0].conn.WriteJSON(map[string]interface{}{
+			"type": "LEAVE_ROOM", "roomCode": roomCode, "playerId": players[0].id,
+		}))
+		// Any reply proves the leave has been handled
+		require.NoError(t, players[0].conn.WriteJSON(map[string]interface{}{"type": "NEXT_ROUND"}))
+		waitForType(t, players[0].conn, "ERROR")
+
+		handler.mu.Lock()
+		defer handler.mu.Unlock()
+		assert.Nil(t, handler.roomService.GetRoom(roomCode))
+		assert.NotContains(t, handler.games, roomCode)
+		assert.NotContains(t, handler.roomConnections, roomCode)
+		assert.NotContains(t, handler.lastActivity, roomCode)
+	})
+
+	t.Run("a room with no recorded activity is idle from the first sweep", func(t *testing.T) {
+		handler, _ := newServer(t)
+		handler.mu.Lock()
+		defer handler.mu.Unlock()
+		room, _, err := handler.roomService.CreateRoom("Host")
+		require.NoError(t, err)
+
+		now := time.Now()
+		handler.sweep(cfg, now)
+		require.NotNil(t, handler.roomService.GetRoom(room.Code), "the idle clock starts now, not at the zero time")
+		handler.sweep(cfg, now.Add(2*time.Minute))
+		assert.Nil(t, handler.roomService.GetRoom(room.Code))
+	})
+
+	t.Run("orphaned games are dropped", func(t *testing.T) {
+		handler, _ := newServer(t)
+		handler.mu.Lock()
+		defer handler.mu.Unlock()
+		handler.games["GHOST1"] = nil
+		handler.sweep(cfg, time.Now())
+		assert.NotContains(t, handler.games, "GHOST1")
+	})
+}
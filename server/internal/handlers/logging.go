@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"log/slog"
+
+	"github.com/thben/clearthedeck/engine"
+)
+
+// roomLog returns the handler's logger with the room code attached
+func (h *RoomHandler) roomLog(roomCode string) *slog.Logger {
+	return h.log.With("room", roomCode)
+}
+
+// sessionLog returns the handler's logger with the session, its room and player, and the message being handled
+func (h *RoomHandler) sessionLog(sess Session) *slog.Logger {
+	logger := h.log.With("session", sess.ID())
+	if info, ok := h.connInfo[sess]; ok {
+		logger = logger.With("room", info.RoomCode, "player", info.PlayerID)
+	}
+	if h.msgType != "" {
+		logger = logger.With("msgType", h.msgType)
+	}
+	return logger
+}
+
+// logEvents records what an action did at a table
+// Clears are logged at info, everything else at debug
+func (h *RoomHandler) logEvents(game *engine.Game, events []engine.Event) {
+	logger := h.roomLog(game.RoomCode).With("round", game.Round)
+	for _, event := range events {
+		switch event.Type {
+		case engine.EventPileCleared:
+			logger.Info("pile cleared", "player", event.PlayerID, "detail", event.Message)
+		case engine.EventRoundEnded:
+			// Logged by finishRound with the table's context
+		default:
+			logger.Debug("game event", "event", event.Type, "player", event.PlayerID, "cards", len(event.Cards), "count", event.Count)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logRecords decodes every JSON log line written so far
+func logRecords(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n")) {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(line, &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func findRecord(records []map[string]interface{}, msg string) map[string]interface{} {
+	for _, record := range records {
+		if record["msg"] == msg {
+			return record
+		}
+	}
+	return nil
+}
+
+func TestStructuredLogs(t *testing.T) {
+	var out bytes.Buffer
+	opts := DefaultOptions()
+	opts.Logger = slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	handler := NewRoomHandlerWithOptions(opts)
+
+	host, guest := NewMemorySession("host"), NewMemorySession("guest")
+	deliver(t, handler, host, map[string]interface{}{"type": "CREATE_ROOM", "playerName": "Host"})
+	created := host.Last(TypeRoomCreated)
+	roomCode := created["roomCode"].(string)
+	deliver(t, handler, guest, map[string]interface{}{"type": "JOIN_ROOM", "roomCode": roomCode, "playerName": "Guest"})
+	deliver(t, handler, guest, map[string]interface{}{"type": "START_GAME", "roomCode": roomCode, "playerId": guest.Last(TypeRoomJoined)["playerId"]})
+	deliver(t, handler, host, map[string]interface{}{"type": "START_GAME", "roomCode": roomCode, "playerId": created["playerId"]})
+
+	records := logRecords(t, &out)
+
+	t.Run("room events carry the room and player", func(t *testing.T) {
+		record := findRecord(records, "room created")
+		require.NotNil(t, record)
+		assert.Equal(t, roomCode, record["room"])
+		assert.Equal(t, created["playerId"], record["player"])
+		assert.Equal(t, "host", record["session"])
+		assert.Equal(t, TypeCreateRoom, record["msgType"])
+	})
+
+	t.Run("rejected requests are logged with their code", func(t *testing.T) {
+		record := findRecord(records, "request rejected")
+		require.NotNil(t, record)
+		assert.Equal(t, ErrCodeForbidden, record["code"])
+		assert.Equal(t, "guest", record["session"])
+		assert.Equal(t, roomCode, record["room"])
+		assert.Equal(t, TypeStartGame, record["msgType"])
+	})
+
+	t.Run("game starts are logged", func(t *testing.T) {
+		record := findRecord(records, "game started")
+		require.NotNil(t, record)
+		assert.Equal(t, roomCode, record["room"])
+		assert.EqualValues(t, 2, record["players"])
+	})
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/metrics"
+)
+
+// knownMessageTypes are the client messages handleMessage dispatches, used as metric labels
+var knownMessageTypes = map[string]bool{
+	TypeCreateRoom:     true,
+	TypeJoinRoom:       true,
+	TypeLeaveRoom:      true,
+	TypeStartGame:      true,
+	TypePlayCards:      true,
+	TypeFlipFaceDown:   true,
+	TypePickupPile:     true,
+	TypeNextRound:      true,
+	TypeEndGame:        true,
+	TypeRejoinRoom:     true,
+	TypeUpdateSettings: true,
+	TypeRequestUndo:    true,
+	TypeUndoVote:       true,
+	TypeLoadScenario:   true,
+	TypeRequestTracker: true,
+	TypeRequestHint:    true,
+	TypeAuthenticate:   true,
+	TypeRequestStats:   true,
+}
+
+// RegisterMetrics adds gauges for the handler's rooms, games and connections to the registry
+func (h *RoomHandler) RegisterMetrics(reg *metrics.Registry) {
+	reg.NewGaugeFunc("ctd_rooms_active", "Rooms currently open.", func() float64 {
+		return float64(len(h.roomService.RoomCodes()))
+	})
+	reg.NewGaugeFunc("ctd_games_in_progress", "Games dealt and not yet finished.", func() float64 {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		n := 0
+		for _, game := range h.games {
+			if !game.IsFinished {
+				n++
+			}
+		}
+		return float64(n)
+	})
+	reg.NewGaugeFunc("ctd_connections", "WebSocket connections currently open.", func() float64 {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return float64(len(h.sessions))
+	})
+}
+
+// recordRoundEnd counts and logs a scored round, with how long it took when its deal time is known
+// Tables restored after a restart have no deal time, so their first round is counted but not timed
+func (h *RoomHandler) recordRoundEnd(roomCode string, game *engine.Game, reason, winnerID string) {
+	metrics.RoundsCompleted.Inc(reason)
+	logger := h.roomLog(roomCode).With("round", game.Round, "reason", reason, "winner", winnerID, "gameOver", game.IsFinished)
+	if started, ok := h.roundStarted[roomCode]; ok {
+		elapsed := time.Since(started)
+		metrics.RoundDuration.Observe("", elapsed.Seconds())
+		delete(h.roundStarted, roomCode)
+		logger = logger.With("duration", elapsed)
+	}
+	logger.Info("round ended")
+}
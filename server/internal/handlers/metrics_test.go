@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/accounts"
+	"github.com/thben/clearthedeck/internal/metrics"
+	"github.com/thben/clearthedeck/internal/services"
+)
+
+func TestErrorCode(t *testing.T) {
+	cases := []struct {
+		err  error
+		code string
+	}{
+		{engine.ErrNotYourTurn, ErrCodeNotYourTurn},
+		{fmt.Errorf("%w: 5 cannot go on 9", engine.ErrInvalidPlay), ErrCodeInvalidMove},
+		{services.ErrRoomNotFound, ErrCodeNotFound},
+		{services.ErrRoomFull, ErrCodeRoomFull},
+		{services.ErrTooManyRooms, ErrCodeUnavailable},
+		{engine.ErrRoundNotOver, ErrCodeWrongPhase},
+		{fmt.Errorf("%w: 7", engine.ErrInvalidPlayerCount), ErrCodeBadRequest},
+		{accounts.ErrInvalidToken, ErrCodeUnauthorized},
+		{errors.New("invalid play, but not one from the rules"), ErrCodeUnclassified},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.code, errorCode(c.err), c.err.Error())
+	}
+}
+
+func TestMessageMetrics(t *testing.T) {
+	handler := NewRoomHandler()
+	host := NewMemorySession("host")
+
+	created := metrics.MessagesHandled.Value(TypeCreateRoom)
+	unknown := metrics.MessagesHandled.Value("unknown")
+	badRequests := metrics.Errors.Value(ErrCodeBadRequest)
+
+	deliver(t, handler, host, map[string]interface{}{"type": "CREATE_ROOM", "playerName": "Host"})
+	deliver(t, handler, host, map[string]interface{}{"type": "NOT_A_MESSAGE"})
+
+	assert.Equal(t, created+1, metrics.MessagesHandled.Value(TypeCreateRoom))
+	assert.Equal(t, unknown+1, metrics.MessagesHandled.Value("unknown"))
+	assert.Equal(t, badRequests+1, metrics.Errors.Value(ErrCodeBadRequest))
+	assert.Equal(t, ErrCodeBadRequest, host.Last(TypeError)["code"])
+}
+
+func TestRegisterMetrics(t *testing.T) {
+	handler := NewRoomHandler()
+	reg := metrics.NewRegistry()
+	handler.RegisterMetrics(reg)
+
+	host, guest := NewMemorySession("host"), NewMemorySession("guest")
+	handler.Connect(host)
+	handler.Connect(guest)
+	deliver(t, handler, host, map[string]interface{}{"type": "CREATE_ROOM", "playerName": "Host"})
+	created := host.Last(TypeRoomCreated)
+	roomCode := created["roomCode"].(string)
+	deliver(t, handler, guest, map[string]interface{}{"type": "JOIN_ROOM", "roomCode": roomCode, "playerName": "Guest"})
+	deliver(t, handler, host, map[string]interface{}{"type": "START_GAME", "roomCode": roomCode, "playerId": created["playerId"]})
+	require.NotNil(t, host.Last(TypeGameStarted))
+
+	var out bytes.Buffer
+	reg.Write(&out)
+	assert.Contains(t, out.String(), "ctd_rooms_active 1\n")
+	assert.Contains(t, out.String(), "ctd_games_in_progress 1\n")
+	assert.Contains(t, out.String(), "ctd_connections 2\n")
+}
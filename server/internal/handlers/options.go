@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/accounts"
+	"github.com/thben/clearthedeck/internal/models"
+	"github.com/thben/clearthedeck/internal/stats"
+)
+
+// Options configure a RoomHandler
+type Options struct {
+	AllowedOrigins []string      // Origins allowed to open a WebSocket; empty allows any origin
+	MaxRooms       int           // Rooms open at once; 0 means no limit
+	MaxConnsPerIP  int           // WebSockets open from one address; 0 means no limit
+	MaxLoginsPerIP int           // Register and login requests from one address each minute; 0 means no limit
+	TurnTimeout    time.Duration // How long a player has for a turn before a bot plays it; 0 disables turn timers
+	UndoVoteWindow time.Duration // How long the table has to approve an undo request
+	SeatHold       time.Duration // How long new rooms hold the seat of a player who left mid-game
+	DefaultRules   engine.RuleSet
+	Accounts       *accounts.Service // nil keeps accounts in memory until the process exits
+	Stats          *stats.Store      // nil keeps statistics in memory until the process exits
+	Logger         *slog.Logger      // nil uses slog.Default()
+}
+
+// DefaultOptions returns the options used by NewRoomHandler
+func DefaultOptions() Options {
+	return Options{
+		UndoVoteWindow: 8 * time.Second,
+		SeatHold:       models.DefaultSeatHoldDuration,
+		DefaultRules:   engine.DefaultRuleSet(),
+	}
+}
+
+// newUpgrader builds a WebSocket upgrader that only accepts the allowed origins
+func newUpgrader(allowed []string) websocket.Upgrader {
+	return websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			if len(allowed) == 0 {
+				return true
+			}
+			origin := r.Header.Get("Origin")
+			for _, o := range allowed {
+				if o == origin {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// remoteIP returns the address a request came from, without its port
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// acquireConn counts a new connection from the address, refusing it when the address is at its limit
+func (h *RoomHandler) acquireConn(ip string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.opts.MaxConnsPerIP > 0 && h.connsPerIP[ip] >= h.opts.MaxConnsPerIP {
+		return false
+	}
+	h.connsPerIP[ip]++
+	return true
+}
+
+// releaseConn forgets a closed connection from the address
+func (h *RoomHandler) releaseConn(ip string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.connsPerIP[ip]--; h.connsPerIP[ip] <= 0 {
+		delete(h.connsPerIP, ip)
+	}
+}
+
+// loginWindow is the period MaxLoginsPerIP counts requests over
+const loginWindow = time.Minute
+
+// loginCount counts one address's register and login requests since the window started
+type loginCount struct {
+	start time.Time
+	count int
+}
+
+// allowLogin counts a register or login request from the address, refusing it when the address is at its limit
+// Password hashing is slow on purpose, so the limit keeps one address from guessing passwords or tying up the CPU.
+func (h *RoomHandler) allowLogin(ip string, now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.opts.MaxLoginsPerIP <= 0 {
+		return true
+	}
+	c := h.loginsPerIP[ip]
+	if c == nil || now.Sub(c.start) >= loginWindow {
+		c = &loginCount{start: now}
+		h.loginsPerIP[ip] = c
+	}
+	if c.count >= h.opts.MaxLoginsPerIP {
+		return false
+	}
+	c.count++
+	return true
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thben/clearthedeck/engine"
+)
+
+func TestHandlerOptions(t *testing.T) {
+	t.Run("only allowed origins may connect", func(t *testing.T) {
+		handler := NewRoomHandlerWithOptions(Options{AllowedOrigins: []string{"https://cards.example"}})
+		server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+		t.Cleanup(server.Close)
+		wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+		_, resp, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {"https://evil.example"}})
+		require.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {"https://cards.example"}})
+		require.NoError(t, err)
+		conn.Close()
+	})
+
+	t.Run("connections per address are limited", func(t *testing.T) {
+		handler := NewRoomHandlerWithOptions(Options{MaxConnsPerIP: 1})
+		server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+		t.Cleanup(server.Close)
+		wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+		first, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		require.NoError(t, err)
+		_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		require.Error(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+		// Closing the first connection frees the slot
+		first.Close()
+		require.Eventually(t, func() bool {
+			conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+			if err != nil {
+				return false
+			}
+			conn.Close()
+			return true
+		}, 2*time.Second, 20*time.Millisecond)
+	})
+
+	t.Run("rooms beyond the limit are refused", func(t *testing.T) {
+		handler := NewRoomHandlerWithOptions(Options{MaxRooms: 1})
+		first, second := NewMemorySession("a"), NewMemorySession("b")
+
+		deliver(t, handler, first, map[string]interface{}{"type": "CREATE_ROOM", "playerName": "A"})
+		deliver(t, handler, second, map[string]interface{}{"type": "CREATE_ROOM", "playerName": "B"})
+
+		require.NotNil(t, first.Last(TypeRoomCreated))
+		assert.Nil(t, second.Last(TypeRoomCreated))
+		assert.Contains(t, second.Last(TypeError)["message"], "no room for more tables")
+	})
+
+	t.Run("new rooms use the configured rules and seat hold", func(t *testing.T) {
+		rules, _ := engine.LookupRuleSet(engine.RulesStrict)
+		handler := NewRoomHandlerWithOptions(Options{DefaultRules: rules, SeatHold: 5 * time.Minute})
+		host := NewMemorySession("host")
+
+		deliver(t, handler, host, map[string]interface{}{"type": "CREATE_ROOM", "playerName": "Host"})
+
+		room := handler.roomService.GetRoom(host.Last(TypeRoomCreated)["roomCode"].(string))
+		require.NotNil(t, room)
+		assert.Equal(t, engine.RulesStrict, room.GetSettings().Rules.Name)
+		assert.Equal(t, 5*time.Minute, room.GetSettings().SeatHoldDuration)
+	})
+}
+
+func TestTurnTimeout(t *testing.T) {
+	opts := DefaultOptions()
+	opts.TurnTimeout = 50 * time.Millisecond
+	handler := NewRoomHandlerWithOptions(opts)
+
+	host, guest := NewMemorySession("host"), NewMemorySession("guest")
+	deliver(t, handler, host, map[string]interface{}{"type": "CREATE_ROOM", "playerName": "Host"})
+	created := host.Last(TypeRoomCreated)
+	roomCode := created["roomCode"].(string)
+	deliver(t, handler, guest, map[string]interface{}{"type": "JOIN_ROOM", "roomCode": roomCode, "playerName": "Guest"})
+	deliver(t, handler, host, map[string]interface{}{"type": "START_GAME", "roomCode": roomCode, "playerId": created["playerId"]})
+
+	handler.mu.Lock()
+	firstPlayer := handler.games[roomCode].GetCurrentPlayer().ID
+	handler.mu.Unlock()
+
+	require.Eventually(t, func() bool { return host.Last(TypeTurnTimedOut) != nil }, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, firstPlayer, host.Last(TypeTurnTimedOut)["playerId"])
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	assert.Positive(t, handler.games[roomCode].Progress.Actions, "a move should have been played for the player")
+}
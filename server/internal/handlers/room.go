@@ -2,12 +2,21 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/accounts"
+	"github.com/thben/clearthedeck/internal/bot"
+	"github.com/thben/clearthedeck/internal/metrics"
 	"github.com/thben/clearthedeck/internal/models"
 	"github.com/thben/clearthedeck/internal/services"
+	"github.com/thben/clearthedeck/internal/stats"
+	"github.com/thben/clearthedeck/internal/storage"
 )
 
 // Message types
@@ -23,22 +32,99 @@ const (
 	TypeGameStarted  = "GAME_STARTED"
 	TypePlayCards    = "PLAY_CARDS"
 	TypeFlipFaceDown = "FLIP_FACE_DOWN"
+	TypePickupPile   = "PICKUP_PILE"
 	TypeGameUpdate   = "GAME_UPDATE"
 	TypeRoundEnd     = "ROUND_END"
 	TypeNextRound    = "NEXT_ROUND"
 	TypeRoundStarted = "ROUND_STARTED"
+	TypeEndGame      = "END_GAME"
+	TypeGameOver     = "GAME_OVER"
 	TypeError        = "ERROR"
+
+	TypeRejoinRoom      = "REJOIN_ROOM"
+	TypePlayerRejoined  = "PLAYER_REJOINED"
+	TypeUpdateSettings  = "UPDATE_SETTINGS"
+	TypeSettingsUpdated = "SETTINGS_UPDATED"
+
+	TypeRequestUndo   = "REQUEST_UNDO"
+	TypeUndoVote      = "UNDO_VOTE"
+	TypeUndoRequested = "UNDO_REQUESTED"
+	TypeUndoApplied   = "UNDO_APPLIED"
+	TypeUndoRejected  = "UNDO_REJECTED"
+
+	TypeRoomClosed = "ROOM_CLOSED"
+
+	TypeLoadScenario = "LOAD_SCENARIO"
+
+	TypeRequestTracker = "REQUEST_TRACKER"
+	TypeCardTracker    = "CARD_TRACKER"
+
+	TypeAuthenticate  = "AUTHENTICATE"
+	TypeAuthenticated = "AUTHENTICATED"
+
+	TypeRequestStats = "REQUEST_STATS"
+	TypePlayerStats  = "PLAYER_STATS"
+
+	TypeRequestHint = "REQUEST_HINT"
+	TypeHint        = "HINT"
+	TypeCoachReview = "COACH_REVIEW"
 )
 
 // RoomHandler handles room-related WebSocket messages
 type RoomHandler struct {
 	roomService *services.RoomService
 	// Map of room code to connections
-	roomConnections map[string]map[*websocket.Conn]bool
+	roomConnections map[string]map[Session]bool
 	// Map of connection to player info
-	connInfo map[*websocket.Conn]*ConnectionInfo
+	connInfo map[Session]*ConnectionInfo
 	// Map of room code to game instance
-	games map[string]*models.Game
+	games map[string]*engine.Game
+	// Strategy used for seats taken over by bots
+	botStrategy bot.Strategy
+	// Suggests moves and reviews them at coached tables
+	coach *bot.Coach
+	// Map of room code to the coach's reviews of the current round, for coached tables
+	coachNotes map[string]*coachRound
+	// Map of player ID to the timer releasing their held seat
+	seatTimers map[string]*time.Timer
+	// Map of room code to the most recent undoable action
+	undo map[string]*undoEntry
+	// Map of room code to when a message was last handled for it
+	lastActivity map[string]time.Time
+	// Map of room code to the timer for the current turn
+	turnTimers map[string]*turnTimer
+	// Map of room code to the turn a bot is searching for outside the lock
+	botSearches map[string]turnKey
+	// Map of room code to when the current round was dealt
+	roundStarted map[string]time.Time
+	// Map of room code to the bots the last scenario loaded there added to the room
+	scenarioBots map[string][]string
+	// Registers players and checks their session tokens
+	accounts *accounts.Service
+	// Map of connection to the account it signed in as; guests have no entry
+	signedIn map[Session]*accounts.Account
+	// Lifetime statistics and match histories
+	stats *stats.Store
+	// Map of room code to the match being followed for statistics and replays
+	matches map[string]*stats.Match
+	// Map of remote address to its open WebSocket count
+	connsPerIP map[string]int
+	// Map of remote address to its register and login requests this minute
+	loginsPerIP map[string]*loginCount
+	opts        Options
+	upgrader    websocket.Upgrader
+	// Every open session, so a shutdown can reach connections not yet in a room
+	sessions map[Session]bool
+	// Set once a shutdown has begun: no new rooms or connections
+	draining bool
+	// Set once connections are being closed for shutdown: disconnects no longer count as departures
+	closing bool
+	// Logs with room, player and connection context
+	log *slog.Logger
+	// Type of the message being handled, attached to logs written while handling it
+	msgType string
+	// Serializes message handling with seat-hold, undo and lifecycle timers
+	mu sync.Mutex
 }
 
 // ConnectionInfo stores player info for a connection
@@ -47,116 +133,277 @@ type ConnectionInfo struct {
 	PlayerID string
 }
 
-// NewRoomHandler creates a new room handler
+// NewRoomHandler creates a new room handler with the default options
 func NewRoomHandler() *RoomHandler {
+	return NewRoomHandlerWithOptions(DefaultOptions())
+}
+
+// NewRoomHandlerWithOptions creates a new room handler
+func NewRoomHandlerWithOptions(opts Options) *RoomHandler {
+	roomService := services.NewRoomService()
+	roomService.SetMaxRooms(opts.MaxRooms)
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if opts.Accounts == nil {
+		opts.Accounts = accounts.NewService(storage.NewMemoryStore(), nil, 0)
+	}
+	if opts.Stats == nil {
+		opts.Stats = stats.NewStore(storage.NewMemoryStore())
+	}
+
 	return &RoomHandler{
-		roomService:     services.NewRoomService(),
-		roomConnections: make(map[string]map[*websocket.Conn]bool),
-		connInfo:        make(map[*websocket.Conn]*ConnectionInfo),
-		games:           make(map[string]*models.Game),
+		roomService:     roomService,
+		roomConnections: make(map[string]map[Session]bool),
+		connInfo:        make(map[Session]*ConnectionInfo),
+		games:           make(map[string]*engine.Game),
+		botStrategy:     bot.NewHeuristic(),
+		coach:           bot.NewCoach(),
+		coachNotes:      make(map[string]*coachRound),
+		seatTimers:      make(map[string]*time.Timer),
+		undo:            make(map[string]*undoEntry),
+		lastActivity:    make(map[string]time.Time),
+		turnTimers:      make(map[string]*turnTimer),
+		botSearches:     make(map[string]turnKey),
+		roundStarted:    make(map[string]time.Time),
+		scenarioBots:    make(map[string][]string),
+		accounts:        opts.Accounts,
+		signedIn:        make(map[Session]*accounts.Account),
+		stats:           opts.Stats,
+		matches:         make(map[string]*stats.Match),
+		connsPerIP:      make(map[string]int),
+		loginsPerIP:     make(map[string]*loginCount),
+		sessions:        make(map[Session]bool),
+		opts:            opts,
+		upgrader:        newUpgrader(opts.AllowedOrigins),
+		log:             logger,
 	}
 }
 
 // HandleWebSocket upgrades HTTP connection to WebSocket and handles room messages
 func (h *RoomHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		log.Printf("Failed to upgrade connection: %v", err)
+	if h.Draining() {
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
 		return
 	}
 
-	// Send welcome message
-	welcomeMsg := map[string]interface{}{
-		"type":    "connected",
-		"message": "Successfully connected to server",
+	ip := remoteIP(r)
+	if !h.acquireConn(ip) {
+		http.Error(w, "too many connections", http.StatusTooManyRequests)
+		return
 	}
-	if err := conn.WriteJSON(welcomeMsg); err != nil {
-		log.Printf("Failed to send welcome message: %v", err)
-		conn.Close()
+	defer h.releaseConn(ip)
+
+	// A session token signs the connection in; without one the player is a guest
+	var account *accounts.Account
+	if token := requestToken(r); token != "" {
+		var err error
+		if account, err = h.accounts.Authenticate(token); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.log.Warn("websocket upgrade failed", "remote", ip, "error", err)
 		return
 	}
 
+	c := newClient(conn, h.log.With("remote", ip))
+	c.configureReads()
+	go c.writePump()
+
+	h.ConnectAs(c, account)
+	c.log.Debug("client connected")
+
 	// Handle disconnection
 	defer func() {
-		h.handleDisconnect(conn)
-		conn.Close()
-		log.Printf("Client disconnected")
+		h.Disconnect(c)
+		c.Close()
+		c.log.Debug("client disconnected")
 	}()
 
-	// Listen for messages
+	// Listen for messages; the read deadline is extended by every message and pong
 	for {
 		_, msgBytes, err := conn.ReadMessage()
 		if err != nil {
-			log.Printf("Read error: %v", err)
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				c.log.Info("read failed", "error", err)
+			}
 			break
 		}
+		conn.SetReadDeadline(time.Now().Add(pongWait))
 
-		var msg map[string]interface{}
-		if err := json.Unmarshal(msgBytes, &msg); err != nil {
-			h.sendError(conn, "Invalid message format")
-			continue
-		}
+		h.HandleMessage(c, msgBytes)
+	}
+}
+
+// Connect greets a newly connected guest session
+func (h *RoomHandler) Connect(sess Session) {
+	h.ConnectAs(sess, nil)
+}
+
+// ConnectAs greets a newly connected session signed in as the account, or as a guest when it is nil
+func (h *RoomHandler) ConnectAs(sess Session, account *accounts.Account) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sessions[sess] = true
+
+	welcomeMsg := map[string]interface{}{
+		"type":    "connected",
+		"message": "Successfully connected to server",
+	}
+	if account != nil {
+		h.signedIn[sess] = account
+		welcomeMsg["account"] = account.Public()
+	}
+	sess.Send(welcomeMsg)
+}
+
+// HandleMessage handles one raw message received from a session
+func (h *RoomHandler) HandleMessage(sess Session, msgBytes []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handleRawMessage(sess, msgBytes)
+}
 
-		msgType, ok := msg["type"].(string)
-		if !ok {
-			h.sendError(conn, "Message type is required")
-			continue
+// Disconnect applies the departure rules for a session whose transport has gone away
+func (h *RoomHandler) Disconnect(sess Session) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.sessions, sess)
+	delete(h.signedIn, sess)
+	if h.closing {
+		if info, ok := h.connInfo[sess]; ok {
+			h.removeConnection(sess, info.RoomCode)
 		}
+		return
+	}
+	h.handleDisconnect(sess)
+}
+
+func (h *RoomHandler) handleRawMessage(sess Session, msgBytes []byte) {
+	var msg map[string]interface{}
+	if err := json.Unmarshal(msgBytes, &msg); err != nil {
+		h.sendError(sess, ErrCodeBadRequest, "Invalid message format")
+		return
+	}
+
+	msgType, ok := msg["type"].(string)
+	if !ok {
+		h.sendError(sess, ErrCodeBadRequest, "Message type is required")
+		return
+	}
 
-		h.handleMessage(conn, msgType, msg)
+	h.handleMessage(sess, msgType, msg)
+
+	if info, ok := h.connInfo[sess]; ok {
+		h.touch(info.RoomCode)
 	}
 }
 
-func (h *RoomHandler) handleMessage(conn *websocket.Conn, msgType string, msg map[string]interface{}) {
+func (h *RoomHandler) handleMessage(sess Session, msgType string, msg map[string]interface{}) {
+	label := msgType
+	if !knownMessageTypes[msgType] {
+		label = "unknown" // Keeps label values bounded whatever clients send
+	}
+	start := time.Now()
+	h.msgType = label
+	defer func() {
+		elapsed := time.Since(start)
+		metrics.MessagesHandled.Inc(label)
+		metrics.MessageDuration.Observe(label, elapsed.Seconds())
+		h.sessionLog(sess).Debug("message handled", "duration", elapsed)
+		h.msgType = ""
+	}()
+
 	switch msgType {
 	case TypeCreateRoom:
-		h.handleCreateRoom(conn, msg)
+		h.handleCreateRoom(sess, msg)
 	case TypeJoinRoom:
-		h.handleJoinRoom(conn, msg)
+		h.handleJoinRoom(sess, msg)
 	case TypeLeaveRoom:
-		h.handleLeaveRoom(conn, msg)
+		h.handleLeaveRoom(sess, msg)
 	case TypeStartGame:
-		h.handleStartGame(conn, msg)
+		h.handleStartGame(sess, msg)
 	case TypePlayCards:
-		h.handlePlayCards(conn, msg)
+		h.handlePlayCards(sess, msg)
 	case TypeFlipFaceDown:
-		h.handleFlipFaceDown(conn, msg)
+		h.handleFlipFaceDown(sess, msg)
+	case TypePickupPile:
+		h.handlePickupPile(sess, msg)
 	case TypeNextRound:
-		h.handleNextRound(conn, msg)
+		h.handleNextRound(sess, msg)
+	case TypeEndGame:
+		h.handleEndGame(sess, msg)
+	case TypeRejoinRoom:
+		h.handleRejoinRoom(sess, msg)
+	case TypeUpdateSettings:
+		h.handleUpdateSettings(sess, msg)
+	case TypeRequestUndo:
+		h.handleRequestUndo(sess, msg)
+	case TypeUndoVote:
+		h.handleUndoVote(sess, msg)
+	case TypeLoadScenario:
+		h.handleLoadScenario(sess, msg)
+	case TypeRequestTracker:
+		h.handleRequestTracker(sess, msg)
+	case TypeRequestHint:
+		h.handleRequestHint(sess, msg)
+	case TypeAuthenticate:
+		h.handleAuthenticate(sess, msg)
+	case TypeRequestStats:
+		h.handleRequestStats(sess, msg)
 	default:
-		h.sendError(conn, "Unknown message type")
+		h.sendError(sess, ErrCodeBadRequest, "Unknown message type")
 	}
 }
 
-func (h *RoomHandler) handleCreateRoom(conn *websocket.Conn, msg map[string]interface{}) {
-	playerName, ok := msg["playerName"].(string)
-	if !ok || playerName == "" {
-		h.sendError(conn, "Player name is required")
+func (h *RoomHandler) handleCreateRoom(sess Session, msg map[string]interface{}) {
+	playerName, _ := msg["playerName"].(string)
+	seat := h.seatFor(sess, playerName)
+	if seat.Name == "" {
+		h.sendError(sess, ErrCodeBadRequest, "Player name is required")
 		return
 	}
 
-	room, playerID, err := h.roomService.CreateRoom(playerName)
+	if h.draining {
+		h.sendError(sess, ErrCodeUnavailable, "Server is shutting down; new rooms cannot be created")
+		return
+	}
+
+	room, err := h.roomService.CreateRoomAs(seat)
 	if err != nil {
-		h.sendError(conn, err.Error())
+		h.sendErr(sess, err)
 		return
 	}
+	playerID := seat.ID
+
+	// A recycled room code must not pick up anything left behind by its previous room
+	delete(h.games, room.Code)
+	delete(h.undo, room.Code)
+	h.stopTurnTimer(room.Code)
+
+	settings := room.GetSettings()
+	settings.SeatHoldDuration = h.opts.SeatHold
+	settings.Rules = h.opts.DefaultRules
+	settings.Testing, _ = msg["testing"].(bool)
+	room.SetSettings(settings)
 
 	// Store connection info
-	h.connInfo[conn] = &ConnectionInfo{
+	h.connInfo[sess] = &ConnectionInfo{
 		RoomCode: room.Code,
 		PlayerID: playerID,
 	}
 
 	// Add connection to room
 	if h.roomConnections[room.Code] == nil {
-		h.roomConnections[room.Code] = make(map[*websocket.Conn]bool)
-	}
-	h.roomConnections[room.Code][conn] = true
-
-	// Update player connection in room
-	if player, ok := room.GetPlayer(playerID); ok {
-		player.Connection = conn
+		h.roomConnections[room.Code] = make(map[Session]bool)
 	}
+	h.roomConnections[room.Code][sess] = true
+	h.sessionLog(sess).Info("room created")
 
 	// Send response
 	response := map[string]interface{}{
@@ -165,50 +412,47 @@ func (h *RoomHandler) handleCreateRoom(conn *websocket.Conn, msg map[string]inte
 		"playerId": playerID,
 		"room":     h.serializeRoom(room),
 	}
-	conn.WriteJSON(response)
+	sess.Send(response)
 }
 
-func (h *RoomHandler) handleJoinRoom(conn *websocket.Conn, msg map[string]interface{}) {
+func (h *RoomHandler) handleJoinRoom(sess Session, msg map[string]interface{}) {
 	roomCode, ok := msg["roomCode"].(string)
 	if !ok || roomCode == "" {
-		h.sendError(conn, "Room code is required")
+		h.sendError(sess, ErrCodeBadRequest, "Room code is required")
 		return
 	}
 
-	playerName, ok := msg["playerName"].(string)
-	if !ok || playerName == "" {
-		h.sendError(conn, "Player name is required")
+	playerName, _ := msg["playerName"].(string)
+	seat := h.seatFor(sess, playerName)
+	if seat.Name == "" {
+		h.sendError(sess, ErrCodeBadRequest, "Player name is required")
 		return
 	}
 
-	playerID, err := h.roomService.JoinRoom(roomCode, playerName)
-	if err != nil {
-		h.sendError(conn, err.Error())
+	if err := h.roomService.JoinRoomAs(roomCode, seat); err != nil {
+		h.sendErr(sess, err)
 		return
 	}
+	playerID, playerName := seat.ID, seat.Name
 
 	room := h.roomService.GetRoom(roomCode)
 	if room == nil {
-		h.sendError(conn, "Room not found")
+		h.sendError(sess, ErrCodeNotFound, "Room not found")
 		return
 	}
 
 	// Store connection info
-	h.connInfo[conn] = &ConnectionInfo{
+	h.connInfo[sess] = &ConnectionInfo{
 		RoomCode: roomCode,
 		PlayerID: playerID,
 	}
 
 	// Add connection to room
 	if h.roomConnections[roomCode] == nil {
-		h.roomConnections[roomCode] = make(map[*websocket.Conn]bool)
-	}
-	h.roomConnections[roomCode][conn] = true
-
-	// Update player connection in room
-	if player, ok := room.GetPlayer(playerID); ok {
-		player.Connection = conn
+		h.roomConnections[roomCode] = make(map[Session]bool)
 	}
+	h.roomConnections[roomCode][sess] = true
+	h.sessionLog(sess).Info("player joined")
 
 	// Send response to joining player
 	response := map[string]interface{}{
@@ -216,7 +460,7 @@ func (h *RoomHandler) handleJoinRoom(conn *websocket.Conn, msg map[string]interf
 		"playerId": playerID,
 		"room":     h.serializeRoom(room),
 	}
-	conn.WriteJSON(response)
+	sess.Send(response)
 
 	// Broadcast to other players in room
 	broadcast := map[string]interface{}{
@@ -225,100 +469,97 @@ func (h *RoomHandler) handleJoinRoom(conn *websocket.Conn, msg map[string]interf
 		"playerId":   playerID,
 		"room":       h.serializeRoom(room),
 	}
-	h.broadcastToRoom(roomCode, broadcast, conn)
+	h.broadcastToRoom(roomCode, broadcast, sess)
 }
 
-func (h *RoomHandler) handleLeaveRoom(conn *websocket.Conn, msg map[string]interface{}) {
+func (h *RoomHandler) handleLeaveRoom(sess Session, msg map[string]interface{}) {
 	roomCode, ok := msg["roomCode"].(string)
 	if !ok {
-		h.sendError(conn, "Room code is required")
+		h.sendError(sess, ErrCodeBadRequest, "Room code is required")
 		return
 	}
 
 	playerID, ok := msg["playerId"].(string)
 	if !ok {
-		h.sendError(conn, "Player ID is required")
+		h.sendError(sess, ErrCodeBadRequest, "Player ID is required")
 		return
 	}
 
 	room := h.roomService.GetRoom(roomCode)
 	if room == nil {
-		h.sendError(conn, "Room not found")
+		h.sendError(sess, ErrCodeNotFound, "Room not found")
 		return
 	}
 
 	player, ok := room.GetPlayer(playerID)
 	if !ok {
-		h.sendError(conn, "Player not in room")
+		h.sendError(sess, ErrCodeNotFound, "Player not in room")
 		return
 	}
-	playerName := player.Name
 
-	err := h.roomService.LeaveRoom(roomCode, playerID)
-	if err != nil {
-		h.sendError(conn, err.Error())
-		return
-	}
-
-	// Remove connection from room
-	if connections, exists := h.roomConnections[roomCode]; exists {
-		delete(connections, conn)
-		if len(connections) == 0 {
-			delete(h.roomConnections, roomCode)
-		}
-	}
-	delete(h.connInfo, conn)
-
-	// Broadcast to remaining players
-	broadcast := map[string]interface{}{
-		"type":       TypePlayerLeft,
-		"playerName": playerName,
-		"playerId":   playerID,
-		"room":       h.serializeRoom(room),
-	}
-	h.broadcastToRoom(roomCode, broadcast, nil)
+	h.departPlayer(sess, room, player)
 }
 
-func (h *RoomHandler) handleStartGame(conn *websocket.Conn, msg map[string]interface{}) {
+func (h *RoomHandler) handleStartGame(sess Session, msg map[string]interface{}) {
 	roomCode, ok := msg["roomCode"].(string)
 	if !ok {
-		h.sendError(conn, "Room code is required")
+		h.sendError(sess, ErrCodeBadRequest, "Room code is required")
 		return
 	}
 
 	playerID, ok := msg["playerId"].(string)
 	if !ok {
-		h.sendError(conn, "Player ID is required")
+		h.sendError(sess, ErrCodeBadRequest, "Player ID is required")
 		return
 	}
 
 	room := h.roomService.GetRoom(roomCode)
 	if room == nil {
-		h.sendError(conn, "Room not found")
+		h.sendError(sess, ErrCodeNotFound, "Room not found")
 		return
 	}
 
 	// Check if player is host
 	if room.GetHostID() != playerID {
-		h.sendError(conn, "Only the host can start the game")
+		h.sendError(sess, ErrCodeForbidden, "Only the host can start the game")
 		return
 	}
 
 	// Check minimum players
 	if room.GetPlayerCount() < services.MinPlayers {
-		h.sendError(conn, "Need at least 3 players to start")
+		h.sendError(sess, ErrCodeWrongPhase, fmt.Sprintf("Need at least %d players to start", services.MinPlayers))
 		return
 	}
 
 	// Convert room players to slice in join order for deterministic turn/dealer rotation
 	players := room.GetPlayersInOrder()
 
+	// Resolve decks and deal sizes for the chosen variant and table size
+	settings := room.GetSettings()
+	deal, err := engine.ResolveDealConfig(settings.Variant, settings.Deal, len(players))
+	if err != nil {
+		h.sendErr(sess, err)
+		return
+	}
+
 	// Start the game - this creates deck, shuffles, and deals cards
-	game := services.StartGame(players)
+	game, err := engine.StartGameWithConfig(players, deal)
+	if err != nil {
+		h.sendErr(sess, err)
+		return
+	}
 	game.RoomCode = roomCode
+	game.Rules = settings.Rules
+	game.Scoring = settings.Scoring
+	game.StalemateActions = settings.StalemateActions
 
 	// Store game instance
+	h.startMatch(room, game)
 	h.games[roomCode] = game
+	delete(h.coachNotes, roomCode)
+	h.roundStarted[roomCode] = time.Now()
+	metrics.GamesStarted.Inc("")
+	h.roomLog(roomCode).Info("game started", "players", len(players), "decks", deal.Decks, "rules", game.Rules.Name)
 
 	// Broadcast game started to all players with game state
 	broadcast := map[string]interface{}{
@@ -326,10 +567,11 @@ func (h *RoomHandler) handleStartGame(conn *websocket.Conn, msg map[string]inter
 		"game": h.serializeGame(game),
 	}
 	h.broadcastToRoom(roomCode, broadcast, nil)
+	h.scheduleTurn(roomCode, game)
 }
 
-func (h *RoomHandler) handleDisconnect(conn *websocket.Conn) {
-	info, ok := h.connInfo[conn]
+func (h *RoomHandler) handleDisconnect(sess Session) {
+	info, ok := h.connInfo[sess]
 	if !ok {
 		return
 	}
@@ -343,51 +585,38 @@ func (h *RoomHandler) handleDisconnect(conn *websocket.Conn) {
 	if !ok {
 		return
 	}
-	playerName := player.Name
-
-	// Remove player from room
-	h.roomService.LeaveRoom(info.RoomCode, info.PlayerID)
-
-	// Remove connection from room
-	if connections, exists := h.roomConnections[info.RoomCode]; exists {
-		delete(connections, conn)
-		if len(connections) == 0 {
-			delete(h.roomConnections, info.RoomCode)
-		}
-	}
-	delete(h.connInfo, conn)
 
-	// Broadcast to remaining players
-	broadcast := map[string]interface{}{
-		"type":       TypePlayerLeft,
-		"playerName": playerName,
-		"playerId":   info.PlayerID,
-		"room":       h.serializeRoom(room),
-	}
-	h.broadcastToRoom(info.RoomCode, broadcast, nil)
+	h.departPlayer(sess, room, player)
 }
 
-func (h *RoomHandler) broadcastToRoom(roomCode string, msg map[string]interface{}, exclude *websocket.Conn) {
+func (h *RoomHandler) broadcastToRoom(roomCode string, msg map[string]interface{}, exclude Session) {
 	connections, exists := h.roomConnections[roomCode]
 	if !exists {
 		return
 	}
 
-	for conn := range connections {
-		if conn != exclude {
-			if err := conn.WriteJSON(msg); err != nil {
-				log.Printf("Failed to broadcast to connection: %v", err)
-			}
+	for sess := range connections {
+		if sess != exclude {
+			sess.Send(msg)
 		}
 	}
 }
 
-func (h *RoomHandler) sendError(conn *websocket.Conn, message string) {
+// sendError rejects a request with a message and the code clients and metrics group it by
+func (h *RoomHandler) sendError(sess Session, code, message string) {
+	metrics.Errors.Inc(code)
+	h.sessionLog(sess).Info("request rejected", "code", code, "error", message)
 	response := map[string]interface{}{
 		"type":    TypeError,
 		"message": message,
+		"code":    code,
 	}
-	conn.WriteJSON(response)
+	sess.Send(response)
+}
+
+// sendErr rejects a request with an error from the rules, rooms or accounts
+func (h *RoomHandler) sendErr(sess Session, err error) {
+	h.sendError(sess, errorCode(err), err.Error())
 }
 
 func (h *RoomHandler) serializeRoom(room *models.Room) map[string]interface{} {
@@ -397,10 +626,17 @@ func (h *RoomHandler) serializeRoom(room *models.Room) map[string]interface{} {
 
 	players := make([]map[string]interface{}, 0, room.GetPlayerCount())
 	for _, player := range room.GetPlayersInOrder() {
-		players = append(players, map[string]interface{}{
-			"id":   player.ID,
-			"name": player.Name,
-		})
+		seat := map[string]interface{}{
+			"id":         player.ID,
+			"name":       player.Name,
+			"isBot":      player.IsBot,
+			"away":       player.Away,
+			"registered": player.Registered,
+		}
+		if player.Registered {
+			h.addRating(seat, player.ID)
+		}
+		players = append(players, seat)
 	}
 
 	return map[string]interface{}{
@@ -408,10 +644,11 @@ func (h *RoomHandler) serializeRoom(room *models.Room) map[string]interface{} {
 		"hostId":      room.GetHostID(),
 		"players":     players,
 		"playerCount": room.GetPlayerCount(),
+		"settings":    h.serializeSettings(room.GetSettings()),
 	}
 }
 
-func (h *RoomHandler) serializeGame(game *models.Game) map[string]interface{} {
+func (h *RoomHandler) serializeGame(game *engine.Game) map[string]interface{} {
 	if game == nil {
 		return nil
 	}
@@ -454,6 +691,8 @@ func (h *RoomHandler) serializeGame(game *models.Game) map[string]interface{} {
 			"hand":           hand,
 			"tableCardsUp":   tableUp,
 			"tableCardsDown": tableDown,
+			"isBot":          player.IsBot,
+			"away":           player.Away,
 		})
 	}
 
@@ -467,12 +706,19 @@ func (h *RoomHandler) serializeGame(game *models.Game) map[string]interface{} {
 		})
 	}
 
-	return map[string]interface{}{
+	serialized := map[string]interface{}{
 		"players":            players,
 		"centerPile":         centerPile,
 		"discardCount":       len(game.DiscardPile),
 		"currentPlayerIndex": game.CurrentPlayerIndex,
 		"dealerIndex":        game.DealerIndex,
 		"round":              game.Round,
+		"rules":              game.ActiveRules(),
+		"scoring":            game.ActiveScoring(),
+	}
+	// Casual tables may show the card tracker with every update
+	if room := h.roomService.GetRoom(game.RoomCode); room != nil && room.GetSettings().ShowTracker {
+		serialized["tracker"] = engine.TrackCards(game)
 	}
+	return serialized
 }
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/models"
+	"github.com/thben/clearthedeck/internal/scenario"
+	"github.com/thben/clearthedeck/internal/services"
+)
+
+// handleLoadScenario lets the host of a testing room replace the table with a scenario's position
+// The scenario is YAML or JSON text, or the same fields as an object. Room players keep their
+// seats by name; any other scenario player joins as a bot, and leaves again when the next scenario is loaded.
+func (h *RoomHandler) handleLoadScenario(sess Session, msg map[string]interface{}) {
+	connInfo, ok := h.connInfo[sess]
+	if !ok {
+		h.sendError(sess, ErrCodeNotFound, "Connection not registered")
+		return
+	}
+
+	room := h.roomService.GetRoom(connInfo.RoomCode)
+	if room == nil {
+		h.sendError(sess, ErrCodeNotFound, "Room not found")
+		return
+	}
+
+	if room.GetHostID() != connInfo.PlayerID {
+		h.sendError(sess, ErrCodeForbidden, "Only the host can load a scenario")
+		return
+	}
+
+	settings := room.GetSettings()
+	if !settings.Testing {
+		h.sendError(sess, ErrCodeForbidden, "Scenarios can only be loaded in testing rooms")
+		return
+	}
+
+	s, err := readScenario(msg["scenario"])
+	if err != nil {
+		h.sendError(sess, ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	previous := make(map[string]bool)
+	for _, id := range h.scenarioBots[room.Code] {
+		previous[id] = true
+	}
+	seated, err := seatScenario(room, s, previous)
+	if err != nil {
+		h.sendError(sess, ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	game, err := s.Game()
+	if err != nil {
+		h.sendError(sess, ErrCodeBadRequest, err.Error())
+		return
+	}
+	if s.Rules == "" {
+		game.Rules = settings.Rules
+	}
+	game.RoomCode = room.Code
+	game.Scoring = settings.Scoring
+	game.StalemateActions = settings.StalemateActions
+
+	// Room players sit in their own seats so the room and the game share them
+	for id := range previous {
+		room.RemovePlayer(id)
+	}
+	var bots []string
+	for i, p := range game.Players {
+		roomPlayer, ok := seated[p.ID]
+		if !ok {
+			p.JoinedAt = time.Now()
+			room.AddPlayer(p)
+			bots = append(bots, p.ID)
+			continue
+		}
+		roomPlayer.Hand = p.Hand
+		roomPlayer.TableCardsUp = p.TableCardsUp
+		roomPlayer.TableCardsDown = p.TableCardsDown
+		roomPlayer.RoundScore = p.RoundScore
+		roomPlayer.TotalScore = p.TotalScore
+		roomPlayer.FlipMisses = 0
+		game.Players[i] = roomPlayer
+	}
+
+	// Nothing from the previous table carries over
+	h.clearUndo(room.Code, undoSuperseded)
+	h.stopTurnTimer(room.Code)
+	h.endMatch(room.Code)
+	h.games[room.Code] = game
+	h.scenarioBots[room.Code] = bots
+	delete(h.coachNotes, room.Code)
+	h.roundStarted[room.Code] = time.Now()
+	h.roomLog(room.Code).Info("scenario loaded", "scenario", s.Name, "players", len(game.Players))
+
+	h.broadcastToRoom(room.Code, map[string]interface{}{
+		"type":     TypeGameStarted,
+		"game":     h.serializeGame(game),
+		"room":     h.serializeRoom(room),
+		"scenario": s.Name,
+	}, nil)
+	h.scheduleTurn(room.Code, game)
+	h.playBotTurns(room.Code, game)
+}
+
+// readScenario parses a scenario sent as text or as an object
+func readScenario(raw interface{}) (*scenario.Scenario, error) {
+	var data []byte
+	switch v := raw.(type) {
+	case string:
+		data = []byte(v)
+	case map[string]interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid scenario: %w", err)
+		}
+		data = encoded
+	default:
+		return nil, errors.New("Scenario is required")
+	}
+	s, err := scenario.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid scenario: %w", err)
+	}
+	return s, nil
+}
+
+// seatScenario gives each scenario player the ID of the room player with the same name, or a new
+// bot ID, and returns the room players by ID. Every room player must have a seat in the scenario,
+// apart from the bots a previous scenario added, which give up theirs.
+func seatScenario(room *models.Room, s *scenario.Scenario, previous map[string]bool) (map[string]*engine.Player, error) {
+	byName := make(map[string]*engine.Player)
+	for _, p := range room.GetPlayersInOrder() {
+		if !previous[p.ID] {
+			byName[p.Name] = p
+		}
+	}
+	if len(s.Players) > services.MaxPlayers {
+		return nil, fmt.Errorf("Invalid scenario: at most %d players can be seated", services.MaxPlayers)
+	}
+
+	seated := make(map[string]*engine.Player)
+	for i := range s.Players {
+		seat := &s.Players[i]
+		if p, ok := byName[seat.Name]; ok {
+			seat.ID = p.ID
+			seat.Bot = p.IsBot
+			seated[p.ID] = p
+			delete(byName, seat.Name)
+			continue
+		}
+		seat.ID = uuid.New().String()
+		seat.Bot = true
+	}
+
+	for _, p := range room.GetPlayersInOrder() {
+		if _, ok := byName[p.Name]; ok {
+			return nil, fmt.Errorf("Invalid scenario: %s has no seat", p.Name)
+		}
+	}
+	return seated, nil
+}
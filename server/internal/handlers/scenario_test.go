@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const threeKings = `
+name: three kings
+current: Bob
+pile: [KD, KC, KH]
+players:
+  - name: Alice
+    hand: [5H, 9C]
+    faceUp: [KS]
+    faceDown: [2C]
+  - name: Bob
+    hand: [KS, 3D]
+  - name: Carol
+    hand: [4C]
+`
+
+// openRoom creates a room hosted by Alice with Bob joined, returning their sessions and the room code
+func openRoom(t *testing.T, h *RoomHandler, testingRoom bool) (*MemorySession, *MemorySession, string) {
+	host := NewMemorySession("alice")
+	h.Connect(host)
+	deliver(t, h, host, map[string]interface{}{"type": "CREATE_ROOM", "playerName": "Alice", "testing": testingRoom})
+	created := host.Last(TypeRoomCreated)
+	require.NotNil(t, created)
+	roomCode := created["roomCode"].(string)
+
+	guest := NewMemorySession("bob")
+	h.Connect(guest)
+	deliver(t, h, guest, map[string]interface{}{"type": "JOIN_ROOM", "roomCode": roomCode, "playerName": "Bob"})
+	require.NotNil(t, guest.Last(TypeRoomJoined))
+	return host, guest, roomCode
+}
+
+func TestLoadScenario(t *testing.T) {
+	t.Run("Host sets up the table in a testing room", func(t *testing.T) {
+		h := NewRoomHandler()
+		host, guest, roomCode := openRoom(t, h, true)
+
+		deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": threeKings})
+		started := guest.Last(TypeGameStarted)
+		require.NotNil(t, started)
+		assert.Equal(t, "three kings", started["scenario"])
+
+		game := h.games[roomCode]
+		require.NotNil(t, game)
+		room := h.roomService.GetRoom(roomCode)
+		assert.Equal(t, 3, room.GetPlayerCount(), "Carol joins as a bot")
+		assert.True(t, game.Players[2].IsBot)
+
+		// Room players keep their IDs and share their seats with the game
+		bob := game.Players[1]
+		roomBob, ok := room.GetPlayer(bob.ID)
+		require.True(t, ok)
+		assert.Same(t, roomBob, bob)
+		assert.Equal(t, bob, game.GetCurrentPlayer())
+
+		// Bob plays the fourth king and clears the pile
+		deliver(t, h, guest, map[string]interface{}{"type": TypePlayCards, "cardIds": []string{bob.ID + "-hand-0"}})
+		assert.Nil(t, guest.Last(TypeError))
+		assert.Empty(t, game.CenterPile)
+		assert.Len(t, game.DiscardPile, 4)
+	})
+
+	t.Run("Scenario can be sent as an object", func(t *testing.T) {
+		h := NewRoomHandler()
+		host, _, roomCode := openRoom(t, h, true)
+
+		deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": map[string]interface{}{
+			"phase": "afterPickup",
+			"players": []interface{}{
+				map[string]interface{}{"name": "Alice", "hand": []string{"7H", "2C"}},
+				map[string]interface{}{"name": "Bob", "hand": []string{"3S"}},
+			},
+		}})
+		require.Nil(t, host.Last(TypeError))
+		assert.True(t, h.games[roomCode].AfterPickup)
+	})
+
+	t.Run("Only testing rooms accept scenarios", func(t *testing.T) {
+		h := NewRoomHandler()
+		host, _, _ := openRoom(t, h, false)
+
+		deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": threeKings})
+		rejected := host.Last(TypeError)
+		require.NotNil(t, rejected)
+		assert.Equal(t, ErrCodeForbidden, rejected["code"])
+	})
+
+	t.Run("Only the host may load a scenario", func(t *testing.T) {
+		h := NewRoomHandler()
+		_, guest, _ := openRoom(t, h, true)
+
+		deliver(t, h, guest, map[string]interface{}{"type": TypeLoadScenario, "scenario": threeKings})
+		rejected := guest.Last(TypeError)
+		require.NotNil(t, rejected)
+		assert.Equal(t, ErrCodeForbidden, rejected["code"])
+	})
+
+	t.Run("Every room player needs a seat", func(t *testing.T) {
+		h := NewRoomHandler()
+		host, _, roomCode := openRoom(t, h, true)
+
+		deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": `
+players:
+  - {name: Alice, hand: [5H]}
+  - {name: Dave, hand: [6H]}
+`})
+		rejected := host.Last(TypeError)
+		require.NotNil(t, rejected)
+		assert.Contains(t, rejected["message"], "Bob has no seat")
+		assert.Equal(t, ErrCodeBadRequest, rejected["code"])
+		assert.Nil(t, h.games[roomCode])
+	})
+
+	t.Run("A scenario's bots leave when the next one is loaded", func(t *testing.T) {
+		h := NewRoomHandler()
+		host, _, roomCode := openRoom(t, h, true)
+		room := h.roomService.GetRoom(roomCode)
+
+		deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": threeKings})
+		require.Nil(t, host.Last(TypeError))
+		carol := h.games[roomCode].Players[2]
+		require.Equal(t, 3, room.GetPlayerCount())
+
+		// A smaller table no longer has a seat for Carol
+		deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": `
+players:
+  - {name: Alice, hand: [5H]}
+  - {name: Bob, hand: [6H]}
+`})
+		require.Nil(t, host.Last(TypeError))
+		assert.Equal(t, 2, room.GetPlayerCount())
+		_, ok := room.GetPlayer(carol.ID)
+		assert.False(t, ok, "Carol has left the room")
+		assert.Len(t, h.games[roomCode].Players, 2)
+
+		deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": threeKings})
+		require.Nil(t, host.Last(TypeError))
+		assert.Equal(t, 3, room.GetPlayerCount(), "bots do not pile up")
+	})
+
+	t.Run("Broken scenarios are rejected", func(t *testing.T) {
+		h := NewRoomHandler()
+		host, _, _ := openRoom(t, h, true)
+
+		deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": "players: [{name: Alice, hand: [1Z]}]"})
+		rejected := host.Last(TypeError)
+		require.NotNil(t, rejected)
+		assert.Equal(t, ErrCodeBadRequest, rejected["code"])
+	})
+}
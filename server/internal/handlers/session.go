@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Session is one connected peer as seen by the room handler
+// It hides the transport, so rooms and games only ever deal in player IDs
+type Session interface {
+	// ID uniquely identifies the session for logging
+	ID() string
+	// Send queues a message for the peer and reports whether it was accepted
+	Send(msg interface{}) bool
+	// Close ends the session; the transport reports the disconnect back to the handler
+	Close()
+	// CloseWith ends the session telling the peer why, using a WebSocket close code
+	CloseWith(code int, reason string)
+}
+
+// MemorySession is an in-memory Session that records what it is sent
+// It lets the room handler be driven without any network I/O
+type MemorySession struct {
+	id        string
+	mu        sync.Mutex
+	messages  []map[string]interface{}
+	closed    bool
+	closeCode int
+}
+
+// NewMemorySession creates an in-memory session with the given ID
+func NewMemorySession(id string) *MemorySession {
+	return &MemorySession{id: id}
+}
+
+// ID returns the session ID
+func (s *MemorySession) ID() string {
+	return s.id
+}
+
+// Send records the message as the JSON object a peer would receive
+func (s *MemorySession) Send(msg interface{}) bool {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return false
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.messages = append(s.messages, decoded)
+	return true
+}
+
+// Close marks the session closed; later messages are refused
+func (s *MemorySession) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+}
+
+// CloseWith marks the session closed and records the close code
+func (s *MemorySession) CloseWith(code int, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	s.closeCode = code
+}
+
+// CloseCode returns the close code the session was closed with, or 0
+func (s *MemorySession) CloseCode() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeCode
+}
+
+// Closed reports whether the session has been closed
+func (s *MemorySession) Closed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// Messages returns everything sent to the session so far
+func (s *MemorySession) Messages() []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]interface{}(nil), s.messages...)
+}
+
+// Last returns the most recent message of the given type, or nil if there is none
+func (s *MemorySession) Last(msgType string) map[string]interface{} {
+	messages := s.Messages()
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i]["type"] == msgType {
+			return messages[i]
+		}
+	}
+	return nil
+}
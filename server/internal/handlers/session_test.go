@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deliver hands a message to the handler as if the session had sent it
+func deliver(t *testing.T, h *RoomHandler, sess Session, msg map[string]interface{}) {
+	data, err := json.Marshal(msg)
+	require.NoError(t, err)
+	h.HandleMessage(sess, data)
+}
+
+func TestRoomHandlerWithMemorySessions(t *testing.T) {
+	h := NewRoomHandler()
+
+	host := NewMemorySession("host")
+	h.Connect(host)
+	assert.Equal(t, "connected", host.Messages()[0]["type"])
+
+	deliver(t, h, host, map[string]interface{}{"type": "CREATE_ROOM", "playerName": "Host"})
+	created := host.Last(TypeRoomCreated)
+	require.NotNil(t, created)
+	roomCode := created["roomCode"].(string)
+
+	guests := make([]*MemorySession, 0, 2)
+	for i := 1; i <= 2; i++ {
+		guest := NewMemorySession(fmt.Sprintf("guest-%d", i))
+		h.Connect(guest)
+		deliver(t, h, guest, map[string]interface{}{
+			"type": "JOIN_ROOM", "roomCode": roomCode, "playerName": fmt.Sprintf("Guest%d", i),
+		})
+		require.NotNil(t, guest.Last(TypeRoomJoined))
+		guests = append(guests, guest)
+	}
+	assert.NotNil(t, host.Last(TypePlayerJoined))
+
+	deliver(t, h, host, map[string]interface{}{
+		"type": "START_GAME", "roomCode": roomCode, "playerId": created["playerId"],
+	})
+	for _, sess := range []*MemorySession{host, guests[0], guests[1]} {
+		require.NotNil(t, sess.Last(TypeGameStarted), "session %s did not see the game start", sess.ID())
+	}
+
+	// Playing out of turn is answered only to the sender
+	before := len(host.Messages())
+	deliver(t, h, guests[1], map[string]interface{}{"type": "PICKUP_PILE"})
+	assert.Equal(t, "not your turn", guests[1].Last(TypeError)["message"])
+	assert.Len(t, host.Messages(), before)
+
+	// Dropping a session applies the room's departure policy without any socket involved
+	h.Disconnect(guests[1])
+	left := host.Last(TypePlayerLeft)
+	require.NotNil(t, left)
+	assert.Equal(t, "hold", left["departurePolicy"])
+}
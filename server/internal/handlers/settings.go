@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/models"
+)
+
+// maxSeatHold caps how long a host may hold a departed player's seat
+const maxSeatHold = time.Hour
+
+// Bounds on the set size a host may choose
+const (
+	minSetSize = 2
+	maxSetSize = 8
+)
+
+// Bounds on the no-progress limit a host may choose
+const (
+	minStalemateActions = 20
+	maxStalemateActions = 2000
+)
+
+// maxRoundLimit caps the rounds a host may set a game to last
+const maxRoundLimit = 100
+
+// maxDealOverride caps each deck or deal-size override a host may set
+const maxDealOverride = 20
+
+// handleUpdateSettings lets the host change the room settings
+func (h *RoomHandler) handleUpdateSettings(sess Session, msg map[string]interface{}) {
+	connInfo, ok := h.connInfo[sess]
+	if !ok {
+		h.sendError(sess, ErrCodeNotFound, "Connection not registered")
+		return
+	}
+
+	room := h.roomService.GetRoom(connInfo.RoomCode)
+	if room == nil {
+		h.sendError(sess, ErrCodeNotFound, "Room not found")
+		return
+	}
+
+	if room.GetHostID() != connInfo.PlayerID {
+		h.sendError(sess, ErrCodeForbidden, "Only the host can change settings")
+		return
+	}
+
+	settings := room.GetSettings()
+
+	if raw, ok := msg["departurePolicy"].(string); ok {
+		policy := models.DeparturePolicy(raw)
+		if !policy.IsValid() {
+			h.sendError(sess, ErrCodeBadRequest, "Unknown departure policy")
+			return
+		}
+		settings.DeparturePolicy = policy
+	}
+
+	if minutes, ok := msg["seatHoldMinutes"].(float64); ok {
+		hold := time.Duration(minutes * float64(time.Minute))
+		if hold <= 0 || hold > maxSeatHold {
+			h.sendError(sess, ErrCodeBadRequest, "Seat hold must be between 0 and 60 minutes")
+			return
+		}
+		settings.SeatHoldDuration = hold
+	}
+
+	if raw, ok := msg["variant"].(string); ok {
+		variant := engine.TableVariant(raw)
+		if !variant.IsValid() {
+			h.sendError(sess, ErrCodeBadRequest, "Unknown table variant")
+			return
+		}
+		settings.Variant = variant
+	}
+
+	if raw, ok := msg["deal"].(map[string]interface{}); ok {
+		deal, err := parseDealOverrides(raw)
+		if err != nil {
+			h.sendError(sess, ErrCodeBadRequest, err.Error())
+			return
+		}
+		settings.Deal = deal
+	}
+
+	if name, ok := msg["rulesPreset"].(string); ok {
+		rules, found := engine.LookupRuleSet(name)
+		if !found {
+			h.sendError(sess, ErrCodeBadRequest, "Unknown rule preset")
+			return
+		}
+		settings.Rules = rules
+	}
+
+	if raw, ok := msg["rules"].(map[string]interface{}); ok {
+		rules, err := parseRuleSet(settings.Rules, raw)
+		if err != nil {
+			h.sendError(sess, ErrCodeBadRequest, err.Error())
+			return
+		}
+		settings.Rules = rules
+	}
+
+	if name, ok := msg["scoringProfile"].(string); ok {
+		profile, found := engine.LookupScoringProfile(name)
+		if !found {
+			h.sendError(sess, ErrCodeBadRequest, "Unknown scoring profile")
+			return
+		}
+		settings.Scoring = profile
+	}
+
+	if raw, ok := msg["stalemateActions"].(float64); ok {
+		if raw < minStalemateActions || raw > maxStalemateActions || raw != float64(int(raw)) {
+			h.sendError(sess, ErrCodeBadRequest, fmt.Sprintf("Stalemate limit must be a whole number between %d and %d", minStalemateActions, maxStalemateActions))
+			return
+		}
+		settings.StalemateActions = int(raw)
+	}
+
+	if raw, ok := msg["roundLimit"].(float64); ok {
+		if raw < 0 || raw > maxRoundLimit || raw != float64(int(raw)) {
+			h.sendError(sess, ErrCodeBadRequest, fmt.Sprintf("Round limit must be a whole number between 0 and %d", maxRoundLimit))
+			return
+		}
+		settings.RoundLimit = int(raw)
+	}
+
+	if show, ok := msg["showTracker"].(bool); ok {
+		settings.ShowTracker = show
+	}
+
+	if coach, ok := msg["coach"].(bool); ok {
+		settings.Coach = coach
+	}
+
+	if raw, ok := msg["botLevel"].(string); ok {
+		level := models.BotLevel(raw)
+		if !level.IsValid() {
+			h.sendError(sess, ErrCodeBadRequest, "Unknown bot level")
+			return
+		}
+		settings.BotLevel = level
+	}
+
+	room.SetSettings(settings)
+
+	broadcast := map[string]interface{}{
+		"type": TypeSettingsUpdated,
+		"room": h.serializeRoom(room),
+	}
+	h.broadcastToRoom(connInfo.RoomCode, broadcast, nil)
+}
+
+func (h *RoomHandler) serializeSettings(settings models.RoomSettings) map[string]interface{} {
+	return map[string]interface{}{
+		"departurePolicy":  string(settings.DeparturePolicy),
+		"seatHoldMinutes":  settings.SeatHoldDuration.Minutes(),
+		"variant":          string(settings.Variant),
+		"rules":            settings.Rules,
+		"scoring":          settings.Scoring,
+		"stalemateActions": settings.StalemateActions,
+		"roundLimit":       settings.RoundLimit,
+		"botLevel":         string(settings.BotLevel),
+		"showTracker":      settings.ShowTracker,
+		"coach":            settings.Coach,
+		"testing":          settings.Testing,
+		"deal": map[string]interface{}{
+			"decks":    settings.Deal.Decks,
+			"faceDown": settings.Deal.FaceDown,
+			"faceUp":   settings.Deal.FaceUp,
+			"hand":     settings.Deal.Hand,
+		},
+	}
+}
+
+// parseDealOverrides reads deck and deal-size overrides; missing or zero fields keep the variant default
+func parseDealOverrides(raw map[string]interface{}) (engine.DealConfig, error) {
+	var deal engine.DealConfig
+	fields := map[string]*int{
+		"decks":    &deal.Decks,
+		"faceDown": &deal.FaceDown,
+		"faceUp":   &deal.FaceUp,
+		"hand":     &deal.Hand,
+	}
+	for key, target := range fields {
+		value, ok := raw[key]
+		if !ok {
+			continue
+		}
+		number, ok := value.(float64)
+		if !ok || number < 0 || number > maxDealOverride || number != float64(int(number)) {
+			return engine.DealConfig{}, fmt.Errorf("deal %s must be a whole number between 0 and %d", key, maxDealOverride)
+		}
+		*target = int(number)
+	}
+	return deal, nil
+}
+
+// parseRuleSet applies the rule fields present in raw on top of the current rules
+func parseRuleSet(current engine.RuleSet, raw map[string]interface{}) (engine.RuleSet, error) {
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return engine.RuleSet{}, fmt.Errorf("invalid rules")
+	}
+
+	rules := current
+	rules.WildRanks = append([]string(nil), current.WildRanks...)
+	if err := json.Unmarshal(encoded, &rules); err != nil {
+		return engine.RuleSet{}, fmt.Errorf("invalid rules")
+	}
+
+	if rules.MinSetSize < minSetSize || rules.MinSetSize > maxSetSize {
+		return engine.RuleSet{}, fmt.Errorf("set size must be between %d and %d", minSetSize, maxSetSize)
+	}
+	for _, rank := range rules.WildRanks {
+		if !engine.IsRank(rank) {
+			return engine.RuleSet{}, fmt.Errorf("unknown wild rank: %s", rank)
+		}
+	}
+
+	// Named presets only describe their exact rules
+	if preset, ok := engine.LookupRuleSet(rules.Name); !ok || !preset.SameRules(rules) {
+		rules.Name = engine.RulesCustom
+		if rules.IsStandard() {
+			rules.Name = engine.RulesStandard
+		}
+	}
+	return rules, nil
+}
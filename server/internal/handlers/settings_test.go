@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/bot"
+	"github.com/thben/clearthedeck/internal/models"
+)
+
+func TestParseRuleSet(t *testing.T) {
+	t.Run("Overrides are applied on top of the current rules", func(t *testing.T) {
+		rules, err := parseRuleSet(engine.DefaultRuleSet(), map[string]interface{}{
+			"minSetSize": float64(3),
+			"wildRanks":  []interface{}{"2", "10"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, rules.MinSetSize)
+		assert.True(t, rules.IsWild("2"))
+		assert.True(t, rules.AllowOverValue)
+		assert.Equal(t, engine.RulesCustom, rules.Name)
+	})
+
+	t.Run("Matching a preset keeps its name", func(t *testing.T) {
+		strict, _ := engine.LookupRuleSet(engine.RulesStrict)
+		rules, err := parseRuleSet(engine.DefaultRuleSet(), map[string]interface{}{
+			"name":                  engine.RulesStrict,
+			"allowOverValue":        false,
+			"faceUpBeforeHandEmpty": false,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, engine.RulesStrict, rules.Name)
+		assert.True(t, strict.SameRules(rules))
+	})
+
+	t.Run("Standard rules are recognised without a name", func(t *testing.T) {
+		current := engine.DefaultRuleSet()
+		current.Name = engine.RulesCustom
+		current.MinSetSize = 3
+		rules, err := parseRuleSet(current, map[string]interface{}{"minSetSize": float64(4)})
+		require.NoError(t, err)
+		assert.Equal(t, engine.RulesStandard, rules.Name)
+	})
+
+	t.Run("Invalid values are rejected", func(t *testing.T) {
+		_, err := parseRuleSet(engine.DefaultRuleSet(), map[string]interface{}{"minSetSize": float64(1)})
+		assert.Error(t, err)
+
+		_, err = parseRuleSet(engine.DefaultRuleSet(), map[string]interface{}{"wildRanks": []interface{}{"11"}})
+		assert.Error(t, err)
+
+		_, err = parseRuleSet(engine.DefaultRuleSet(), map[string]interface{}{"wildClears": "yes"})
+		assert.Error(t, err)
+	})
+}
+
+func TestBotLevelSetting(t *testing.T) {
+	h := NewRoomHandler()
+	host, guest, roomCode := openRoom(t, h, true)
+
+	deliver(t, h, host, map[string]interface{}{"type": TypeUpdateSettings, "botLevel": "hard"})
+	assert.Nil(t, host.Last(TypeError))
+	updated := guest.Last(TypeSettingsUpdated)
+	require.NotNil(t, updated)
+	settings := updated["room"].(map[string]interface{})["settings"].(map[string]interface{})
+	assert.Equal(t, "hard", settings["botLevel"])
+
+	deliver(t, h, host, map[string]interface{}{"type": TypeUpdateSettings, "botLevel": "godlike"})
+	assert.Equal(t, "Unknown bot level", host.Last(TypeError)["message"])
+
+	// A searching bot takes its turn as soon as it is up
+	deliver(t, h, host, map[string]interface{}{"type": TypeUpdateSettings, "botLevel": "easy"})
+	deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": `
+current: Carol
+pile: [9H]
+players:
+  - {name: Alice, hand: [5H, 9C]}
+  - {name: Bob, hand: [KS, 3D]}
+  - {name: Carol, hand: [4C, QD]}
+`})
+	game := h.games[roomCode]
+	require.NotNil(t, game)
+	// Carol may pick up before she plays, but her turn is over either way
+	require.Eventually(t, func() bool {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return game.GetCurrentPlayer().Name == "Alice"
+	}, 5*time.Second, 5*time.Millisecond)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	assert.NotZero(t, game.Progress.Actions)
+	assert.Empty(t, h.botSearches)
+}
+
+func TestBotSearchOutsideLock(t *testing.T) {
+	h := NewRoomHandler()
+	host, _, roomCode := openRoom(t, h, true)
+	deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": `
+pile: [9H]
+players:
+  - {name: Alice, hand: [5H, 9C]}
+  - {name: Bob, hand: [KS, 3D]}
+  - {name: Carol, hand: [4C, QD]}
+`})
+	game := h.games[roomCode]
+	require.NotNil(t, game)
+
+	// Carol starts thinking, then someone moves before her search is back: the search is dropped
+	h.mu.Lock()
+	game.CurrentPlayerIndex = 2
+	h.searchBotTurn(roomCode, game, bot.ForLevel(models.BotEasy))
+	game.CurrentPlayerIndex = 0
+	h.mu.Unlock()
+
+	require.Eventually(t, func() bool {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return len(h.botSearches) == 0
+	}, 5*time.Second, 5*time.Millisecond)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	assert.Zero(t, game.Progress.Actions)
+	assert.Len(t, game.Players[2].Hand, 2)
+	assert.Equal(t, "Alice", game.GetCurrentPlayer().Name)
+}
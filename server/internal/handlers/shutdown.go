@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/models"
+	"github.com/thben/clearthedeck/internal/storage"
+)
+
+// TypeServerShuttingDown warns every connection that the server is about to stop
+const TypeServerShuttingDown = "SERVER_SHUTTING_DOWN"
+
+// tablesKind is the storage kind running games are saved under
+const tablesKind = "tables"
+
+// savedTable is a running game saved at shutdown so it can be picked up again after a restart
+type savedTable struct {
+	Code        string              `json:"code"`
+	HostID      string              `json:"hostId"`
+	Settings    models.RoomSettings `json:"settings"`
+	PlayerOrder []string            `json:"playerOrder"`
+	Game        *engine.Game        `json:"game"`
+	SavedAt     time.Time           `json:"savedAt"`
+}
+
+// Shutdown drains the handler: new rooms and connections are refused, everyone is warned,
+// and once the countdown or ctx runs out running games are saved to store (if not nil)
+// and every connection is closed with a going-away close code
+func (h *RoomHandler) Shutdown(ctx context.Context, countdown time.Duration, store storage.Store) error {
+	h.mu.Lock()
+	h.draining = true
+	notice := map[string]interface{}{
+		"type":    TypeServerShuttingDown,
+		"seconds": countdown.Seconds(),
+		"saving":  store != nil,
+	}
+	for sess := range h.sessions {
+		sess.Send(notice)
+	}
+	h.mu.Unlock()
+
+	timer := time.NewTimer(countdown)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closing = true
+	h.stopTimers()
+
+	var err error
+	if store != nil {
+		err = h.saveTables(store)
+	}
+	for sess := range h.sessions {
+		sess.CloseWith(websocket.CloseGoingAway, "server shutting down")
+	}
+	return err
+}
+
+// Draining reports whether a shutdown has begun
+func (h *RoomHandler) Draining() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.draining
+}
+
+// stopTimers cancels every seat-hold, undo and turn timer so nothing changes after games are saved
+func (h *RoomHandler) stopTimers() {
+	for id := range h.seatTimers {
+		h.cancelSeatHold(id)
+	}
+	for code, entry := range h.undo {
+		if entry.timer != nil {
+			entry.timer.Stop()
+		}
+		delete(h.undo, code)
+	}
+	for code := range h.turnTimers {
+		h.stopTurnTimer(code)
+	}
+}
+
+// saveTables writes every running game to the store
+func (h *RoomHandler) saveTables(store storage.Store) error {
+	var errs []error
+	for code, game := range h.games {
+		room := h.roomService.GetRoom(code)
+		if room == nil || game == nil || !game.IsStarted || game.IsFinished {
+			continue
+		}
+
+		order := make([]string, 0, len(game.Players))
+		for _, p := range room.GetPlayersInOrder() {
+			order = append(order, p.ID)
+		}
+		data, err := json.Marshal(savedTable{
+			Code:        code,
+			HostID:      room.GetHostID(),
+			Settings:    room.GetSettings(),
+			PlayerOrder: order,
+			Game:        game,
+			SavedAt:     time.Now(),
+		})
+		if err == nil {
+			err = store.Save(tablesKind, code, data)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("saving room %s: %w", code, err))
+			continue
+		}
+		h.roomLog(code).Info("game saved")
+	}
+	return errors.Join(errs...)
+}
+
+// RestoreTables reopens the games saved at the last shutdown
+// Every human seat is held so players can come back with REJOIN_ROOM and their player ID
+func (h *RoomHandler) RestoreTables(store storage.Store) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	codes, err := store.List(tablesKind)
+	if err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	var errs []error
+	for _, code := range codes {
+		if err := h.restoreTable(store, code); err != nil {
+			errs = append(errs, fmt.Errorf("restoring room %s: %w", code, err))
+			continue
+		}
+		store.Delete(tablesKind, code)
+		restored++
+	}
+	return restored, errors.Join(errs...)
+}
+
+func (h *RoomHandler) restoreTable(store storage.Store, code string) error {
+	data, err := store.Load(tablesKind, code)
+	if err != nil {
+		return err
+	}
+	var table savedTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return err
+	}
+	if table.Game == nil || len(table.Game.Players) == 0 {
+		return fmt.Errorf("no game")
+	}
+
+	// The room shares player objects with the game, as a room that started the game would
+	room := models.NewRoom(uuid.New().String(), code, table.HostID)
+	room.SetSettings(table.Settings)
+	for _, id := range table.PlayerOrder {
+		if i := table.Game.PlayerIndex(id); i >= 0 {
+			room.AddPlayer(table.Game.Players[i])
+		}
+	}
+	if err := h.roomService.AddRoom(room); err != nil {
+		return err
+	}
+
+	// Seats already held when the server stopped keep the time they had left
+	hold := table.Settings.SeatHoldDuration
+	now := time.Now()
+	var expired []string
+	for _, p := range table.Game.Players {
+		switch {
+		case p.IsBot:
+		case p.Away:
+			if left := p.AwayUntil.Sub(now); left > 0 {
+				h.startSeatHold(code, p.ID, left)
+			} else {
+				expired = append(expired, p.ID)
+			}
+		default:
+			p.Away = true
+			p.AwayUntil = now.Add(hold)
+			h.startSeatHold(code, p.ID, hold)
+		}
+	}
+
+	h.games[code] = table.Game
+	h.touch(code)
+	for _, id := range expired {
+		h.expireSeatHold(code, id)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thben/clearthedeck/internal/storage"
+)
+
+// startMemoryGame seats a host and a guest in a started game
+func startMemoryGame(t *testing.T, h *RoomHandler) (string, *MemorySession, *MemorySession, string) {
+	host, guest := NewMemorySession("host"), NewMemorySession("guest")
+	h.Connect(host)
+	h.Connect(guest)
+	deliver(t, h, host, map[string]interface{}{"type": "CREATE_ROOM", "playerName": "Host"})
+	created := host.Last(TypeRoomCreated)
+	require.NotNil(t, created)
+	roomCode := created["roomCode"].(string)
+	deliver(t, h, guest, map[string]interface{}{"type": "JOIN_ROOM", "roomCode": roomCode, "playerName": "Guest"})
+	deliver(t, h, host, map[string]interface{}{"type": "START_GAME", "roomCode": roomCode, "playerId": created["playerId"]})
+	require.NotNil(t, host.Last(TypeGameStarted))
+	return roomCode, host, guest, guest.Last(TypeRoomJoined)["playerId"].(string)
+}
+
+func TestShutdown(t *testing.T) {
+	t.Run("warns everyone, refuses new rooms, then saves games and closes connections", func(t *testing.T) {
+		h := NewRoomHandler()
+		roomCode, host, guest, _ := startMemoryGame(t, h)
+		lobby := NewMemorySession("lobby")
+		h.Connect(lobby)
+		store := storage.NewMemoryStore()
+
+		done := make(chan error, 1)
+		go func() { done <- h.Shutdown(context.Background(), 100*time.Millisecond, store) }()
+
+		require.Eventually(t, func() bool { return lobby.Last(TypeServerShuttingDown) != nil }, time.Second, 5*time.Millisecond)
+		notice := host.Last(TypeServerShuttingDown)
+		require.NotNil(t, notice)
+		assert.Equal(t, 0.1, notice["seconds"])
+		assert.Equal(t, true, notice["saving"])
+
+		deliver(t, h, lobby, map[string]interface{}{"type": "CREATE_ROOM", "playerName": "Late"})
+		assert.Contains(t, lobby.Last(TypeError)["message"], "shutting down")
+
+		require.NoError(t, <-done)
+		for _, sess := range []*MemorySession{host, guest, lobby} {
+			assert.Equal(t, websocket.CloseGoingAway, sess.CloseCode())
+		}
+		keys, err := store.List(tablesKind)
+		require.NoError(t, err)
+		assert.Equal(t, []string{roomCode}, keys)
+	})
+
+	t.Run("the deadline cuts the countdown short", func(t *testing.T) {
+		h := NewRoomHandler()
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		require.NoError(t, h.Shutdown(ctx, time.Minute, nil))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("disconnects during shutdown do not count as departures", func(t *testing.T) {
+		h := NewRoomHandler()
+		roomCode, _, guest, guestID := startMemoryGame(t, h)
+		require.NoError(t, h.Shutdown(context.Background(), 0, nil))
+
+		h.Disconnect(guest)
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		room := h.roomService.GetRoom(roomCode)
+		require.NotNil(t, room)
+		player, ok := room.GetPlayer(guestID)
+		require.True(t, ok)
+		assert.False(t, player.Away)
+	})
+}
+
+func TestRestoreTables(t *testing.T) {
+	store := storage.NewMemoryStore()
+	before := NewRoomHandler()
+	roomCode, _, _, guestID := startMemoryGame(t, before)
+	require.NoError(t, before.Shutdown(context.Background(), 0, store))
+
+	after := NewRoomHandler()
+	restored, err := after.RestoreTables(store)
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored)
+
+	keys, err := store.List(tablesKind)
+	require.NoError(t, err)
+	assert.Empty(t, keys, "restored games are removed from storage")
+
+	// Seats are held until their players come back
+	after.mu.Lock()
+	room := after.roomService.GetRoom(roomCode)
+	require.NotNil(t, room)
+	game := after.games[roomCode]
+	require.NotNil(t, game)
+	guest, ok := room.GetPlayer(guestID)
+	require.True(t, ok)
+	assert.True(t, guest.Away)
+	assert.Same(t, guest, game.Players[game.PlayerIndex(guestID)])
+	after.mu.Unlock()
+
+	sess := NewMemorySession("returning")
+	after.Connect(sess)
+	deliver(t, after, sess, map[string]interface{}{"type": "REJOIN_ROOM", "roomCode": roomCode, "playerId": guestID})
+
+	require.NotNil(t, sess.Last(TypeRoomJoined))
+	require.NotNil(t, sess.Last(TypeGameUpdate))
+	assert.False(t, guest.Away)
+}
+
+func TestRestoreAwayPlayers(t *testing.T) {
+	// restoreAway saves a game whose guest had already left, with their hold ending after the given time
+	restoreAway := func(t *testing.T, left time.Duration) (*RoomHandler, string, string) {
+		store := storage.NewMemoryStore()
+		before := NewRoomHandler()
+		roomCode, _, _, guestID := startMemoryGame(t, before)
+		before.mu.Lock()
+		guest, ok := before.roomService.GetRoom(roomCode).GetPlayer(guestID)
+		require.True(t, ok)
+		guest.Away = true
+		guest.AwayUntil = time.Now().Add(left)
+		before.mu.Unlock()
+		require.NoError(t, before.Shutdown(context.Background(), 0, store))
+
+		after := NewRoomHandler()
+		restored, err := after.RestoreTables(store)
+		require.NoError(t, err)
+		require.Equal(t, 1, restored)
+		return after, roomCode, guestID
+	}
+
+	t.Run("a held seat keeps the time it had left", func(t *testing.T) {
+		after, roomCode, guestID := restoreAway(t, 50*time.Millisecond)
+
+		after.mu.Lock()
+		game := after.games[roomCode]
+		require.NotNil(t, game)
+		assert.GreaterOrEqual(t, game.PlayerIndex(guestID), 0, "the seat is still held")
+		assert.Contains(t, after.seatTimers, guestID)
+		after.mu.Unlock()
+
+		require.Eventually(t, func() bool {
+			after.mu.Lock()
+			defer after.mu.Unlock()
+			return game.PlayerIndex(guestID) < 0
+		}, time.Second, 5*time.Millisecond, "the seat is given up when the hold runs out")
+	})
+
+	t.Run("a hold that ran out while the server was down is given up at once", func(t *testing.T) {
+		after, roomCode, guestID := restoreAway(t, -time.Second)
+
+		after.mu.Lock()
+		defer after.mu.Unlock()
+		game := after.games[roomCode]
+		require.NotNil(t, game)
+		assert.Less(t, game.PlayerIndex(guestID), 0)
+		assert.NotContains(t, after.seatTimers, guestID)
+		require.Len(t, game.Forfeited, 1)
+		assert.Equal(t, guestID, game.Forfeited[0].ID)
+	})
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/models"
+	"github.com/thben/clearthedeck/internal/stats"
+)
+
+// Matches listed with a player's statistics unless a limit is asked for
+const defaultHistoryLimit = 20
+
+// startMatch begins following a newly dealt game for statistics and replays
+// Testing rooms set up positions by hand, so their games are not followed.
+func (h *RoomHandler) startMatch(room *models.Room, game *engine.Game) {
+	h.endMatch(room.Code)
+	if room.GetSettings().Testing {
+		return
+	}
+	h.matches[room.Code] = stats.NewMatch(engine.FromGame(game), time.Now())
+}
+
+// followMatch passes what an action did to the room's match; state is the position after it
+func (h *RoomHandler) followMatch(roomCode string, state engine.State, events []engine.Event) {
+	if m, ok := h.matches[roomCode]; ok {
+		m.Add(state, events)
+	}
+}
+
+// rewindMatch takes undone moves back out of the room's match
+func (h *RoomHandler) rewindMatch(roomCode string, game *engine.Game) {
+	if m, ok := h.matches[roomCode]; ok {
+		m.Rewind(engine.FromGame(game))
+	}
+}
+
+// recordMatchRound adds a scored round to the players' statistics and saves the match
+func (h *RoomHandler) recordMatchRound(roomCode string, game *engine.Game) {
+	m, ok := h.matches[roomCode]
+	if !ok {
+		return
+	}
+	if err := h.stats.RecordRound(m, game); err != nil {
+		h.roomLog(roomCode).Error("statistics could not be saved", "match", m.ID(), "error", err)
+	}
+}
+
+// endMatch saves the room's match as over and stops following it
+func (h *RoomHandler) endMatch(roomCode string) {
+	m, ok := h.matches[roomCode]
+	if !ok {
+		return
+	}
+	delete(h.matches, roomCode)
+	game := h.games[roomCode]
+	if game == nil {
+		return
+	}
+	if err := h.stats.FinishMatch(m, game); err != nil {
+		h.roomLog(roomCode).Error("match could not be saved", "match", m.ID(), "error", err)
+	}
+}
+
+// handleRequestStats answers with a player's statistics and recent matches
+// Without a playerId the signed-in player's own are sent.
+func (h *RoomHandler) handleRequestStats(sess Session, msg map[string]interface{}) {
+	playerID, _ := msg["playerId"].(string)
+	if playerID == "" {
+		account, ok := h.signedIn[sess]
+		if !ok {
+			h.sendError(sess, ErrCodeBadRequest, "Player ID is required; guests have no statistics")
+			return
+		}
+		playerID = account.ID
+	}
+	if !plainID(playerID) {
+		h.sendError(sess, ErrCodeBadRequest, "Invalid player ID")
+		return
+	}
+
+	body, err := h.playerStats(playerID, defaultHistoryLimit)
+	if err != nil {
+		h.sessionLog(sess).Error("statistics could not be loaded", "error", err)
+		h.sendError(sess, ErrCodeUnavailable, "Statistics are unavailable")
+		return
+	}
+	body["type"] = TypePlayerStats
+	sess.Send(body)
+}
+
+// playerStats gathers a player's statistics and match history, each match with a link to its replay
+func (h *RoomHandler) playerStats(playerID string, limit int) (map[string]interface{}, error) {
+	player, err := h.stats.Player(playerID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := h.stats.History(playerID, limit)
+	if err != nil {
+		return nil, err
+	}
+	rating, err := h.stats.Rating(playerID)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]map[string]interface{}, 0, len(history))
+	for _, record := range history {
+		matches = append(matches, map[string]interface{}{
+			"id":        record.ID,
+			"roomCode":  record.RoomCode,
+			"rules":     record.Rules,
+			"startedAt": record.StartedAt,
+			"endedAt":   record.EndedAt,
+			"rounds":    record.Rounds,
+			"finished":  record.Finished,
+			"abandoned": record.Abandoned,
+			"players":   record.Players,
+			"replay":    replayPath(record.ID),
+		})
+	}
+	return map[string]interface{}{
+		"playerId": playerID,
+		"stats":    player,
+		"rating":   rating,
+		"matches":  matches,
+	}, nil
+}
+
+// addRating shows a signed-in player's rating on their lobby seat, rounded to a whole number
+func (h *RoomHandler) addRating(seat map[string]interface{}, playerID string) {
+	rating, err := h.stats.Rating(playerID)
+	if err != nil {
+		h.log.Error("rating could not be loaded", "player", playerID, "error", err)
+		return
+	}
+	seat["rating"] = int(math.Round(rating.Rating))
+	seat["provisional"] = rating.Provisional
+}
+
+// replayPath is where a match's moves can be fetched in notation
+func replayPath(matchID string) string {
+	return "/api/matches/" + matchID + "/replay"
+}
+
+// HandlePlayerStats serves GET /api/players/{id}/stats, with an optional limit on the matches listed
+func (h *RoomHandler) HandlePlayerStats(w http.ResponseWriter, r *http.Request) {
+	if !h.allowCORS(w, r) || !allowGet(w, r) {
+		return
+	}
+	playerID, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/players/"), "/")
+	if !plainID(playerID) || rest != "stats" {
+		writeJSONError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+	limit := defaultHistoryLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > stats.MaxHistory {
+			writeJSONError(w, http.StatusBadRequest, errors.New("limit must be a number from 1 to 100"))
+			return
+		}
+		limit = n
+	}
+
+	body, err := h.playerStats(playerID, limit)
+	if err != nil {
+		h.log.Error("statistics could not be loaded", "player", playerID, "error", err)
+		writeJSONError(w, http.StatusInternalServerError, errors.New("statistics are unavailable"))
+		return
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+// HandleMatch serves GET /api/matches/{id} as JSON and GET /api/matches/{id}/replay as notation text
+func (h *RoomHandler) HandleMatch(w http.ResponseWriter, r *http.Request) {
+	if !h.allowCORS(w, r) || !allowGet(w, r) {
+		return
+	}
+	matchID, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/matches/"), "/")
+	if !plainID(matchID) || (rest != "" && rest != "replay") {
+		writeJSONError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	record, err := h.stats.Match(matchID)
+	if errors.Is(err, stats.ErrMatchNotFound) {
+		writeJSONError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		h.log.Error("match could not be loaded", "match", matchID, "error", err)
+		writeJSONError(w, http.StatusInternalServerError, errors.New("match is unavailable"))
+		return
+	}
+	if rest == "replay" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(record.Notation))
+		return
+	}
+	writeJSON(w, http.StatusOK, record)
+}
+
+// plainID reports whether an ID from a URL could be a player or match ID: letters, digits, - and _
+func plainID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// allowGet refuses anything but GET, answering the request itself
+func allowGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet {
+		return true
+	}
+	w.Header().Set("Allow", "GET")
+	writeJSONError(w, http.StatusMethodNotAllowed, errors.New("use GET"))
+	return false
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/bot"
+	"github.com/thben/clearthedeck/internal/notation"
+	"github.com/thben/clearthedeck/internal/stats"
+)
+
+// playOutRound has each player send the heuristic bot's move until the round ends
+func playOutRound(t *testing.T, h *RoomHandler, roomCode string, seats map[string]*MemorySession) {
+	t.Helper()
+	strategy := bot.NewHeuristic()
+	for moves := 0; moves < 2000; moves++ {
+		game := h.games[roomCode]
+		if game.RoundOver() {
+			return
+		}
+		current := game.GetCurrentPlayer()
+		action, err := strategy.ChooseAction(game, current.ID)
+		require.NoError(t, err)
+
+		msg := map[string]interface{}{"type": TypePickupPile}
+		switch action.Type {
+		case engine.ActionPlay:
+			msg = map[string]interface{}{"type": TypePlayCards, "cardIds": action.CardIDs, "afterPickup": action.AfterPickup}
+		case engine.ActionFlip:
+			msg = map[string]interface{}{"type": TypeFlipFaceDown, "cardId": action.CardIDs[0]}
+		}
+		deliver(t, h, seats[current.ID], msg)
+	}
+	t.Fatal("round did not end")
+}
+
+func TestPlayerStats(t *testing.T) {
+	h := NewRoomHandler()
+	alice, _ := signIn(t, h, "alice", "Alice")
+	host := NewMemorySession("alice")
+	h.ConnectAs(host, alice)
+	deliver(t, h, host, map[string]interface{}{"type": TypeCreateRoom})
+	roomCode := host.Last(TypeRoomCreated)["roomCode"].(string)
+
+	guest := NewMemorySession("bob")
+	h.Connect(guest)
+	deliver(t, h, guest, map[string]interface{}{"type": TypeJoinRoom, "roomCode": roomCode, "playerName": "Bob"})
+	bobID := guest.Last(TypeRoomJoined)["playerId"].(string)
+
+	deliver(t, h, host, map[string]interface{}{"type": TypeStartGame, "roomCode": roomCode, "playerId": alice.ID})
+	require.NotNil(t, host.Last(TypeGameStarted))
+	playOutRound(t, h, roomCode, map[string]*MemorySession{alice.ID: host, bobID: guest})
+	result := h.games[roomCode].LastResult()
+	require.NotNil(t, result)
+	var aliceScore int
+	for _, line := range result.Players {
+		if line.ID == alice.ID {
+			aliceScore = line.RoundScore
+		}
+	}
+
+	t.Run("the round is added to the signed-in player's statistics", func(t *testing.T) {
+		deliver(t, h, host, map[string]interface{}{"type": TypeRequestStats})
+		answer := host.Last(TypePlayerStats)
+		require.NotNil(t, answer)
+		stats := answer["stats"].(map[string]interface{})
+		assert.Equal(t, float64(1), stats["gamesPlayed"])
+		assert.Equal(t, float64(1), stats["roundsPlayed"])
+		assert.Equal(t, float64(aliceScore), stats["totalRoundScore"])
+
+		rating := answer["rating"].(map[string]interface{})
+		assert.Equal(t, true, rating["provisional"])
+		assert.Equal(t, float64(0), rating["games"], "two-player games are not rated")
+
+		matches := answer["matches"].([]interface{})
+		require.Len(t, matches, 1)
+		assert.Contains(t, matches[0].(map[string]interface{})["replay"], "/replay")
+	})
+
+	t.Run("lobbies show signed-in players' ratings", func(t *testing.T) {
+		players := guest.Last(TypeRoomJoined)["room"].(map[string]interface{})["players"].([]interface{})
+		for _, p := range players {
+			seat := p.(map[string]interface{})
+			if seat["id"] == alice.ID {
+				assert.Equal(t, float64(1500), seat["rating"])
+				assert.Equal(t, true, seat["provisional"])
+			} else {
+				assert.NotContains(t, seat, "rating", "guests are unrated")
+			}
+		}
+	})
+
+	t.Run("guests must name a player", func(t *testing.T) {
+		deliver(t, h, guest, map[string]interface{}{"type": TypeRequestStats})
+		assert.Equal(t, ErrCodeBadRequest, guest.Last(TypeError)["code"])
+
+		deliver(t, h, guest, map[string]interface{}{"type": TypeRequestStats, "playerId": alice.ID})
+		assert.NotNil(t, guest.Last(TypePlayerStats))
+	})
+
+	t.Run("statistics and replays are served over HTTP", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.HandlePlayerStats(rec, httptest.NewRequest(http.MethodGet, "/api/players/"+alice.ID+"/stats?limit=5", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		match := body["matches"].([]interface{})[0].(map[string]interface{})
+
+		rec = httptest.NewRecorder()
+		h.HandleMatch(rec, httptest.NewRequest(http.MethodGet, match["replay"].(string), nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		parsed, err := notation.Parse(rec.Body.String())
+		require.NoError(t, err)
+		_, err = notation.Replay(parsed)
+		assert.NoError(t, err, rec.Body.String())
+
+		rec = httptest.NewRecorder()
+		h.HandleMatch(rec, httptest.NewRequest(http.MethodGet, "/api/matches/missing", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("closing the room mid-game saves the match as abandoned", func(t *testing.T) {
+		h.closeRoom(roomCode)
+		history, err := h.stats.History(alice.ID, 10)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.False(t, history[0].Finished)
+		assert.True(t, history[0].Abandoned)
+	})
+}
+
+func TestGameEnd(t *testing.T) {
+	h := NewRoomHandler()
+	seats := make(map[string]*MemorySession)
+	var ids []string
+	for _, name := range []string{"Alice", "Bob", "Carol"} {
+		account, _ := signIn(t, h, strings.ToLower(name), name)
+		sess := NewMemorySession(account.Username)
+		h.ConnectAs(sess, account)
+		seats[account.ID] = sess
+		ids = append(ids, account.ID)
+	}
+	host := seats[ids[0]]
+	deliver(t, h, host, map[string]interface{}{"type": TypeCreateRoom})
+	roomCode := host.Last(TypeRoomCreated)["roomCode"].(string)
+	for _, id := range ids[1:] {
+		deliver(t, h, seats[id], map[string]interface{}{"type": TypeJoinRoom, "roomCode": roomCode})
+		require.NotNil(t, seats[id].Last(TypeRoomJoined))
+	}
+
+	ratedGames := func(t *testing.T) []int {
+		games := make([]int, len(ids))
+		for i, id := range ids {
+			r, err := h.stats.Rating(id)
+			require.NoError(t, err)
+			games[i] = r.Games
+		}
+		return games
+	}
+
+	t.Run("the game ends after the round limit and is rated", func(t *testing.T) {
+		deliver(t, h, host, map[string]interface{}{"type": TypeUpdateSettings, "roundLimit": 2})
+		require.Nil(t, host.Last(TypeError))
+		deliver(t, h, host, map[string]interface{}{"type": TypeStartGame, "roomCode": roomCode, "playerId": ids[0]})
+		require.NotNil(t, host.Last(TypeGameStarted))
+
+		playOutRound(t, h, roomCode, seats)
+		assert.Nil(t, host.Last(TypeGameOver), "one round is not the end")
+		deliver(t, h, host, map[string]interface{}{"type": TypeNextRound})
+		playOutRound(t, h, roomCode, seats)
+
+		over := seats[ids[2]].Last(TypeGameOver)
+		require.NotNil(t, over)
+		assert.Len(t, over["standings"], 3)
+		assert.True(t, h.games[roomCode].IsFinished)
+		assert.Equal(t, []int{1, 1, 1}, ratedGames(t))
+		moved := false
+		for _, id := range ids {
+			r, err := h.stats.Rating(id)
+			require.NoError(t, err)
+			moved = moved || r.Rating != stats.InitialRating
+		}
+		assert.True(t, moved, "ratings change")
+
+		history, err := h.stats.History(ids[0], 10)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.True(t, history[0].Finished)
+		assert.False(t, history[0].Abandoned)
+
+		deliver(t, h, host, map[string]interface{}{"type": TypeNextRound})
+		assert.Equal(t, ErrCodeWrongPhase, host.Last(TypeError)["code"])
+	})
+
+	t.Run("the host ends the game between rounds", func(t *testing.T) {
+		deliver(t, h, host, map[string]interface{}{"type": TypeUpdateSettings, "roundLimit": 0})
+		deliver(t, h, host, map[string]interface{}{"type": TypeStartGame, "roomCode": roomCode, "playerId": ids[0]})
+		require.NotNil(t, host.Last(TypeGameStarted))
+
+		deliver(t, h, seats[ids[1]], map[string]interface{}{"type": TypeEndGame})
+		assert.Equal(t, ErrCodeForbidden, seats[ids[1]].Last(TypeError)["code"])
+		deliver(t, h, host, map[string]interface{}{"type": TypeEndGame})
+		assert.Contains(t, host.Last(TypeError)["message"], "between rounds")
+
+		playOutRound(t, h, roomCode, seats)
+		gameOvers := func() int {
+			n := 0
+			for _, msg := range host.Messages() {
+				if msg["type"] == TypeGameOver {
+					n++
+				}
+			}
+			return n
+		}
+		before := gameOvers()
+		deliver(t, h, host, map[string]interface{}{"type": TypeEndGame})
+		assert.Equal(t, before+1, gameOvers())
+		assert.True(t, h.games[roomCode].IsFinished)
+		assert.Equal(t, []int{2, 2, 2}, ratedGames(t))
+
+		history, err := h.stats.History(ids[0], 10)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		for _, record := range history {
+			assert.True(t, record.Finished)
+			assert.False(t, record.Abandoned)
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import "github.com/thben/clearthedeck/engine"
+
+// handleRequestTracker sends the player the public card tracker for their table
+func (h *RoomHandler) handleRequestTracker(sess Session, msg map[string]interface{}) {
+	connInfo, ok := h.connInfo[sess]
+	if !ok {
+		h.sendError(sess, ErrCodeNotFound, "Connection not registered")
+		return
+	}
+
+	game, ok := h.games[connInfo.RoomCode]
+	if !ok || game == nil {
+		h.sendError(sess, ErrCodeWrongPhase, "Game not started")
+		return
+	}
+
+	sess.Send(map[string]interface{}{
+		"type":    TypeCardTracker,
+		"tracker": engine.TrackCards(game),
+	})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardTracker(t *testing.T) {
+	t.Run("Players may ask for the tracker", func(t *testing.T) {
+		h := NewRoomHandler()
+		host, guest, _ := openRoom(t, h, true)
+
+		deliver(t, h, guest, map[string]interface{}{"type": TypeRequestTracker})
+		assert.Equal(t, "Game not started", guest.Last(TypeError)["message"])
+
+		deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": threeKings})
+		require.Nil(t, guest.Last(TypeCardTracker))
+		deliver(t, h, guest, map[string]interface{}{"type": TypeRequestTracker})
+		reply := guest.Last(TypeCardTracker)
+		require.NotNil(t, reply)
+		tracker := reply["tracker"].(map[string]interface{})
+		assert.Equal(t, float64(3), tracker["onPile"].(map[string]interface{})["K"])
+		assert.Equal(t, float64(1), tracker["faceUp"].(map[string]interface{})["K"])
+		assert.Nil(t, host.Last(TypeCardTracker), "The reply goes only to the player who asked")
+
+		assert.NotContains(t, guest.Last(TypeGameStarted)["game"], "tracker")
+	})
+
+	t.Run("Casual tables see it with every update", func(t *testing.T) {
+		h := NewRoomHandler()
+		host, guest, _ := openRoom(t, h, true)
+
+		deliver(t, h, host, map[string]interface{}{"type": TypeUpdateSettings, "showTracker": true})
+		settings := guest.Last(TypeSettingsUpdated)["room"].(map[string]interface{})["settings"].(map[string]interface{})
+		assert.Equal(t, true, settings["showTracker"])
+
+		deliver(t, h, host, map[string]interface{}{"type": TypeLoadScenario, "scenario": threeKings})
+		game := guest.Last(TypeGameStarted)["game"].(map[string]interface{})
+		require.Contains(t, game, "tracker")
+		tracker := game["tracker"].(map[string]interface{})
+		assert.Equal(t, float64(3), tracker["onPile"].(map[string]interface{})["K"])
+	})
+}
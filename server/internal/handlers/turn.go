@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/thben/clearthedeck/engine"
+)
+
+// TypeTurnTimedOut tells the table a bot played a turn the player let run out
+const TypeTurnTimedOut = "TURN_TIMED_OUT"
+
+// turnKey identifies one turn; any action or a new round makes a new one
+type turnKey struct {
+	round    int
+	actions  int
+	playerID string
+}
+
+// turnTimer runs out a turn when the player takes too long
+type turnTimer struct {
+	key   turnKey
+	timer *time.Timer
+}
+
+// scheduleTurn starts the timer for the current turn unless it is already running
+// Bots, held seats and finished rounds are not timed
+func (h *RoomHandler) scheduleTurn(roomCode string, game *engine.Game) {
+	if h.opts.TurnTimeout <= 0 {
+		return
+	}
+	current := game.GetCurrentPlayer()
+	if current == nil || current.IsBot || !current.IsPresent() || game.IsFinished || game.RoundOver() {
+		h.stopTurnTimer(roomCode)
+		return
+	}
+
+	key := turnKey{round: game.Round, actions: game.Progress.Actions, playerID: current.ID}
+	if t, ok := h.turnTimers[roomCode]; ok && t.key == key {
+		return
+	}
+	h.stopTurnTimer(roomCode)
+
+	t := &turnTimer{key: key}
+	t.timer = time.AfterFunc(h.opts.TurnTimeout, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.turnTimers[roomCode] == t {
+			delete(h.turnTimers, roomCode)
+			h.expireTurn(roomCode, key)
+		}
+	})
+	h.turnTimers[roomCode] = t
+}
+
+// stopTurnTimer cancels the room's turn timer, if any
+func (h *RoomHandler) stopTurnTimer(roomCode string) {
+	if t, ok := h.turnTimers[roomCode]; ok {
+		t.timer.Stop()
+		delete(h.turnTimers, roomCode)
+	}
+}
+
+// expireTurn has the bot strategy play the turn the player let run out
+func (h *RoomHandler) expireTurn(roomCode string, key turnKey) {
+	game := h.games[roomCode]
+	if game == nil {
+		return
+	}
+	current := game.GetCurrentPlayer()
+	if current == nil || game.RoundOver() || (turnKey{game.Round, game.Progress.Actions, current.ID}) != key {
+		return
+	}
+
+	action, err := h.botStrategy.ChooseAction(game, current.ID)
+	events := h.playForSeat(roomCode, game, current.ID, action, err)
+	h.clearUndo(roomCode, undoSuperseded)
+
+	broadcast := map[string]interface{}{
+		"type":       TypeTurnTimedOut,
+		"playerId":   current.ID,
+		"playerName": current.Name,
+	}
+	h.broadcastToRoom(roomCode, broadcast, nil)
+
+	if h.finishRound(roomCode, game, events) {
+		return
+	}
+	h.broadcastGameState(roomCode, game)
+	h.playBotTurns(roomCode, game)
+}
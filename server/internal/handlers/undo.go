@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/thben/clearthedeck/engine"
+)
+
+// Reasons sent with UNDO_REJECTED
+const (
+	undoDeclined   = "declined"
+	undoExpired    = "expired"
+	undoSuperseded = "superseded"
+)
+
+// undoEntry is the most recent action in a room and, once requested, the vote on undoing it
+type undoEntry struct {
+	playerID string
+	snapshot *engine.GameSnapshot
+	revealed bool // The action showed a face-down card to the table, so it cannot be taken back
+	approved map[string]bool
+	timer    *time.Timer // Set while a vote is running
+}
+
+// recordAction remembers the state before a player's action so it can be undone
+// Any earlier action can no longer be undone
+func (h *RoomHandler) recordAction(roomCode, playerID string, snapshot *engine.GameSnapshot) {
+	h.clearUndo(roomCode, undoSuperseded)
+	h.undo[roomCode] = &undoEntry{
+		playerID: playerID,
+		snapshot: snapshot,
+		approved: make(map[string]bool),
+	}
+}
+
+// recordReveal remembers a player's action that showed a face-down card
+// It supersedes any earlier action but cannot be undone itself: the card is no longer hidden
+func (h *RoomHandler) recordReveal(roomCode, playerID string) {
+	h.clearUndo(roomCode, undoSuperseded)
+	h.undo[roomCode] = &undoEntry{playerID: playerID, revealed: true}
+}
+
+// clearUndo forgets the most recent action, rejecting any vote still running on it
+func (h *RoomHandler) clearUndo(roomCode, reason string) {
+	entry, ok := h.undo[roomCode]
+	if !ok {
+		return
+	}
+	delete(h.undo, roomCode)
+	if entry.timer != nil {
+		entry.timer.Stop()
+		h.broadcastUndoRejected(roomCode, entry, reason)
+	}
+}
+
+// handleRequestUndo asks the table to undo the requesting player's most recent action
+// The host may pass force to undo their own action without a vote
+func (h *RoomHandler) handleRequestUndo(sess Session, msg map[string]interface{}) {
+	connInfo, ok := h.connInfo[sess]
+	if !ok {
+		h.sendError(sess, ErrCodeNotFound, "Connection not registered")
+		return
+	}
+
+	room := h.roomService.GetRoom(connInfo.RoomCode)
+	game := h.games[connInfo.RoomCode]
+	if room == nil || game == nil {
+		h.sendError(sess, ErrCodeWrongPhase, "Game not started")
+		return
+	}
+
+	entry, ok := h.undo[connInfo.RoomCode]
+	if ok && entry.revealed && entry.playerID == connInfo.PlayerID {
+		h.sendError(sess, ErrCodeForbidden, "A flip cannot be undone once the face-down card has been shown")
+		return
+	}
+	if !ok || entry.playerID != connInfo.PlayerID || !entry.snapshot.Matches(game) {
+		h.sendError(sess, ErrCodeForbidden, "Only your most recent action can be undone, before anyone else acts")
+		return
+	}
+	if entry.timer != nil {
+		h.sendError(sess, ErrCodeWrongPhase, "Undo already requested")
+		return
+	}
+
+	force, _ := msg["force"].(bool)
+	voters := h.undoVoters(game, entry)
+	if len(voters) == 0 || (force && room.GetHostID() == connInfo.PlayerID) {
+		h.applyUndo(connInfo.RoomCode, game, entry)
+		return
+	}
+
+	roomCode := connInfo.RoomCode
+	entry.timer = time.AfterFunc(h.opts.UndoVoteWindow, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.undo[roomCode] == entry {
+			h.clearUndo(roomCode, undoExpired)
+		}
+	})
+
+	player, _ := room.GetPlayer(connInfo.PlayerID)
+	broadcast := map[string]interface{}{
+		"type":        TypeUndoRequested,
+		"playerId":    connInfo.PlayerID,
+		"voteSeconds": h.opts.UndoVoteWindow.Seconds(),
+	}
+	if player != nil {
+		broadcast["playerName"] = player.Name
+	}
+	h.broadcastToRoom(roomCode, broadcast, nil)
+}
+
+// handleUndoVote records a player's answer to a pending undo request
+// A host voting with force applies the undo straight away
+func (h *RoomHandler) handleUndoVote(sess Session, msg map[string]interface{}) {
+	connInfo, ok := h.connInfo[sess]
+	if !ok {
+		h.sendError(sess, ErrCodeNotFound, "Connection not registered")
+		return
+	}
+
+	entry, ok := h.undo[connInfo.RoomCode]
+	game := h.games[connInfo.RoomCode]
+	if !ok || entry.timer == nil || game == nil {
+		h.sendError(sess, ErrCodeNotFound, "No undo request to vote on")
+		return
+	}
+
+	room := h.roomService.GetRoom(connInfo.RoomCode)
+	if force, _ := msg["force"].(bool); force {
+		if room == nil || room.GetHostID() != connInfo.PlayerID {
+			h.sendError(sess, ErrCodeForbidden, "Only the host can force an undo")
+			return
+		}
+		h.applyUndo(connInfo.RoomCode, game, entry)
+		return
+	}
+
+	if connInfo.PlayerID == entry.playerID {
+		h.sendError(sess, ErrCodeForbidden, "You cannot vote on your own undo request")
+		return
+	}
+
+	approve, ok := msg["approve"].(bool)
+	if !ok {
+		h.sendError(sess, ErrCodeBadRequest, "approve field is required")
+		return
+	}
+	if !approve {
+		h.clearUndo(connInfo.RoomCode, undoDeclined)
+		return
+	}
+
+	entry.approved[connInfo.PlayerID] = true
+	for _, id := range h.undoVoters(game, entry) {
+		if !entry.approved[id] {
+			return
+		}
+	}
+	h.applyUndo(connInfo.RoomCode, game, entry)
+}
+
+// undoVoters lists the players whose approval an undo needs: everyone else still sitting at the table
+func (h *RoomHandler) undoVoters(game *engine.Game, entry *undoEntry) []string {
+	voters := make([]string, 0, len(game.Players))
+	for _, p := range game.Players {
+		if p.ID != entry.playerID && p.IsPresent() {
+			voters = append(voters, p.ID)
+		}
+	}
+	return voters
+}
+
+// applyUndo restores the game to before the action and tells the table
+func (h *RoomHandler) applyUndo(roomCode string, game *engine.Game, entry *undoEntry) {
+	if entry.timer != nil {
+		entry.timer.Stop()
+	}
+	delete(h.undo, roomCode)
+	if !entry.snapshot.Matches(game) {
+		h.broadcastUndoRejected(roomCode, entry, undoSuperseded)
+		return
+	}
+	game.Restore(entry.snapshot)
+	h.forgetMistakesAfter(roomCode, game)
+	h.rewindMatch(roomCode, game)
+
+	broadcast := map[string]interface{}{
+		"type":     TypeUndoApplied,
+		"playerId": entry.playerID,
+	}
+	h.broadcastToRoom(roomCode, broadcast, nil)
+	h.broadcastGameState(roomCode, game)
+}
+
+func (h *RoomHandler) broadcastUndoRejected(roomCode string, entry *undoEntry, reason string) {
+	broadcast := map[string]interface{}{
+		"type":     TypeUndoRejected,
+		"playerId": entry.playerID,
+		"reason":   reason,
+	}
+	h.broadcastToRoom(roomCode, broadcast, nil)
+}
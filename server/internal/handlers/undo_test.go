@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// playHostCard plays a card that is not a ten from the host's hand, so the turn passes on
+func playHostCard(t *testing.T, handler *RoomHandler, roomCode string, host testPlayer) string {
+	handler.mu.Lock()
+	game := handler.games[roomCode]
+	require.NotNil(t, game)
+	var cardID string
+	for _, card := range game.Players[0].Hand {
+		if card.Value != "10" {
+			cardID = card.ID
+			break
+		}
+	}
+	handler.mu.Unlock()
+	require.NotEmpty(t, cardID)
+
+	require.NoError(t, host.conn.WriteJSON(map[string]interface{}{
+		"type": "PLAY_CARDS", "cardIds": []string{cardID},
+	}))
+	waitForType(t, host.conn, "GAME_UPDATE")
+	return cardID
+}
+
+func TestUndo(t *testing.T) {
+	newTable := func(t *testing.T) (*RoomHandler, string, []testPlayer) {
+		handler := NewRoomHandler()
+		server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+		t.Cleanup(server.Close)
+		wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+		roomCode, players := setupTable(t, wsURL, 3)
+		startTableGame(t, roomCode, players)
+		return handler, roomCode, players
+	}
+
+	handOf := func(handler *RoomHandler, roomCode string, index int) []string {
+		handler.mu.Lock()
+		defer handler.mu.Unlock()
+		ids := make([]string, 0)
+		for _, card := range handler.games[roomCode].Players[index].Hand {
+			ids = append(ids, card.ID)
+		}
+		return ids
+	}
+
+	t.Run("applied once everyone else approves", func(t *testing.T) {
+		handler, roomCode, players := newTable(t)
+		cardID := playHostCard(t, handler, roomCode, players[0])
+		assert.NotContains(t, handOf(handler, roomCode, 0), cardID)
+
+		require.NoError(t, players[0].conn.WriteJSON(map[string]interface{}{"type": "REQUEST_UNDO"}))
+		requested := waitForType(t, players[1].conn, "UNDO_REQUESTED")
+		assert.Equal(t, players[0].id, requested["playerId"])
+
+		require.NoError(t, players[1].conn.WriteJSON(map[string]interface{}{"type": "UNDO_VOTE", "approve": true}))
+		require.NoError(t, players[2].conn.WriteJSON(map[string]interface{}{"type": "UNDO_VOTE", "approve": true}))
+
+		applied := waitForType(t, players[0].conn, "UNDO_APPLIED")
+		assert.Equal(t, players[0].id, applied["playerId"])
+		update := waitForType(t, players[0].conn, "GAME_UPDATE")
+		game := update["game"].(map[string]interface{})
+		assert.Equal(t, float64(0), game["currentPlayerIndex"])
+		assert.Empty(t, game["centerPile"])
+		assert.Contains(t, handOf(handler, roomCode, 0), cardID)
+	})
+
+	t.Run("rejected when a player declines", func(t *testing.T) {
+		handler, roomCode, players := newTable(t)
+		cardID := playHostCard(t, handler, roomCode, players[0])
+
+		require.NoError(t, players[0].conn.WriteJSON(map[string]interface{}{"type": "REQUEST_UNDO"}))
+		waitForType(t, players[2].conn, "UNDO_REQUESTED")
+		require.NoError(t, players[2].conn.WriteJSON(map[string]interface{}{"type": "UNDO_VOTE", "approve": false}))
+
+		rejected := waitForType(t, players[0].conn, "UNDO_REJECTED")
+		assert.Equal(t, "declined", rejected["reason"])
+		assert.NotContains(t, handOf(handler, roomCode, 0), cardID)
+	})
+
+	t.Run("host can force an undo", func(t *testing.T) {
+		handler, roomCode, players := newTable(t)
+		cardID := playHostCard(t, handler, roomCode, players[0])
+
+		require.NoError(t, players[0].conn.WriteJSON(map[string]interface{}{"type": "REQUEST_UNDO", "force": true}))
+		waitForType(t, players[1].conn, "UNDO_APPLIED")
+		assert.Contains(t, handOf(handler, roomCode, 0), cardID)
+	})
+
+	t.Run("disabled once someone else has acted", func(t *testing.T) {
+		handler, roomCode, players := newTable(t)
+		playHostCard(t, handler, roomCode, players[0])
+
+		require.NoError(t, players[1].conn.WriteJSON(map[string]interface{}{"type": "PICKUP_PILE"}))
+		waitForType(t, players[0].conn, "GAME_UPDATE")
+
+		require.NoError(t, players[0].conn.WriteJSON(map[string]interface{}{"type": "REQUEST_UNDO"}))
+		errMsg := waitForType(t, players[0].conn, "ERROR")
+		assert.Contains(t, errMsg["message"], "most recent action")
+	})
+}
+
+func TestUndoFlip(t *testing.T) {
+	h := NewRoomHandler()
+	host, _, roomCode := openRoom(t, h, false)
+	deliver(t, h, host, map[string]interface{}{"type": TypeStartGame, "roomCode": roomCode, "playerId": host.Last(TypeRoomCreated)["playerId"]})
+	game := h.games[roomCode]
+	require.NotNil(t, game)
+
+	// Leave the host only their face-down cards so their turn is a flip
+	player := game.Players[game.CurrentPlayerIndex]
+	player.Hand = nil
+	player.TableCardsUp = nil
+	deliver(t, h, host, map[string]interface{}{"type": TypeFlipFaceDown, "cardId": player.TableCardsDown[0].ID})
+	require.Nil(t, host.Last(TypeError))
+
+	deliver(t, h, host, map[string]interface{}{"type": TypeRequestUndo, "force": true})
+	rejected := host.Last(TypeError)
+	require.NotNil(t, rejected, "the flipped card has been shown to everyone")
+	assert.Equal(t, ErrCodeForbidden, rejected["code"])
+	assert.Nil(t, host.Last(TypeUndoApplied))
+}
@@ -0,0 +1,33 @@
+package metrics
+
+// Default is the registry served at /metrics
+var Default = NewRegistry()
+
+// Latency buckets in seconds for handling one message
+var messageBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+
+// Round length buckets in seconds
+var roundBuckets = []float64{30, 60, 120, 300, 600, 900, 1800, 3600}
+
+// Server metrics, updated by the handlers and services
+var (
+	MessagesHandled = Default.NewCounterVec("ctd_messages_handled_total",
+		"WebSocket messages handled, by message type.", "type")
+	Errors = Default.NewCounterVec("ctd_errors_total",
+		"ERROR replies sent to clients, by error code.", "code")
+	MessageDuration = Default.NewHistogramVec("ctd_message_duration_seconds",
+		"Time taken to handle one WebSocket message, by message type.", "type", messageBuckets)
+	GamesStarted = Default.NewCounterVec("ctd_games_started_total",
+		"Games started.", "")
+	RoundsCompleted = Default.NewCounterVec("ctd_rounds_completed_total",
+		"Rounds scored, by how they ended.", "reason")
+	RoundDuration = Default.NewHistogramVec("ctd_round_duration_seconds",
+		"Time from dealing a round to scoring it.", "", roundBuckets)
+	RoomsCreated = Default.NewCounterVec("ctd_rooms_created_total",
+		"Rooms created.", "")
+	RoomsRefused = Default.NewCounterVec("ctd_rooms_refused_total",
+		"Rooms refused because the server was at its room limit.", "")
+
+	_ = Default.NewGaugeFunc("ctd_round_duration_average_seconds",
+		"Average time from dealing a round to scoring it, since the server started.", RoundDuration.Mean)
+)
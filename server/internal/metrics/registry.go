@@ -0,0 +1,249 @@
+// Package metrics collects server metrics and writes them in the Prometheus text format
+//
+// It implements just the counters, gauges and histograms the server needs, so no client library is required.
+// The package has no net/http dependency so the rules engine can still be built for WebAssembly.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the media type of the text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metric is anything the registry can write out
+type metric interface {
+	name() string
+	write(w io.Writer)
+}
+
+// Registry holds metrics and writes them out in registration order
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+	names   map[string]bool
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[m.name()] {
+		panic("metrics: " + m.name() + " registered twice")
+	}
+	r.names[m.name()] = true
+	r.metrics = append(r.metrics, m)
+}
+
+// Write writes every metric in the Prometheus text exposition format
+func (r *Registry) Write(w io.Writer) {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+// CounterVec counts events, split by the values of one label
+type CounterVec struct {
+	metricName, help, label string
+	mu                      sync.Mutex
+	values                  map[string]float64
+}
+
+// NewCounterVec registers a counter with one label; an empty label name makes a plain counter
+func (r *Registry) NewCounterVec(name, help, label string) *CounterVec {
+	c := &CounterVec{metricName: name, help: help, label: label, values: make(map[string]float64)}
+	r.register(c)
+	return c
+}
+
+// Inc adds one for the label value
+func (c *CounterVec) Inc(value string) {
+	c.Add(value, 1)
+}
+
+// Add adds n for the label value
+func (c *CounterVec) Add(value string, n float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[value] += n
+}
+
+// Value returns the count for the label value
+func (c *CounterVec) Value(value string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[value]
+}
+
+func (c *CounterVec) name() string { return c.metricName }
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	writeHeader(w, c.metricName, c.help, "counter")
+	for _, v := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.metricName, labels(c.label, v), formatFloat(c.values[v]))
+	}
+}
+
+// GaugeFunc reports a value read when metrics are scraped
+type GaugeFunc struct {
+	metricName, help string
+	read             func() float64
+}
+
+// NewGaugeFunc registers a gauge whose value comes from read
+func (r *Registry) NewGaugeFunc(name, help string, read func() float64) *GaugeFunc {
+	g := &GaugeFunc{metricName: name, help: help, read: read}
+	r.register(g)
+	return g
+}
+
+func (g *GaugeFunc) name() string { return g.metricName }
+
+func (g *GaugeFunc) write(w io.Writer) {
+	writeHeader(w, g.metricName, g.help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.metricName, formatFloat(g.read()))
+}
+
+// HistogramVec tracks the distribution of observations, split by the values of one label
+type HistogramVec struct {
+	metricName, help, label string
+	buckets                 []float64
+	mu                      sync.Mutex
+	series                  map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64 // Per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec registers a histogram with the given upper bounds; an empty label name makes a plain histogram
+func (r *Registry) NewHistogramVec(name, help, label string, buckets []float64) *HistogramVec {
+	h := &HistogramVec{
+		metricName: name,
+		help:       help,
+		label:      label,
+		buckets:    append([]float64(nil), buckets...),
+		series:     make(map[string]*histogram),
+	}
+	sort.Float64s(h.buckets)
+	r.register(h)
+	return h
+}
+
+// Observe records a value for the label value
+func (h *HistogramVec) Observe(value string, v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[value]
+	if !ok {
+		s = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.series[value] = s
+	}
+	for i, bound := range h.buckets {
+		if v <= bound {
+			s.counts[i]++
+			break
+		}
+	}
+	s.count++
+	s.sum += v
+}
+
+// Mean returns the average of every observation across all label values, or 0 without any
+func (h *HistogramVec) Mean() float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var count uint64
+	var sum float64
+	for _, s := range h.series {
+		count += s.count
+		sum += s.sum
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+func (h *HistogramVec) name() string { return h.metricName }
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	writeHeader(w, h.metricName, h.help, "histogram")
+	for _, value := range sortedKeys(h.series) {
+		s := h.series[value]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, labels(h.label, value, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, labels(h.label, value, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.metricName, labels(h.label, value), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.metricName, labels(h.label, value), s.count)
+	}
+}
+
+func writeHeader(w io.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, escapeHelp(help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+// labels formats label pairs, skipping any whose name is empty
+func labels(pairs ...string) string {
+	var parts []string
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i] == "" {
+			continue
+		}
+		parts = append(parts, pairs[i]+`="`+escapeLabel(pairs[i+1])+`"`)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func escapeHelp(v string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(v)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	reg := NewRegistry()
+	messages := reg.NewCounterVec("test_messages_total", "Messages handled.", "type")
+	latency := reg.NewHistogramVec("test_latency_seconds", "Handling time.", "", []float64{0.5, 0.1})
+	reg.NewGaugeFunc("test_open", "Open things.", func() float64 { return 3 })
+
+	messages.Inc("PLAY_CARDS")
+	messages.Inc("PLAY_CARDS")
+	messages.Inc(`say "hi"`)
+	latency.Observe("", 0.05)
+	latency.Observe("", 0.3)
+	latency.Observe("", 2)
+
+	var out bytes.Buffer
+	reg.Write(&out)
+
+	assert.Equal(t, `# HELP test_messages_total Messages handled.
+# TYPE test_messages_total counter
+test_messages_total{type="PLAY_CARDS"} 2
+test_messages_total{type="say \"hi\""} 1
+# HELP test_latency_seconds Handling time.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{le="0.1"} 1
+test_latency_seconds_bucket{le="0.5"} 2
+test_latency_seconds_bucket{le="+Inf"} 3
+test_latency_seconds_sum 2.35
+test_latency_seconds_count 3
+# HELP test_open Open things.
+# TYPE test_open gauge
+test_open 3
+`, out.String())
+	assert.InDelta(t, 2.35/3, latency.Mean(), 1e-9)
+}
+
+func TestRegistryRejectsDuplicateNames(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewCounterVec("dup_total", "First.", "")
+	assert.Panics(t, func() { reg.NewCounterVec("dup_total", "Second.", "") })
+}
+
+func TestHistogramMeanWithoutObservations(t *testing.T) {
+	reg := NewRegistry()
+	h := reg.NewHistogramVec("empty_seconds", "Nothing yet.", "", []float64{1})
+	assert.Equal(t, 0.0, h.Mean())
+}
@@ -4,31 +4,18 @@ import (
 	"sync"
 	"time"
 
-	"github.com/gorilla/websocket"
+	"github.com/thben/clearthedeck/engine"
 )
 
-// Player represents a player in a room
-type Player struct {
-	ID             string          `json:"id"`
-	Name           string          `json:"name"`
-	Connection     *websocket.Conn `json:"-"`
-	JoinedAt       time.Time       `json:"joinedAt"`
-	Hand           []*Card         `json:"hand"`
-	TableCardsUp   []*Card         `json:"tableCardsUp"`
-	TableCardsDown []*Card         `json:"tableCardsDown"`
-	RoundScore     int             `json:"roundScore"` // Points for current round
-	TotalScore     int             `json:"totalScore"` // Cumulative score across all rounds
-}
-
 // Room represents a game room
 type Room struct {
 	ID          string
-	Code        string             `json:"code"`
-	HostID      string             `json:"hostId"`
-	Players     map[string]*Player `json:"players"`
+	Code        string                    `json:"code"`
+	HostID      string                    `json:"hostId"`
+	Players     map[string]*engine.Player `json:"players"`
 	PlayerOrder []string
-	Clients     map[*websocket.Conn]bool
-	CreatedAt   time.Time `json:"createdAt"`
+	Settings    RoomSettings `json:"settings"`
+	CreatedAt   time.Time    `json:"createdAt"`
 	mu          sync.RWMutex
 }
 
@@ -38,15 +25,15 @@ func NewRoom(id, code, hostID string) *Room {
 		ID:          id,
 		Code:        code,
 		HostID:      hostID,
-		Players:     make(map[string]*Player),
+		Players:     make(map[string]*engine.Player),
 		PlayerOrder: []string{},
-		Clients:     make(map[*websocket.Conn]bool),
+		Settings:    DefaultRoomSettings(),
 		CreatedAt:   time.Now(),
 	}
 }
 
 // AddPlayer adds a player to the room
-func (r *Room) AddPlayer(player *Player) {
+func (r *Room) AddPlayer(player *engine.Player) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.Players[player.ID] = player
@@ -67,7 +54,7 @@ func (r *Room) RemovePlayer(playerID string) {
 }
 
 // GetPlayer gets a player by ID
-func (r *Room) GetPlayer(playerID string) (*Player, bool) {
+func (r *Room) GetPlayer(playerID string) (*engine.Player, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	player, ok := r.Players[playerID]
@@ -94,10 +81,10 @@ func (r *Room) HasPlayerWithName(name string) bool {
 }
 
 // GetPlayersInOrder returns players in join order
-func (r *Room) GetPlayersInOrder() []*Player {
+func (r *Room) GetPlayersInOrder() []*engine.Player {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	ordered := make([]*Player, 0, len(r.PlayerOrder))
+	ordered := make([]*engine.Player, 0, len(r.PlayerOrder))
 	for _, id := range r.PlayerOrder {
 		if p, ok := r.Players[id]; ok {
 			ordered = append(ordered, p)
@@ -120,33 +107,53 @@ func (r *Room) SetHostID(id string) {
 	r.HostID = id
 }
 
-// NextHost returns the first player in order, or empty string if none
+// NextHost returns the first present human player in order, or empty string if none
 func (r *Room) NextHost() string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	if len(r.PlayerOrder) == 0 {
-		return ""
+	for _, id := range r.PlayerOrder {
+		if p, ok := r.Players[id]; ok && p.IsPresent() {
+			return id
+		}
 	}
-	return r.PlayerOrder[0]
+	return ""
 }
 
-// AddClient adds a client connection to the room
-func (r *Room) AddClient(conn *websocket.Conn) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.Clients[conn] = true
+// GetPresentCount returns the number of human players actively sitting in the room
+func (r *Room) GetPresentCount() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	count := 0
+	for _, p := range r.Players {
+		if p.IsPresent() {
+			count++
+		}
+	}
+	return count
 }
 
-// RemoveClient removes a client connection from the room
-func (r *Room) RemoveClient(conn *websocket.Conn) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	delete(r.Clients, conn)
+// HasHeldSeats reports whether any seat is being held for a departed player
+func (r *Room) HasHeldSeats() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.Players {
+		if p.Away {
+			return true
+		}
+	}
+	return false
 }
 
-// GetClientCount returns the number of clients in the room
-func (r *Room) GetClientCount() int {
+// GetSettings safely returns the room settings
+func (r *Room) GetSettings() RoomSettings {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	return len(r.Clients)
+	return r.Settings
+}
+
+// SetSettings safely replaces the room settings
+func (r *Room) SetSettings(settings RoomSettings) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Settings = settings
 }
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/thben/clearthedeck/engine"
+)
+
+// DeparturePolicy decides what happens to a player's seat when they leave mid-game
+type DeparturePolicy string
+
+const (
+	// DepartureHoldSeat keeps the seat for a while so the player can rejoin; their turns are skipped meanwhile
+	DepartureHoldSeat DeparturePolicy = "hold"
+	// DepartureBot hands the seat over to a bot that finishes the game for them
+	DepartureBot DeparturePolicy = "bot"
+	// DepartureRedistribute removes the player and deals their remaining cards to the others
+	DepartureRedistribute DeparturePolicy = "redistribute"
+	// DepartureForfeit removes the player and scores their remaining cards as a forfeit
+	DepartureForfeit DeparturePolicy = "forfeit"
+)
+
+// DefaultSeatHoldDuration is how long a held seat waits before the player forfeits
+const DefaultSeatHoldDuration = 2 * time.Minute
+
+// IsValid reports whether the policy is one of the known departure policies
+func (p DeparturePolicy) IsValid() bool {
+	switch p {
+	case DepartureHoldSeat, DepartureBot, DepartureRedistribute, DepartureForfeit:
+		return true
+	}
+	return false
+}
+
+// BotLevel is how strongly bots play for seats they take over
+type BotLevel string
+
+const (
+	BotBasic  BotLevel = "basic"  // Rule-of-thumb play
+	BotEasy   BotLevel = "easy"   // Short search
+	BotMedium BotLevel = "medium" // Longer search
+	BotHard   BotLevel = "hard"   // Longest search; each move takes up to half a second
+)
+
+// IsValid reports whether the level is one of the known bot levels
+func (l BotLevel) IsValid() bool {
+	switch l {
+	case BotBasic, BotEasy, BotMedium, BotHard:
+		return true
+	}
+	return false
+}
+
+// RoomSettings holds the options chosen by the host for a room
+type RoomSettings struct {
+	DeparturePolicy  DeparturePolicy       `json:"departurePolicy"`
+	SeatHoldDuration time.Duration         `json:"seatHoldDuration"`
+	Variant          engine.TableVariant   `json:"variant"`
+	Deal             engine.DealConfig     `json:"deal"` // Non-zero fields override the variant's deal
+	Rules            engine.RuleSet        `json:"rules"`
+	Scoring          engine.ScoringProfile `json:"scoring"`
+	StalemateActions int                   `json:"stalemateActions"` // Actions without progress before a round is ended
+	RoundLimit       int                   `json:"roundLimit"`       // Rounds in a game; 0 plays on until the host ends it
+	BotLevel         BotLevel              `json:"botLevel"`
+	ShowTracker      bool                  `json:"showTracker"` // Send the public card tracker with every game update
+	Coach            bool                  `json:"coach"`       // Players may ask for hints and get a review after each round
+	Testing          bool                  `json:"testing"`     // Testing lobby: the host may load scenarios
+}
+
+// DefaultRoomSettings returns the settings a new room starts with
+func DefaultRoomSettings() RoomSettings {
+	return RoomSettings{
+		DeparturePolicy:  DepartureHoldSeat,
+		SeatHoldDuration: DefaultSeatHoldDuration,
+		Variant:          engine.VariantAuto,
+		Rules:            engine.DefaultRuleSet(),
+		Scoring:          engine.DefaultScoringProfile(),
+		StalemateActions: engine.DefaultStalemateActions,
+		BotLevel:         BotBasic,
+	}
+}
@@ -0,0 +1,203 @@
+// Package notation writes and reads matches in a compact text form that can be pasted into bug reports
+//
+// A match lists the seats and rules, then each round's deal and the moves made in it:
+//
+//	rules: standard
+//	seats: Alice, Bob, Carol
+//	R1 D:Alice
+//	deal Alice: down 3H 9S 2C KD / up 7H 7D QS 4C / hand 2H 5C 9D
+//	deal Bob: ...
+//	| Bob: 7H 7D (hand) -> over; Carol: 10S -> clear; Carol: pickup
+//	| Alice: flip 4S -> miss
+//	end: won Carol
+//
+// Statements are separated by new lines, "|" or ";" and anything after "#" is a comment.
+// Names that could be misread, such as ones holding any of , | ; # : or looking like a round header,
+// are written in double quotes with Go escapes: seats: "Smith, J", "R2".
+// Cards are a value and a suit letter (7H, 10S, QD); moves may leave the suit out to mean any card of that value.
+// The player left of the dealer starts unless the round header names another with T:.
+package notation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/thben/clearthedeck/engine"
+)
+
+// MoveKind is what a move did
+type MoveKind string
+
+const (
+	MovePlay   MoveKind = "play"
+	MoveFlip   MoveKind = "flip"
+	MovePickup MoveKind = "pickup"
+)
+
+// Outcomes a move may be annotated with
+const (
+	OutcomeClear = "clear" // The pile was cleared by a wild or a set
+	OutcomeOver  = "over"  // An over-value play picked up the lower cards
+	OutcomeMiss  = "miss"  // A flipped card could not be played and went into the hand
+)
+
+// Card sources
+const (
+	FromHand = "hand"
+	FromUp   = "up"
+)
+
+// Card is a card as written in a match
+type Card struct {
+	Value  string // A, 2-10, J, Q, K
+	Suit   string // Hearts, Diamonds, Clubs or Spades; empty means any suit
+	Source string // Where a played card came from; empty lets the replay look in the hand, then face-up
+}
+
+// Hand is the cards one player was dealt
+type Hand struct {
+	Player string
+	Down   []Card
+	Up     []Card
+	Hand   []Card
+}
+
+// Move is one action by a player
+type Move struct {
+	Player   string
+	Kind     MoveKind
+	Cards    []Card // Cards played, or the face-down card flipped
+	Outcomes []string
+}
+
+// Round is one deal and the moves made in it
+type Round struct {
+	Number int
+	Dealer string
+	First  string // Player to act first; empty means left of the dealer
+	Deal   []Hand
+	Moves  []Move
+	End    string // won or a stalemate reason; empty while the round is running
+	Winner string
+}
+
+// Record is a whole match
+type Record struct {
+	Rules  string // Rule set preset; empty is the standard rules
+	Seats  []string
+	Rounds []Round
+}
+
+var suitLetters = map[string]string{"Hearts": "H", "Diamonds": "D", "Clubs": "C", "Spades": "S"}
+
+// String writes a card as its value and suit letter
+func (c Card) String() string {
+	return c.Value + suitLetters[c.Suit]
+}
+
+// FromModel converts a game card
+func FromModel(card *engine.Card) Card {
+	return Card{Value: card.Value, Suit: card.Suit}
+}
+
+// Matches reports whether a game card is the card written, treating a missing suit as any suit
+func (c Card) Matches(card *engine.Card) bool {
+	return card.Value == c.Value && (c.Suit == "" || card.Suit == c.Suit)
+}
+
+// Format writes a record in match notation
+func Format(rec Record) string {
+	var b strings.Builder
+	if rec.Rules != "" {
+		fmt.Fprintf(&b, "rules: %s\n", rec.Rules)
+	}
+	seats := make([]string, len(rec.Seats))
+	for i, seat := range rec.Seats {
+		seats[i] = quoteName(seat)
+	}
+	fmt.Fprintf(&b, "seats: %s\n", strings.Join(seats, ", "))
+	for _, round := range rec.Rounds {
+		fmt.Fprintf(&b, "R%d D:%s", round.Number, quoteName(round.Dealer))
+		if round.First != "" {
+			fmt.Fprintf(&b, " T:%s", quoteName(round.First))
+		}
+		b.WriteString("\n")
+		for _, hand := range round.Deal {
+			fmt.Fprintf(&b, "deal %s: down %s / up %s / hand %s\n", quoteName(hand.Player), cardList(hand.Down), cardList(hand.Up), cardList(hand.Hand))
+		}
+		for _, move := range round.Moves {
+			fmt.Fprintf(&b, "| %s\n", move)
+		}
+		if round.End != "" {
+			b.WriteString("end: " + round.End)
+			if round.Winner != "" {
+				b.WriteString(" " + quoteName(round.Winner))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// String writes a move as it appears after "|"
+func (m Move) String() string {
+	var b strings.Builder
+	b.WriteString(quoteName(m.Player) + ": ")
+	switch m.Kind {
+	case MovePickup:
+		b.WriteString("pickup")
+	case MoveFlip:
+		b.WriteString("flip")
+		if len(m.Cards) > 0 {
+			b.WriteString(" " + m.Cards[0].String())
+		}
+	default:
+		// Source markers cover the cards written since the previous marker
+		for i, card := range m.Cards {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			b.WriteString(card.String())
+			if card.Source != "" && (i == len(m.Cards)-1 || m.Cards[i+1].Source != card.Source) {
+				b.WriteString(" (" + card.Source + ")")
+			}
+		}
+	}
+	if len(m.Outcomes) > 0 {
+		b.WriteString(" -> " + strings.Join(m.Outcomes, ", "))
+	}
+	return b.String()
+}
+
+func cardList(cards []Card) string {
+	if len(cards) == 0 {
+		return "-"
+	}
+	parts := make([]string, len(cards))
+	for i, card := range cards {
+		parts[i] = card.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteName writes a player name, quoting it when it would otherwise be read as something else
+func quoteName(name string) string {
+	if needsQuotes(name) {
+		return strconv.Quote(name)
+	}
+	return name
+}
+
+func needsQuotes(name string) bool {
+	switch {
+	case name == "", strings.ContainsAny(name, ",|;#:\"\\"):
+		return true
+	case strings.Join(strings.Fields(name), " ") != name:
+		// Spacing other than single spaces between words would be lost
+		return true
+	case isRoundHeader(name), name == "rules", name == "seats", name == "end", strings.HasPrefix(name, "deal "):
+		return true
+	}
+	return false
+}
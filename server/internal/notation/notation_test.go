@@ -0,0 +1,265 @@
+package notation
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thben/clearthedeck/engine"
+)
+
+// A bug report: "I played two 7s on a 9 and it ate my pile"
+const disputed = `
+# Reported from a three-player table
+seats: Alice, Bob, Carol
+R1 D:Alice
+deal Alice: down KD / up 4C / hand 9S 3H 5D
+deal Bob: down 2C / up QS / hand 7H 7D JH
+deal Carol: down 6S / up 8H / hand 10S 4H 6H
+| Bob: JH; Carol: 4H; Alice: 9S -> over
+| Bob: 7 7 (hand)
+`
+
+func names(cards []*engine.Card) []string {
+	out := make([]string, len(cards))
+	for i, c := range cards {
+		out[i] = FromModel(c).String()
+	}
+	return out
+}
+
+func TestParse(t *testing.T) {
+	rec, err := Parse(disputed)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Alice", "Bob", "Carol"}, rec.Seats)
+	require.Len(t, rec.Rounds, 1)
+	round := rec.Rounds[0]
+	assert.Equal(t, 1, round.Number)
+	assert.Equal(t, "Alice", round.Dealer)
+	require.Len(t, round.Deal, 3)
+	assert.Equal(t, []Card{{Value: "K", Suit: "Diamonds"}}, round.Deal[0].Down)
+	require.Len(t, round.Moves, 4)
+	assert.Equal(t, Move{Player: "Alice", Kind: MovePlay, Cards: []Card{{Value: "9", Suit: "Spades"}}, Outcomes: []string{OutcomeOver}}, round.Moves[2])
+	assert.Equal(t, Move{Player: "Bob", Kind: MovePlay, Cards: []Card{{Value: "7", Source: FromHand}, {Value: "7", Source: FromHand}}}, round.Moves[3])
+
+	t.Run("short form from a ticket", func(t *testing.T) {
+		rec, err := Parse("seats: Alice, Bob, Carol\nR1 D:Alice | Bob: 7 7 (hand) -> over; Carol: 10 clear; Carol: pickup; Alice: flip")
+		require.NoError(t, err)
+		moves := rec.Rounds[0].Moves
+		require.Len(t, moves, 4)
+		assert.Equal(t, []string{OutcomeOver}, moves[0].Outcomes)
+		assert.Equal(t, Move{Player: "Carol", Kind: MovePlay, Cards: []Card{{Value: "10"}}, Outcomes: []string{OutcomeClear}}, moves[1])
+		assert.Equal(t, MovePickup, moves[2].Kind)
+		assert.Equal(t, Move{Player: "Alice", Kind: MoveFlip}, moves[3])
+	})
+
+	t.Run("errors name the line", func(t *testing.T) {
+		for text, want := range map[string]string{
+			"R1 D:Alice":                                    "line 1",
+			"seats: Alice, Bob\nR1 D:Zed":                   "\"Zed\" is not seated",
+			"seats: Alice, Bob\nR1 D:Alice\nBob: 7X":        "line 3: \"7X\" is not a card",
+			"seats: Alice, Bob\nBob: 7H":                    "comes before any round header",
+			"seats: Alice\nR1 D:Alice\ndeal Alice: left 7H": "unknown deal area",
+		} {
+			_, err := Parse(text)
+			assert.ErrorContains(t, err, want, text)
+		}
+	})
+}
+
+func TestReplay(t *testing.T) {
+	rec, err := Parse(disputed)
+	require.NoError(t, err)
+
+	game, err := Replay(rec)
+	require.NoError(t, err)
+
+	// Two 7s under a 9 are lower, so nothing was picked up: Bob only lost his 7s
+	assert.Equal(t, []string{"9S", "7H", "7D"}, names(game.CenterPile))
+	assert.Empty(t, game.Players[1].Hand)
+	assert.ElementsMatch(t, []string{"3H", "5D", "JH", "4H"}, names(game.Players[0].Hand))
+	assert.Equal(t, "Carol", game.GetCurrentPlayer().Name)
+
+	t.Run("a disputed outcome is reported", func(t *testing.T) {
+		rec, err := Parse(disputed + "\n| Carol: 6H -> over")
+		require.NoError(t, err)
+
+		_, err = Replay(rec)
+		assert.ErrorContains(t, err, "R1 move 5 (Carol: 6H -> over): expected over but the move gave nothing special")
+	})
+
+	t.Run("illegal moves are reported", func(t *testing.T) {
+		rec, err := Parse(disputed + "\n| Alice: 3H")
+		require.NoError(t, err)
+
+		_, err = Replay(rec)
+		assert.ErrorContains(t, err, "not your turn")
+	})
+
+	t.Run("missing cards are reported", func(t *testing.T) {
+		rec, err := Parse(disputed + "\n| Carol: KH")
+		require.NoError(t, err)
+
+		_, err = Replay(rec)
+		assert.ErrorContains(t, err, "Carol has no KH in hand or face-up")
+	})
+
+	t.Run("the round end is checked", func(t *testing.T) {
+		rec, err := Parse(disputed + "\nend: won Bob")
+		require.NoError(t, err)
+
+		_, err = Replay(rec)
+		assert.ErrorContains(t, err, "expected the round to end (won) but it is still running")
+	})
+}
+
+// playRandomly plays legal moves until the game has seen the number of rounds or the move limit is reached
+func playRandomly(t *testing.T, state engine.State, rec *Recorder, rounds int) engine.State {
+	t.Helper()
+	rng := rand.New(rand.NewSource(7))
+	for moves := 0; moves < 2000; moves++ {
+		action := engine.Action{Type: engine.ActionNextRound, Seed: int64(moves + 1)}
+		if state.RoundOver() {
+			if state.Round() >= rounds {
+				return state
+			}
+		} else {
+			legal := engine.LegalActions(state, state.CurrentPlayerID())
+			require.NotEmpty(t, legal)
+			action = legal[rng.Intn(len(legal))]
+		}
+		next, events, err := engine.Apply(state, action)
+		require.NoError(t, err)
+		rec.Add(next, events)
+		state = next
+	}
+	return state
+}
+
+func TestRecorderRoundTrip(t *testing.T) {
+	seats := []engine.Seat{{ID: "a", Name: "Alice"}, {ID: "b", Name: "Bob"}, {ID: "c", Name: "Carol: the third"}}
+	start, err := engine.NewState(seats, engine.Config{Seed: 11})
+	require.NoError(t, err)
+
+	rec := NewRecorder(start)
+	final := playRandomly(t, start, rec, 2).Game()
+
+	text := rec.String()
+	parsed, err := Parse(text)
+	require.NoError(t, err, text)
+	assert.Equal(t, []string{"Alice", "Bob", "Carol: the third"}, parsed.Seats)
+	require.Len(t, parsed.Rounds, 2)
+	assert.NotEmpty(t, parsed.Rounds[1].End, "both rounds are scored")
+
+	replayed, err := Replay(parsed)
+	require.NoError(t, err, text)
+
+	assert.Equal(t, final.Round, replayed.Round)
+	assert.Equal(t, names(final.CenterPile), names(replayed.CenterPile))
+	for i, p := range final.Players {
+		r := replayed.Players[i]
+		assert.Equal(t, names(p.Hand), names(r.Hand), p.Name)
+		assert.Equal(t, names(p.TableCardsUp), names(r.TableCardsUp), p.Name)
+		assert.Equal(t, names(p.TableCardsDown), names(r.TableCardsDown), p.Name)
+		assert.Equal(t, p.TotalScore, r.TotalScore, p.Name)
+	}
+}
+
+func TestRecorderRewind(t *testing.T) {
+	seats := []engine.Seat{{ID: "a", Name: "Alice"}, {ID: "b", Name: "Bob"}}
+	start, err := engine.NewState(seats, engine.Config{Seed: 5})
+	require.NoError(t, err)
+	rec := NewRecorder(start)
+
+	// Three moves are made and the last two taken back
+	state := start
+	var kept engine.State
+	for i := 0; i < 3; i++ {
+		legal := engine.LegalActions(state, state.CurrentPlayerID())
+		require.NotEmpty(t, legal)
+		next, events, err := engine.Apply(state, legal[len(legal)-1])
+		require.NoError(t, err)
+		rec.Add(next, events)
+		state = next
+		if i == 0 {
+			kept = state
+		}
+	}
+	rec.Rewind(kept)
+	require.Len(t, rec.Record().Rounds[0].Moves, 1)
+
+	final := playRandomly(t, kept, rec, 1).Game()
+	replayed, err := Replay(rec.Record())
+	require.NoError(t, err, rec.String())
+	for i, p := range final.Players {
+		assert.Equal(t, p.TotalScore, replayed.Players[i].TotalScore, p.Name)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	rec := Record{
+		Rules: engine.RulesStrict,
+		Seats: []string{"Alice", "Bob"},
+		Rounds: []Round{{
+			Number: 1,
+			Dealer: "Alice",
+			First:  "Alice",
+			Deal: []Hand{
+				{Player: "Alice", Up: []Card{{Value: "4", Suit: "Clubs"}}, Hand: []Card{{Value: "9", Suit: "Spades"}}},
+				{Player: "Bob", Down: []Card{{Value: "2", Suit: "Clubs"}}, Hand: []Card{{Value: "7", Suit: "Hearts"}}},
+			},
+			Moves: []Move{
+				{Player: "Alice", Kind: MovePlay, Cards: []Card{{Value: "9", Suit: "Spades", Source: FromHand}, {Value: "4", Suit: "Clubs", Source: FromUp}}, Outcomes: []string{OutcomeOver}},
+				{Player: "Bob", Kind: MoveFlip, Cards: []Card{{Value: "2", Suit: "Clubs"}}, Outcomes: []string{OutcomeMiss}},
+				{Player: "Bob", Kind: MovePickup},
+			},
+			End:    "won",
+			Winner: "Alice",
+		}},
+	}
+
+	text := Format(rec)
+	assert.Equal(t, `rules: strict
+seats: Alice, Bob
+R1 D:Alice T:Alice
+deal Alice: down - / up 4C / hand 9S
+deal Bob: down 2C / up - / hand 7H
+| Alice: 9S (hand) 4C (up) -> over
+| Bob: flip 2C -> miss
+| Bob: pickup
+end: won Alice
+`, text)
+
+	parsed, err := Parse(text)
+	require.NoError(t, err)
+	rec.Rounds[0].Deal[0].Down = nil
+	rec.Rounds[0].Deal[1].Up = nil
+	assert.Equal(t, rec, parsed)
+}
+
+func TestNamesRoundTrip(t *testing.T) {
+	awkward := []string{"Smith, J", "a|b", "x;y", "#1", "Carol: the third", "R2", "deal me", `say "hi"`, "two  spaces"}
+	seats := make([]engine.Seat, len(awkward))
+	for i, name := range awkward {
+		seats[i] = engine.Seat{ID: string(rune('a' + i)), Name: name}
+	}
+	start, err := engine.NewState(seats, engine.Config{Seed: 7})
+	require.NoError(t, err)
+
+	rec := NewRecorder(start)
+	final := playRandomly(t, start, rec, 1).Game()
+
+	text := rec.String()
+	parsed, err := Parse(text)
+	require.NoError(t, err, text)
+	assert.Equal(t, rec.Record(), parsed)
+
+	replayed, err := Replay(parsed)
+	require.NoError(t, err, text)
+	for i, p := range final.Players {
+		assert.Equal(t, p.Name, replayed.Players[i].Name)
+		assert.Equal(t, p.TotalScore, replayed.Players[i].TotalScore, p.Name)
+	}
+}
@@ -0,0 +1,324 @@
+package notation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var suitNames = map[string]string{"H": "Hearts", "D": "Diamonds", "C": "Clubs", "S": "Spades"}
+
+var cardValues = map[string]bool{
+	"A": true, "2": true, "3": true, "4": true, "5": true, "6": true, "7": true,
+	"8": true, "9": true, "10": true, "J": true, "Q": true, "K": true,
+}
+
+// Parse reads a match written in notation
+func Parse(text string) (Record, error) {
+	var rec Record
+	var round *Round
+	for lineNo, line := range strings.Split(text, "\n") {
+		if i := indexOutside(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		for _, stmt := range splitOutside(line, func(r rune) bool { return r == '|' || r == ';' }) {
+			stmt = strings.TrimSpace(stmt)
+			if stmt == "" {
+				continue
+			}
+			if err := parseStatement(&rec, &round, stmt); err != nil {
+				return Record{}, fmt.Errorf("line %d: %w", lineNo+1, err)
+			}
+		}
+	}
+	if len(rec.Seats) == 0 {
+		return Record{}, fmt.Errorf("seats are required")
+	}
+	return rec, nil
+}
+
+func parseStatement(rec *Record, round **Round, stmt string) error {
+	head, rest, hasColon := stmt, "", false
+	if i := indexOutside(stmt, ':'); i >= 0 {
+		head, rest, hasColon = stmt[:i], stmt[i+1:], true
+	}
+	head = strings.TrimSpace(head)
+	rest = strings.TrimSpace(rest)
+
+	switch {
+	case isRoundHeader(stmt):
+		return parseRoundHeader(rec, round, stmt)
+	case hasColon && head == "rules":
+		rec.Rules = rest
+		return nil
+	case hasColon && head == "seats":
+		if len(rec.Rounds) > 0 {
+			return fmt.Errorf("seats must come before the first round")
+		}
+		for _, name := range splitOutside(rest, func(r rune) bool { return r == ',' }) {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			name, err := unquoteName(name)
+			if err != nil {
+				return err
+			}
+			rec.Seats = append(rec.Seats, name)
+		}
+		return nil
+	}
+
+	if *round == nil {
+		return fmt.Errorf("%q comes before any round header", stmt)
+	}
+	r := *round
+	switch {
+	case hasColon && strings.HasPrefix(head, "deal "):
+		player, err := unquoteName(strings.TrimSpace(strings.TrimPrefix(head, "deal ")))
+		if err != nil {
+			return err
+		}
+		return parseDeal(rec, r, player, rest)
+	case hasColon && head == "end":
+		fields := fieldsOutside(rest)
+		if len(fields) == 0 {
+			return fmt.Errorf("end needs a reason")
+		}
+		winner, err := unquoteName(strings.Join(fields[1:], " "))
+		if err != nil {
+			return err
+		}
+		r.End, r.Winner = fields[0], winner
+		return nil
+	case hasColon:
+		player, err := unquoteName(head)
+		if err != nil {
+			return err
+		}
+		if err := checkSeat(rec, player); err != nil {
+			return err
+		}
+		move, err := parseMove(player, rest)
+		if err != nil {
+			return err
+		}
+		r.Moves = append(r.Moves, move)
+		return nil
+	}
+	return fmt.Errorf("cannot read %q", stmt)
+}
+
+// isRoundHeader reports whether a statement starts a round, like "R2 D:Bob"
+func isRoundHeader(stmt string) bool {
+	first := strings.Fields(stmt)[0]
+	if len(first) < 2 || first[0] != 'R' {
+		return false
+	}
+	_, err := strconv.Atoi(first[1:])
+	return err == nil
+}
+
+func parseRoundHeader(rec *Record, round **Round, stmt string) error {
+	fields := fieldsOutside(stmt)
+	number, _ := strconv.Atoi(fields[0][1:])
+	r := Round{Number: number}
+
+	// Names may contain spaces, so D: and T: run until the next tag
+	tag := ""
+	for _, field := range fields[1:] {
+		switch {
+		case strings.HasPrefix(field, "D:"):
+			tag, r.Dealer = "D", strings.TrimPrefix(field, "D:")
+		case strings.HasPrefix(field, "T:"):
+			tag, r.First = "T", strings.TrimPrefix(field, "T:")
+		case tag == "D":
+			r.Dealer += " " + field
+		case tag == "T":
+			r.First += " " + field
+		default:
+			return fmt.Errorf("cannot read %q in round header", field)
+		}
+	}
+	if r.Dealer == "" {
+		return fmt.Errorf("round %d needs a dealer (D:name)", number)
+	}
+	for _, name := range []*string{&r.Dealer, &r.First} {
+		if *name == "" {
+			continue
+		}
+		unquoted, err := unquoteName(*name)
+		if err != nil {
+			return err
+		}
+		if err := checkSeat(rec, unquoted); err != nil {
+			return err
+		}
+		*name = unquoted
+	}
+
+	rec.Rounds = append(rec.Rounds, r)
+	*round = &rec.Rounds[len(rec.Rounds)-1]
+	return nil
+}
+
+func parseDeal(rec *Record, r *Round, player, areas string) error {
+	if err := checkSeat(rec, player); err != nil {
+		return err
+	}
+	hand := Hand{Player: player}
+	for _, area := range strings.Split(areas, "/") {
+		fields := strings.Fields(area)
+		if len(fields) == 0 {
+			continue
+		}
+		var cards []Card
+		for _, field := range fields[1:] {
+			if field == "-" {
+				continue
+			}
+			card, err := ParseCard(field)
+			if err != nil {
+				return err
+			}
+			cards = append(cards, card)
+		}
+		switch fields[0] {
+		case "down":
+			hand.Down = cards
+		case "up":
+			hand.Up = cards
+		case "hand":
+			hand.Hand = cards
+		default:
+			return fmt.Errorf("unknown deal area %q, expected down, up or hand", fields[0])
+		}
+	}
+	r.Deal = append(r.Deal, hand)
+	return nil
+}
+
+func parseMove(player, text string) (Move, error) {
+	move := Move{Player: player, Kind: MovePlay}
+	text = strings.Replace(text, "->", " ", 1)
+	text = strings.ReplaceAll(text, ",", " ")
+
+	pending := 0 // Cards written since the last source marker
+	for i, field := range strings.Fields(text) {
+		switch field {
+		case "pickup":
+			if i == 0 {
+				move.Kind = MovePickup
+				continue
+			}
+		case "flip":
+			if i == 0 {
+				move.Kind = MoveFlip
+				continue
+			}
+		case OutcomeClear, OutcomeOver, OutcomeMiss:
+			move.Outcomes = append(move.Outcomes, field)
+			continue
+		case "(" + FromHand + ")", "(" + FromUp + ")":
+			source := strings.Trim(field, "()")
+			for j := len(move.Cards) - pending; j < len(move.Cards); j++ {
+				move.Cards[j].Source = source
+			}
+			pending = 0
+			continue
+		}
+		card, err := ParseCard(field)
+		if err != nil {
+			return Move{}, err
+		}
+		move.Cards = append(move.Cards, card)
+		pending++
+	}
+
+	switch {
+	case move.Kind == MovePlay && len(move.Cards) == 0:
+		return Move{}, fmt.Errorf("%s plays no cards", player)
+	case move.Kind == MoveFlip && len(move.Cards) > 1:
+		return Move{}, fmt.Errorf("%s flips more than one card", player)
+	case move.Kind == MovePickup && len(move.Cards) > 0:
+		return Move{}, fmt.Errorf("%s picks up with cards listed", player)
+	}
+	return move, nil
+}
+
+// ParseCard reads a card such as 7H, 10S or a bare value such as Q, which has no suit
+func ParseCard(text string) (Card, error) {
+	upper := strings.ToUpper(text)
+	if cardValues[upper] {
+		return Card{Value: upper}, nil
+	}
+	if len(upper) >= 2 {
+		value, suit := upper[:len(upper)-1], upper[len(upper)-1:]
+		if name, ok := suitNames[suit]; ok && cardValues[value] {
+			return Card{Value: value, Suit: name}, nil
+		}
+	}
+	return Card{}, fmt.Errorf("%q is not a card", text)
+}
+
+func checkSeat(rec *Record, name string) error {
+	for _, seat := range rec.Seats {
+		if seat == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not seated", name)
+}
+
+// unquoteName reads a player name, which may be written in double quotes
+func unquoteName(text string) (string, error) {
+	if !strings.HasPrefix(text, `"`) {
+		return text, nil
+	}
+	name, err := strconv.Unquote(text)
+	if err != nil {
+		return "", fmt.Errorf("cannot read name %s", text)
+	}
+	return name, nil
+}
+
+// indexOutside returns the index of the first target rune that is not inside a quoted name, or -1
+func indexOutside(text string, target rune) int {
+	parts := splitOutside(text, func(r rune) bool { return r == target })
+	if len(parts) == 1 {
+		return -1
+	}
+	return len(parts[0])
+}
+
+// splitOutside splits text around the runes sep matches, leaving quoted names whole
+func splitOutside(text string, sep func(rune) bool) []string {
+	var parts []string
+	start, quoted, escaped := 0, false, false
+	for i, r := range text {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case !quoted && sep(r):
+			parts = append(parts, text[start:i])
+			start = i + utf8.RuneLen(r)
+		}
+	}
+	return append(parts, text[start:])
+}
+
+// fieldsOutside splits text around spaces like strings.Fields, leaving quoted names whole
+func fieldsOutside(text string) []string {
+	var fields []string
+	for _, field := range splitOutside(text, unicode.IsSpace) {
+		if field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,138 @@
+package notation
+
+import "github.com/thben/clearthedeck/engine"
+
+// Recorder builds a record from a game's event stream
+type Recorder struct {
+	rec   Record
+	names map[string]string // Player ID to seat name
+	up    map[string]bool   // Face-up cards not yet played
+}
+
+// NewRecorder starts recording a game from the deal of its current round
+func NewRecorder(state engine.State) *Recorder {
+	game := state.Game()
+	r := &Recorder{names: make(map[string]string)}
+	if name := game.ActiveRules().Name; name != engine.RulesStandard {
+		r.rec.Rules = name
+	}
+	for _, p := range game.Players {
+		r.names[p.ID] = p.Name
+		r.rec.Seats = append(r.rec.Seats, r.names[p.ID])
+	}
+	r.startRound(game)
+	return r
+}
+
+// Add records what one action did; state is the position after it
+func (r *Recorder) Add(state engine.State, events []engine.Event) {
+	var move *Move
+	flipped := false
+	for _, event := range events {
+		switch event.Type {
+		case engine.EventCardFlipped:
+			move = r.addMove(Move{Player: r.names[event.PlayerID], Kind: MoveFlip, Cards: cards(event.Cards)})
+			flipped = true
+		case engine.EventCardsPlayed:
+			if flipped {
+				continue // The flipped card going onto the pile is part of the flip
+			}
+			played := cards(event.Cards)
+			for i, card := range event.Cards {
+				played[i].Source = FromHand
+				if r.up[card.ID] {
+					played[i].Source = FromUp
+					delete(r.up, card.ID)
+				}
+			}
+			move = r.addMove(Move{Player: r.names[event.PlayerID], Kind: MovePlay, Cards: played})
+		case engine.EventPilePickedUp:
+			if move == nil {
+				move = r.addMove(Move{Player: r.names[event.PlayerID], Kind: MovePickup})
+			}
+		case engine.EventRoundEnded:
+			round := r.round()
+			round.End = event.Reason
+			round.Winner = r.names[event.PlayerID]
+		case engine.EventRoundStarted:
+			r.startRound(state.Game())
+		}
+	}
+	if move != nil {
+		move.Outcomes = outcomes(events)
+	}
+}
+
+// Rewind drops the moves of the current round that the position has not seen, as when actions are undone
+func (r *Recorder) Rewind(state engine.State) {
+	game := state.Game()
+	round := r.round()
+	if round == nil || round.Number != game.Round {
+		return
+	}
+	if n := game.Progress.Actions; n < len(round.Moves) {
+		round.Moves = round.Moves[:n]
+	}
+	// Face-up cards played by the undone moves are back on the table
+	r.up = make(map[string]bool)
+	for _, p := range game.Players {
+		for _, c := range p.TableCardsUp {
+			r.up[c.ID] = true
+		}
+	}
+}
+
+// Record returns the match so far
+func (r *Recorder) Record() Record {
+	return r.rec
+}
+
+// String writes the match so far in notation
+func (r *Recorder) String() string {
+	return Format(r.rec)
+}
+
+func (r *Recorder) startRound(game *engine.Game) {
+	round := Round{Number: game.Round}
+	n := len(game.Players)
+	if n > 0 {
+		round.Dealer = r.names[game.Players[game.DealerIndex%n].ID]
+		if first := game.CurrentPlayerIndex; first != (game.DealerIndex+1)%n {
+			round.First = r.names[game.Players[first].ID]
+		}
+	}
+	r.up = make(map[string]bool)
+	for _, p := range game.Players {
+		round.Deal = append(round.Deal, Hand{
+			Player: r.names[p.ID],
+			Down:   cards(p.TableCardsDown),
+			Up:     cards(p.TableCardsUp),
+			Hand:   cards(p.Hand),
+		})
+		for _, c := range p.TableCardsUp {
+			r.up[c.ID] = true
+		}
+	}
+	r.rec.Rounds = append(r.rec.Rounds, round)
+}
+
+func (r *Recorder) round() *Round {
+	if len(r.rec.Rounds) == 0 {
+		return nil
+	}
+	return &r.rec.Rounds[len(r.rec.Rounds)-1]
+}
+
+func (r *Recorder) addMove(move Move) *Move {
+	round := r.round()
+	round.Moves = append(round.Moves, move)
+	return &round.Moves[len(round.Moves)-1]
+}
+
+func cards(in []*engine.Card) []Card {
+	out := make([]Card, len(in))
+	for i, c := range in {
+		out[i] = FromModel(c)
+	}
+	return out
+}
@@ -0,0 +1,280 @@
+package notation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/thben/clearthedeck/engine"
+)
+
+// cardsPerDeck is the size of the decks a deal is assumed to come from
+const cardsPerDeck = 52
+
+// Replay deals and plays a record, returning the position it ends in
+// Players are identified by their seat names. Outcomes and round ends written in the record are checked
+// against what the rules actually did, so a record doubles as a regression test
+func Replay(rec Record) (*engine.Game, error) {
+	if len(rec.Seats) == 0 {
+		return nil, fmt.Errorf("seats are required")
+	}
+	rules := engine.DefaultRuleSet()
+	if rec.Rules != "" {
+		var ok bool
+		if rules, ok = engine.LookupRuleSet(rec.Rules); !ok {
+			return nil, fmt.Errorf("unknown rule set %q", rec.Rules)
+		}
+	}
+
+	players := make([]*engine.Player, len(rec.Seats))
+	for i, name := range rec.Seats {
+		players[i] = &engine.Player{ID: name, Name: name}
+	}
+	game := engine.NewGame("", "", players)
+	game.Rules = rules
+	game.IsStarted = true
+	if len(rec.Rounds) == 0 {
+		return game, nil
+	}
+
+	var state engine.State
+	for i, round := range rec.Rounds {
+		if i > 0 && !state.RoundOver() {
+			return nil, fmt.Errorf("R%d: round %d has not ended", round.Number, rec.Rounds[i-1].Number)
+		}
+		if i > 0 {
+			game = state.Game()
+		}
+		if err := deal(game, round); err != nil {
+			return nil, fmt.Errorf("R%d: %w", round.Number, err)
+		}
+		state = engine.FromGame(game)
+
+		for n, move := range round.Moves {
+			next, err := replayMove(state, move)
+			if err != nil {
+				return nil, fmt.Errorf("R%d move %d (%s): %w", round.Number, n+1, move, err)
+			}
+			state = next
+		}
+
+		if err := checkEnd(state, round); err != nil {
+			return nil, fmt.Errorf("R%d: %w", round.Number, err)
+		}
+	}
+	return state.Game(), nil
+}
+
+// deal lays out a round's cards exactly as written
+func deal(game *engine.Game, round Round) error {
+	hands := make(map[string]Hand, len(round.Deal))
+	for _, hand := range round.Deal {
+		hands[hand.Player] = hand
+	}
+
+	nextID := 0
+	toModels := func(cards []Card) []*engine.Card {
+		out := make([]*engine.Card, len(cards))
+		for i, card := range cards {
+			out[i] = engine.NewCard(fmt.Sprintf("card-%d", nextID), card.Suit, card.Value)
+			nextID++
+		}
+		return out
+	}
+
+	cfg := engine.DealConfig{}
+	for i, player := range game.Players {
+		hand, ok := hands[player.Name]
+		if !ok {
+			return fmt.Errorf("no deal for %s", player.Name)
+		}
+		player.TableCardsDown = toModels(hand.Down)
+		player.TableCardsUp = toModels(hand.Up)
+		player.Hand = toModels(hand.Hand)
+		player.RoundScore = 0
+		player.FlipMisses = 0
+		if i == 0 {
+			cfg = engine.DealConfig{FaceDown: len(hand.Down), FaceUp: len(hand.Up), Hand: len(hand.Hand)}
+		}
+	}
+	cfg.Decks = (nextID + cardsPerDeck - 1) / cardsPerDeck
+
+	dealer := game.PlayerIndex(round.Dealer)
+	first := (dealer + 1) % len(game.Players)
+	if round.First != "" {
+		first = game.PlayerIndex(round.First)
+	}
+
+	game.Round = round.Number
+	game.Deal = cfg
+	game.DealerIndex = dealer
+	game.CurrentPlayerIndex = first
+	game.CenterPile = []*engine.Card{}
+	game.DiscardPile = []*engine.Card{}
+	game.AfterPickup = false
+	game.SetLastClearMessage("")
+	game.IsFinished = false
+	game.ResetProgress()
+	return nil
+}
+
+// replayMove finds the cards a move names and applies it
+func replayMove(state engine.State, move Move) (engine.State, error) {
+	game := state.Game()
+	i := game.PlayerIndex(move.Player)
+	if i < 0 {
+		return state, fmt.Errorf("%s is not at the table", move.Player)
+	}
+	player := game.Players[i]
+
+	action := engine.Action{PlayerID: player.ID}
+	switch move.Kind {
+	case MovePickup:
+		action.Type = engine.ActionPickup
+	case MoveFlip:
+		action.Type = engine.ActionFlip
+		id, err := findFaceDown(player, move.Cards)
+		if err != nil {
+			return state, err
+		}
+		action.CardIDs = []string{id}
+	default:
+		action.Type = engine.ActionPlay
+		ids, err := findPlayed(player, move.Cards)
+		if err != nil {
+			return state, err
+		}
+		action.CardIDs = ids
+	}
+
+	next, events, err := engine.Apply(state, action)
+	if err != nil {
+		return state, err
+	}
+	if len(move.Outcomes) > 0 {
+		want, got := sortedCopy(move.Outcomes), sortedCopy(outcomes(events))
+		if strings.Join(want, ",") != strings.Join(got, ",") {
+			return state, fmt.Errorf("expected %s but the move gave %s", describe(want), describe(got))
+		}
+	}
+	return next, nil
+}
+
+// findPlayed picks distinct cards for a play, looking where each card's source says
+func findPlayed(player *engine.Player, cards []Card) ([]string, error) {
+	used := make(map[string]bool)
+	ids := make([]string, 0, len(cards))
+	for _, card := range cards {
+		var areas [][]*engine.Card
+		switch card.Source {
+		case FromHand:
+			areas = [][]*engine.Card{player.Hand}
+		case FromUp:
+			areas = [][]*engine.Card{player.TableCardsUp}
+		default:
+			areas = [][]*engine.Card{player.Hand, player.TableCardsUp}
+		}
+		id := ""
+		for _, area := range areas {
+			for _, c := range area {
+				if !used[c.ID] && card.Matches(c) {
+					id = c.ID
+					break
+				}
+			}
+			if id != "" {
+				break
+			}
+		}
+		if id == "" {
+			where := card.Source
+			if where == "" {
+				where = "hand or face-up"
+			}
+			return nil, fmt.Errorf("%s has no %s in %s", player.Name, card, where)
+		}
+		used[id] = true
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// findFaceDown picks the face-down card a flip names, or the first one that may be flipped
+// With several decks a player can hold two copies of a card; the one that may be flipped is preferred
+func findFaceDown(player *engine.Player, cards []Card) (string, error) {
+	blocked := ""
+	for i, c := range player.TableCardsDown {
+		if len(cards) > 0 && !cards[0].Matches(c) {
+			continue
+		}
+		if i >= len(player.TableCardsUp) {
+			return c.ID, nil
+		}
+		if blocked == "" {
+			blocked = c.ID
+		}
+	}
+	switch {
+	case len(cards) == 0:
+		return "", fmt.Errorf("%s has no face-down card that can be flipped", player.Name)
+	case blocked != "":
+		return blocked, nil // Flipping it reports why it cannot be flipped yet
+	}
+	return "", fmt.Errorf("%s has no face-down %s", player.Name, cards[0])
+}
+
+// checkEnd compares how a round ended with what the record says
+func checkEnd(state engine.State, round Round) error {
+	if round.End == "" {
+		return nil
+	}
+	game := state.Game()
+	if !game.RoundOver() {
+		return fmt.Errorf("expected the round to end (%s) but it is still running", round.End)
+	}
+	result := game.LastResult()
+	winner := ""
+	if i := game.PlayerIndex(result.WinnerID); i >= 0 {
+		winner = game.Players[i].Name
+	}
+	if result.Reason != round.End || winner != round.Winner {
+		return fmt.Errorf("expected the round to end %s but it ended %s", strings.TrimSpace(round.End+" "+round.Winner), strings.TrimSpace(result.Reason+" "+winner))
+	}
+	return nil
+}
+
+// outcomes names what an action's events did beyond putting cards on the pile
+func outcomes(events []engine.Event) []string {
+	var out []string
+	played, missed := false, false
+	for _, event := range events {
+		switch event.Type {
+		case engine.EventCardsPlayed:
+			played = true
+		case engine.EventFlipMissed:
+			missed = true
+			out = append(out, OutcomeMiss)
+		case engine.EventPileCleared:
+			out = append(out, OutcomeClear)
+		case engine.EventPilePickedUp:
+			// Picking up after a missed flip is part of the miss
+			if played && !missed {
+				out = append(out, OutcomeOver)
+			}
+		}
+	}
+	return out
+}
+
+func sortedCopy(items []string) []string {
+	out := append([]string(nil), items...)
+	sort.Strings(out)
+	return out
+}
+
+func describe(outcomes []string) string {
+	if len(outcomes) == 0 {
+		return "nothing special"
+	}
+	return strings.Join(outcomes, ", ")
+}
@@ -0,0 +1,323 @@
+// Package scenario sets up game positions from a short YAML or JSON description
+//
+// Scenarios replace hand-built player slices in tests and let the testing lobby jump straight to a position:
+//
+//	name: three kings on the pile
+//	current: Bob
+//	pile: [KD, KC, KH]
+//	players:
+//	  - name: Alice
+//	    hand: [5H, 9C]
+//	    faceUp: [QS]
+//	    faceDown: [2C]
+//	  - name: Bob
+//	    hand: [KS, 3D]
+//
+// Cards use match notation (7H, 10S, QD); a bare value gets whichever suit is least used for it.
+// A position may not use more copies of a card than the decks it is dealt from hold.
+// Card IDs are predictable so tests can name them: "<player id>-hand-0", "<player id>-up-1",
+// "<player id>-down-2", "pile-0" (bottom of the pile) and "discard-0".
+package scenario
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/notation"
+	"github.com/thben/clearthedeck/internal/services"
+	"gopkg.in/yaml.v3"
+)
+
+// Phase is the point in a round a scenario starts at
+type Phase string
+
+const (
+	PhasePlaying     Phase = "playing"     // A player is about to act
+	PhaseAfterPickup Phase = "afterPickup" // The current player just picked up the pile and may play anything
+	PhaseRoundOver   Phase = "roundOver"   // The round has been scored and the host may deal the next
+	PhaseGameOver    Phase = "gameOver"    // The round has been scored and the game is finished
+)
+
+// Scenario describes a position
+type Scenario struct {
+	Name    string   `yaml:"name"`
+	Rules   string   `yaml:"rules"`   // Rule set preset; empty uses the standard rules
+	Round   int      `yaml:"round"`   // Defaults to 1
+	Dealer  string   `yaml:"dealer"`  // Defaults to the first player
+	Current string   `yaml:"current"` // Player to act; defaults to the first player
+	Phase   Phase    `yaml:"phase"`   // Defaults to playing
+	Winner  string   `yaml:"winner"`  // Who won a scored round; empty scores it as a stalemate
+	Players []Seat   `yaml:"players"`
+	Pile    []string `yaml:"pile"`    // Center pile, bottom card first
+	Discard int      `yaml:"discard"` // Cards already cleared out of play
+}
+
+// Seat is one player's cards
+type Seat struct {
+	ID       string   `yaml:"id"` // Defaults to the name
+	Name     string   `yaml:"name"`
+	Hand     []string `yaml:"hand"`
+	FaceUp   []string `yaml:"faceUp"`   // Face-up slots, paired by position with the face-down ones
+	FaceDown []string `yaml:"faceDown"` // Written face up here, dealt face down
+	Score    int      `yaml:"score"`    // Total score from earlier rounds
+	Bot      bool     `yaml:"bot"`
+}
+
+// Parse reads a scenario from YAML or JSON and checks it
+func Parse(data []byte) (*Scenario, error) {
+	var s Scenario
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("reading scenario: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Load reads a scenario file
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Validate checks that the scenario describes a position that can be played
+func (s *Scenario) Validate() error {
+	if len(s.Players) < services.MinPlayers || len(s.Players) > services.MaxPlayers {
+		return fmt.Errorf("a scenario needs %d to %d players", services.MinPlayers, services.MaxPlayers)
+	}
+	ids := make(map[string]bool)
+	names := make(map[string]bool)
+	for i, seat := range s.Players {
+		if seat.Name == "" {
+			return fmt.Errorf("player %d has no name", i+1)
+		}
+		if names[seat.Name] || ids[seat.id()] {
+			return fmt.Errorf("player %s is listed twice", seat.Name)
+		}
+		names[seat.Name] = true
+		ids[seat.id()] = true
+	}
+	if _, err := s.resolve(); err != nil {
+		return err
+	}
+	for field, name := range map[string]string{"dealer": s.Dealer, "current": s.Current, "winner": s.Winner} {
+		if name != "" && !names[name] {
+			return fmt.Errorf("%s %q is not one of the players", field, name)
+		}
+	}
+	switch s.Phase {
+	case "", PhasePlaying, PhaseAfterPickup, PhaseRoundOver, PhaseGameOver:
+	default:
+		return fmt.Errorf("unknown phase %q", s.Phase)
+	}
+	if s.Winner != "" && s.Phase != PhaseRoundOver && s.Phase != PhaseGameOver {
+		return fmt.Errorf("a winner is only given once the round is over")
+	}
+	if s.Rules != "" {
+		if _, ok := engine.LookupRuleSet(s.Rules); !ok {
+			return fmt.Errorf("unknown rule set %q", s.Rules)
+		}
+	}
+	if s.Round < 0 || s.Discard < 0 {
+		return fmt.Errorf("round and discard must not be negative")
+	}
+	return nil
+}
+
+// Game builds a ready game at the scenario's position
+func (s *Scenario) Game() (*engine.Game, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	pos, err := s.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	players := make([]*engine.Player, len(s.Players))
+	for i, seat := range s.Players {
+		p := &engine.Player{ID: seat.id(), Name: seat.Name, TotalScore: seat.Score, IsBot: seat.Bot}
+		p.Hand = gameCards(pos.seats[i][0], p.ID+"-hand")
+		p.TableCardsUp = gameCards(pos.seats[i][1], p.ID+"-up")
+		p.TableCardsDown = gameCards(pos.seats[i][2], p.ID+"-down")
+		players[i] = p
+	}
+
+	game := engine.NewGame("", "", players)
+	game.IsStarted = true
+	if s.Rules != "" {
+		game.Rules, _ = engine.LookupRuleSet(s.Rules)
+	}
+	if s.Round > 0 {
+		game.Round = s.Round
+	}
+	game.Deal = pos.deal
+	game.CenterPile = gameCards(pos.pile, "pile")
+	game.DiscardPile = discard(s.Discard, pos)
+	game.DealerIndex = indexOf(players, s.Dealer)
+	game.CurrentPlayerIndex = indexOf(players, s.Current)
+	game.ResetProgress()
+
+	switch s.Phase {
+	case PhaseAfterPickup:
+		game.AfterPickup = true
+	case PhaseRoundOver, PhaseGameOver:
+		if s.Winner != "" {
+			engine.EndRound(game, players[indexOf(players, s.Winner)].ID)
+		} else {
+			engine.EndRoundInStalemate(game, engine.RoundEndStalemate)
+		}
+		if s.Phase == PhaseGameOver {
+			game.Finish()
+		}
+	}
+	return game, nil
+}
+
+func (seat Seat) id() string {
+	if seat.ID != "" {
+		return seat.ID
+	}
+	return seat.Name
+}
+
+// dealFor picks the deal later rounds use: the table's usual deal, or just enough decks for the scenario's cards
+func dealFor(players, cards int) engine.DealConfig {
+	cfg, err := engine.DealConfigFor(engine.VariantAuto, players)
+	if err != nil {
+		cfg = engine.StandardDeal
+	}
+	if decks := (cards + 51) / 52; decks > cfg.Decks {
+		cfg.Decks = decks
+	}
+	return cfg
+}
+
+// discard makes filler for the cards already out of play from the cards the position leaves in the decks
+func discard(n int, pos *position) []*engine.Card {
+	left := make(map[notation.Card]int, len(pos.used))
+	for card, count := range pos.used {
+		left[card] = count
+	}
+	cards := make([]*engine.Card, 0, n)
+	for _, c := range engine.CreateDecks(pos.deal.Decks) {
+		if len(cards) == n {
+			break
+		}
+		key := notation.Card{Value: c.Value, Suit: c.Suit}
+		if left[key] > 0 {
+			left[key]--
+			continue
+		}
+		c.ID = fmt.Sprintf("discard-%d", len(cards))
+		cards = append(cards, c)
+	}
+	return cards
+}
+
+func indexOf(players []*engine.Player, name string) int {
+	for i, p := range players {
+		if p.Name == name {
+			return i
+		}
+	}
+	return 0
+}
+
+// position is a scenario's cards with every suit settled, and the deal they come from
+type position struct {
+	pile  []notation.Card
+	seats [][3][]notation.Card // Each player's hand, face-up and face-down cards
+	used  map[notation.Card]int
+	deal  engine.DealConfig
+}
+
+// resolve reads the scenario's cards and checks the decks hold them all
+// Cards written in full are counted first, so bare values take the suits they leave free.
+func (s *Scenario) resolve() (*position, error) {
+	pos := &position{seats: make([][3][]notation.Card, len(s.Players)), used: make(map[notation.Card]int)}
+	var err error
+	if pos.pile, err = parseCards(s.Pile); err != nil {
+		return nil, fmt.Errorf("pile: %w", err)
+	}
+	total := s.Discard + len(pos.pile)
+	areas := [][]notation.Card{pos.pile}
+	for i, seat := range s.Players {
+		for a, written := range [][]string{seat.Hand, seat.FaceUp, seat.FaceDown} {
+			if pos.seats[i][a], err = parseCards(written); err != nil {
+				return nil, fmt.Errorf("player %s: %w", seat.Name, err)
+			}
+			total += len(written)
+			areas = append(areas, pos.seats[i][a])
+		}
+	}
+
+	for _, area := range areas {
+		for _, c := range area {
+			if c.Suit != "" {
+				pos.used[c]++
+			}
+		}
+	}
+	for _, area := range areas {
+		for i, c := range area {
+			if c.Suit != "" {
+				continue
+			}
+			c.Suit = suits[0]
+			for _, suit := range suits[1:] {
+				if pos.used[notation.Card{Value: c.Value, Suit: suit}] < pos.used[c] {
+					c.Suit = suit
+				}
+			}
+			area[i] = c
+			pos.used[c]++
+		}
+	}
+
+	pos.deal = dealFor(len(s.Players), total)
+	for _, area := range areas {
+		for _, c := range area {
+			if pos.used[c] > pos.deal.Decks {
+				return nil, fmt.Errorf("%s is used %d times but the %d deck(s) dealt hold only %d", c, pos.used[c], pos.deal.Decks, pos.deal.Decks)
+			}
+		}
+	}
+	return pos, nil
+}
+
+var suits = []string{"Hearts", "Diamonds", "Clubs", "Spades"}
+
+// parseCards reads cards as written, leaving bare values without a suit
+func parseCards(written []string) ([]notation.Card, error) {
+	cards := make([]notation.Card, len(written))
+	for i, text := range written {
+		card, err := notation.ParseCard(text)
+		if err != nil {
+			return nil, err
+		}
+		cards[i] = notation.Card{Value: card.Value, Suit: card.Suit}
+	}
+	return cards, nil
+}
+
+// gameCards turns resolved cards into game cards with predictable IDs
+func gameCards(resolved []notation.Card, prefix string) []*engine.Card {
+	cards := make([]*engine.Card, len(resolved))
+	for i, c := range resolved {
+		cards[i] = engine.NewCard(fmt.Sprintf("%s-%d", prefix, i), c.Suit, c.Value)
+	}
+	return cards
+}
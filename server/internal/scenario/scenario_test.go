@@ -0,0 +1,109 @@
+package scenario
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thben/clearthedeck/engine"
+)
+
+func TestLoad(t *testing.T) {
+	s, err := Load(filepath.Join("testdata", "three-kings.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "three kings on the pile", s.Name)
+
+	game, err := s.Game()
+	require.NoError(t, err)
+
+	assert.True(t, game.IsStarted)
+	assert.Equal(t, 1, game.Round)
+	assert.Equal(t, "Bob", game.GetCurrentPlayer().ID)
+	assert.Equal(t, 0, game.DealerIndex)
+	assert.Len(t, game.DiscardPile, 12)
+	// The discard is made of cards the position leaves over, so none appears twice in one deck
+	seen := make(map[string]bool)
+	for _, c := range allCards(game) {
+		key := c.Value + c.Suit
+		assert.False(t, seen[key], "%s %s is dealt twice", c.Value, c.Suit)
+		seen[key] = true
+	}
+	require.Len(t, game.CenterPile, 4)
+	assert.Equal(t, &engine.Card{ID: "pile-3", Suit: "Hearts", Value: "K"}, game.CenterPile[3])
+
+	alice := game.Players[0]
+	assert.Empty(t, alice.Hand)
+	assert.Equal(t, []*engine.Card{{ID: "Alice-up-0", Suit: "Spades", Value: "Q"}}, alice.TableCardsUp)
+	assert.Len(t, alice.TableCardsDown, 2)
+	assert.Equal(t, 14, alice.TotalScore)
+	assert.Equal(t, "Bob-hand-0", game.Players[1].Hand[0].ID)
+
+	// The position plays like any other: the fourth King clears and Bob goes again
+	require.NoError(t, engine.PlayCards(game, "Bob", []string{"Bob-hand-0"}, false))
+	assert.Empty(t, game.CenterPile)
+	assert.Equal(t, "Bob", game.GetCurrentPlayer().ID)
+}
+
+func TestParse(t *testing.T) {
+	t.Run("JSON works too", func(t *testing.T) {
+		s, err := Parse([]byte(`{"phase": "afterPickup", "players": [{"name": "A", "id": "p1", "hand": ["2", "2"]}, {"name": "B", "hand": ["Q"]}]}`))
+		require.NoError(t, err)
+
+		game, err := s.Game()
+		require.NoError(t, err)
+		assert.True(t, game.AfterPickup)
+		assert.Equal(t, "p1", game.Players[0].ID)
+		assert.Equal(t, "p1-hand-1", game.Players[0].Hand[1].ID)
+		// Bare values are given different suits
+		assert.NotEqual(t, game.Players[0].Hand[0].Suit, game.Players[0].Hand[1].Suit)
+	})
+
+	t.Run("scored rounds", func(t *testing.T) {
+		s, err := Parse([]byte(`
+phase: gameOver
+winner: A
+rules: strict
+players:
+  - name: A
+  - name: B
+    hand: [K, Q]
+`))
+		require.NoError(t, err)
+
+		game, err := s.Game()
+		require.NoError(t, err)
+		assert.True(t, game.RoundOver())
+		assert.True(t, game.IsFinished)
+		assert.Equal(t, "A", game.LastResult().WinnerID)
+		assert.Equal(t, engine.RulesStrict, game.Rules.Name)
+	})
+
+	t.Run("mistakes are reported", func(t *testing.T) {
+		for text, want := range map[string]string{
+			`players: [{name: A}]`:                                               "2 to 16 players",
+			`players: [{name: A}, {name: A}]`:                                    "listed twice",
+			`players: [{name: A, hand: [1H]}, {name: B}]`:                        `"1H" is not a card`,
+			`{current: C, players: [{name: A}, {name: B}]}`:                      `current "C" is not one of the players`,
+			`{phase: lunch, players: [{name: A}, {name: B}]}`:                    "unknown phase",
+			`{winner: A, players: [{name: A}, {name: B}]}`:                       "only given once the round is over",
+			`{rules: chaos, players: [{name: A}, {name: B}]}`:                    "unknown rule set",
+			`{players: [{name: A}, {name: B}], pile: [KH], discrad: 3}`:          "field discrad not found",
+			`{players: [{name: A, hand: [KH]}, {name: B}], pile: [KH]}`:          "KH is used 2 times",
+			`{players: [{name: A, hand: [K, K, K]}, {name: B}], pile: [KH, KS]}`: "is used 2 times",
+		} {
+			_, err := Parse([]byte(text))
+			assert.ErrorContains(t, err, want, text)
+		}
+	})
+}
+
+func allCards(game *engine.Game) []*engine.Card {
+	cards := append(append([]*engine.Card{}, game.CenterPile...), game.DiscardPile...)
+	for _, p := range game.Players {
+		cards = append(cards, p.Hand...)
+		cards = append(cards, p.TableCardsUp...)
+		cards = append(cards, p.TableCardsDown...)
+	}
+	return cards
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/models"
+)
+
+// ErrSeatNotHeld is returned when a player comes back to a seat that is not being held for them
+var ErrSeatNotHeld = errors.New("seat is not being held")
+
+// HoldSeat keeps a departed player's seat until the given time
+// Their turns are skipped while the seat is held
+func HoldSeat(game *engine.Game, playerID string, until time.Time) error {
+	index := game.PlayerIndex(playerID)
+	if index < 0 {
+		return engine.ErrPlayerNotFound
+	}
+
+	player := game.Players[index]
+	player.Away = true
+	player.AwayUntil = until
+
+	// Pass the turn on if the departing player was holding it
+	if game.CurrentPlayerIndex == index {
+		game.AfterPickup = false
+		game.NextPlayer()
+	}
+	return nil
+}
+
+// ReturnToSeat gives a held seat back to the player who left it
+func ReturnToSeat(game *engine.Game, playerID string) error {
+	index := game.PlayerIndex(playerID)
+	if index < 0 {
+		return engine.ErrPlayerNotFound
+	}
+
+	player := game.Players[index]
+	if !player.Away {
+		return ErrSeatNotHeld
+	}
+	player.Away = false
+	player.AwayUntil = time.Time{}
+
+	// If everyone else was away the turn may be parked on nobody in particular
+	if current := game.GetCurrentPlayer(); current != nil && current.Away {
+		game.CurrentPlayerIndex = index
+	}
+	return nil
+}
+
+// ReplaceWithBot hands a departed player's seat to a bot, keeping their cards and score
+func ReplaceWithBot(game *engine.Game, playerID string) error {
+	index := game.PlayerIndex(playerID)
+	if index < 0 {
+		return engine.ErrPlayerNotFound
+	}
+
+	player := game.Players[index]
+	player.IsBot = true
+	player.Away = false
+	player.AwayUntil = time.Time{}
+	return nil
+}
+
+// RemovePlayer takes a departed player out of the game
+// With DepartureRedistribute their remaining cards are dealt round-robin into the other players' hands,
+// with DepartureForfeit their remaining cards are scored against them and moved to the discard pile.
+// Either way they stay on the score sheet as forfeited, with the total they left with.
+// Turn order and dealer position are adjusted so the same players keep their turn and deal.
+func RemovePlayer(game *engine.Game, playerID string, policy models.DeparturePolicy) error {
+	if policy != models.DepartureRedistribute && policy != models.DepartureForfeit {
+		return fmt.Errorf("policy %q does not remove players", policy)
+	}
+
+	index := game.PlayerIndex(playerID)
+	if index < 0 {
+		return engine.ErrPlayerNotFound
+	}
+	player := game.Players[index]
+
+	remaining := make([]*engine.Card, 0, len(player.Hand)+len(player.TableCardsUp)+len(player.TableCardsDown))
+	remaining = append(remaining, player.Hand...)
+	remaining = append(remaining, player.TableCardsUp...)
+	remaining = append(remaining, player.TableCardsDown...)
+
+	if policy == models.DepartureForfeit {
+		player.RoundScore = engine.CalculatePlayerScore(player, game.ActiveScoring())
+		player.TotalScore += player.RoundScore
+		// Hidden cards go out of play unseen, below the cleared ones; face-up cards were seen by all
+		hidden := append(append([]*engine.Card{}, player.Hand...), player.TableCardsDown...)
+		game.DiscardPile = append(append(hidden, game.DiscardPile...), player.TableCardsUp...)
+		game.Undealt += len(hidden)
+	}
+	game.Forfeited = append(game.Forfeited, player)
+
+	player.Hand = []*engine.Card{}
+	player.TableCardsUp = []*engine.Card{}
+	player.TableCardsDown = []*engine.Card{}
+
+	wasCurrent := game.CurrentPlayerIndex == index
+	game.Players = append(game.Players[:index], game.Players[index+1:]...)
+
+	if policy == models.DepartureRedistribute && len(game.Players) > 0 {
+		for i, card := range remaining {
+			receiver := game.Players[(index+i)%len(game.Players)]
+			receiver.Hand = append(receiver.Hand, card)
+		}
+	}
+
+	if len(game.Players) == 0 {
+		game.CurrentPlayerIndex = 0
+		game.DealerIndex = 0
+		return nil
+	}
+
+	// Seats after the departed player shift down by one
+	if game.CurrentPlayerIndex > index {
+		game.CurrentPlayerIndex--
+	}
+	if game.DealerIndex > index {
+		game.DealerIndex--
+	} else if game.DealerIndex == index {
+		// A departed dealer passes the deal back to the previous seat so rotation continues as before
+		game.DealerIndex = (index - 1 + len(game.Players)) % len(game.Players)
+	}
+	game.CurrentPlayerIndex %= len(game.Players)
+	game.DealerIndex %= len(game.Players)
+
+	if wasCurrent {
+		// The next player in order now occupies the departed seat's index
+		game.AfterPickup = false
+		if game.Players[game.CurrentPlayerIndex].Away {
+			game.NextPlayer()
+		}
+	}
+	return nil
+}
+
+// LastPlayerStanding returns the only player left in the game, or nil if more remain
+func LastPlayerStanding(game *engine.Game) *engine.Player {
+	if len(game.Players) == 1 {
+		return game.Players[0]
+	}
+	return nil
+}
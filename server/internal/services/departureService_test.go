@@ -0,0 +1,175 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/models"
+)
+
+func newDepartureGame() *engine.Game {
+	players := []*engine.Player{
+		{ID: "p1", Name: "Alice", Hand: []*engine.Card{{ID: "a1", Value: "5"}}},
+		{
+			ID:             "p2",
+			Name:           "Bob",
+			Hand:           []*engine.Card{{ID: "b1", Value: "K"}, {ID: "b2", Value: "10"}},
+			TableCardsUp:   []*engine.Card{{ID: "b3", Value: "3"}},
+			TableCardsDown: []*engine.Card{{ID: "b4", Value: "2"}},
+		},
+		{ID: "p3", Name: "Carol", Hand: []*engine.Card{{ID: "c1", Value: "9"}}},
+		{ID: "p4", Name: "Dave", Hand: []*engine.Card{{ID: "d1", Value: "4"}}},
+	}
+	game := engine.NewGame("game-1", "ABCD", players)
+	game.IsStarted = true
+	return game
+}
+
+func TestHoldSeat(t *testing.T) {
+	t.Run("should skip the held seat and pass the turn on", func(t *testing.T) {
+		game := newDepartureGame()
+		game.CurrentPlayerIndex = 1
+		game.AfterPickup = true
+
+		err := HoldSeat(game, "p2", time.Now().Add(time.Minute))
+
+		require.NoError(t, err)
+		assert.True(t, game.Players[1].Away)
+		assert.Equal(t, 2, game.CurrentPlayerIndex, "turn should pass to the next seat")
+		assert.False(t, game.AfterPickup, "pickup bonus belongs to the departed player")
+
+		game.CurrentPlayerIndex = 0
+		game.NextPlayer()
+		assert.Equal(t, 2, game.CurrentPlayerIndex, "held seat should be skipped")
+	})
+
+	t.Run("should give the seat back on return", func(t *testing.T) {
+		game := newDepartureGame()
+		require.NoError(t, HoldSeat(game, "p2", time.Now().Add(time.Minute)))
+
+		err := ReturnToSeat(game, "p2")
+
+		require.NoError(t, err)
+		assert.False(t, game.Players[1].Away)
+		assert.Len(t, game.Players[1].Hand, 2, "cards should be kept while away")
+	})
+
+	t.Run("should reject returning to a seat that is not held", func(t *testing.T) {
+		game := newDepartureGame()
+
+		assert.Error(t, ReturnToSeat(game, "p2"))
+	})
+}
+
+func TestReplaceWithBot(t *testing.T) {
+	game := newDepartureGame()
+	require.NoError(t, HoldSeat(game, "p2", time.Now().Add(time.Minute)))
+
+	err := ReplaceWithBot(game, "p2")
+
+	require.NoError(t, err)
+	assert.True(t, game.Players[1].IsBot)
+	assert.False(t, game.Players[1].Away)
+	assert.Len(t, game.Players, 4, "bot keeps the seat")
+}
+
+func TestRemovePlayer(t *testing.T) {
+	t.Run("redistribute deals remaining cards to the other players", func(t *testing.T) {
+		game := newDepartureGame()
+
+		err := RemovePlayer(game, "p2", models.DepartureRedistribute)
+
+		require.NoError(t, err)
+		require.Len(t, game.Players, 3)
+		total := 0
+		for _, p := range game.Players {
+			total += len(p.Hand)
+		}
+		assert.Equal(t, 3+4, total, "all four of Bob's cards should be handed out")
+		require.Len(t, game.Forfeited, 1, "Bob stays on the score sheet")
+		assert.Equal(t, "p2", game.Forfeited[0].ID)
+		assert.Zero(t, game.Forfeited[0].RoundScore)
+	})
+
+	t.Run("forfeit scores remaining cards and discards them", func(t *testing.T) {
+		game := newDepartureGame()
+		game.Players[1].TotalScore = 5
+
+		err := RemovePlayer(game, "p2", models.DepartureForfeit)
+
+		require.NoError(t, err)
+		require.Len(t, game.Forfeited, 1)
+		bob := game.Forfeited[0]
+		assert.Equal(t, 13+20+3+2, bob.RoundScore)
+		assert.Equal(t, 5+38, bob.TotalScore)
+		assert.Len(t, game.DiscardPile, 4)
+	})
+
+	t.Run("turn stays with the same players after removal", func(t *testing.T) {
+		game := newDepartureGame()
+		game.CurrentPlayerIndex = 2
+		game.DealerIndex = 3
+
+		require.NoError(t, RemovePlayer(game, "p2", models.DepartureForfeit))
+
+		assert.Equal(t, "p3", game.GetCurrentPlayer().ID)
+		assert.Equal(t, "p4", game.Players[game.DealerIndex].ID)
+	})
+
+	t.Run("removing the current player passes the turn to the next seat", func(t *testing.T) {
+		game := newDepartureGame()
+		game.CurrentPlayerIndex = 3
+
+		require.NoError(t, RemovePlayer(game, "p4", models.DepartureForfeit))
+
+		assert.Equal(t, "p1", game.GetCurrentPlayer().ID)
+	})
+
+	t.Run("removing the dealer passes the deal to the previous seat", func(t *testing.T) {
+		game := newDepartureGame()
+		game.DealerIndex = 0
+
+		require.NoError(t, RemovePlayer(game, "p1", models.DepartureForfeit))
+
+		assert.Equal(t, "p4", game.Players[game.DealerIndex].ID)
+	})
+
+	t.Run("should reject policies that keep the seat", func(t *testing.T) {
+		game := newDepartureGame()
+
+		assert.Error(t, RemovePlayer(game, "p2", models.DepartureHoldSeat))
+		assert.Len(t, game.Players, 4)
+	})
+
+	t.Run("forfeited players rank below everyone still seated", func(t *testing.T) {
+		game := newDepartureGame()
+		for _, p := range game.Players[1:] {
+			p.TotalScore = 50
+		}
+		require.NoError(t, RemovePlayer(game, "p1", models.DepartureForfeit))
+		engine.EndRound(game, "p3")
+
+		results := game.LastResult().Players
+		require.Len(t, results, 4)
+		alice := results[3]
+		assert.Equal(t, "p1", alice.ID)
+		assert.True(t, alice.Forfeited)
+		assert.Equal(t, 4, alice.Rank)
+		assert.Less(t, alice.TotalScore, results[0].TotalScore, "the lowest total, but still last")
+		assert.Equal(t, 1, results[0].Rank)
+	})
+
+	t.Run("last player standing is reported", func(t *testing.T) {
+		game := newDepartureGame()
+		for _, id := range []string{"p1", "p2", "p3"} {
+			assert.Nil(t, LastPlayerStanding(game))
+			require.NoError(t, RemovePlayer(game, id, models.DepartureForfeit))
+		}
+
+		require.NotNil(t, LastPlayerStanding(game))
+		assert.Equal(t, "p4", LastPlayerStanding(game).ID)
+	})
+}
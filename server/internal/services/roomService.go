@@ -2,16 +2,19 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/google/uuid"
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/metrics"
 	"github.com/thben/clearthedeck/internal/models"
 	"github.com/thben/clearthedeck/internal/utils"
 )
 
 const (
-	MinPlayers = 3
-	MaxPlayers = 10
+	MinPlayers = 2
+	MaxPlayers = 16
 )
 
 var (
@@ -19,13 +22,16 @@ var (
 	ErrRoomFull           = errors.New("room is full")
 	ErrPlayerNameEmpty    = errors.New("player name cannot be empty")
 	ErrPlayerNameExists   = errors.New("player name already exists in room")
-	ErrInvalidPlayerCount = errors.New("room must have 3-10 players")
+	ErrInvalidPlayerCount = errors.New("room must have 2-16 players")
+	ErrTooManyRooms       = errors.New("server has no room for more tables, try again later")
+	ErrAlreadySeated      = errors.New("player already has a seat at another table; rejoin or leave it first")
 )
 
 // RoomService manages game rooms
 type RoomService struct {
-	rooms map[string]*models.Room
-	mu    sync.RWMutex
+	rooms    map[string]*models.Room
+	maxRooms int // 0 means no limit
+	mu       sync.RWMutex
 }
 
 // NewRoomService creates a new room service
@@ -35,15 +41,40 @@ func NewRoomService() *RoomService {
 	}
 }
 
-// CreateRoom creates a new room with a unique code
+// SetMaxRooms limits how many rooms may be open at once; 0 removes the limit
+func (s *RoomService) SetMaxRooms(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxRooms = n
+}
+
+// CreateRoom creates a new room with a unique code, hosted by a guest
 func (s *RoomService) CreateRoom(playerName string) (*models.Room, string, error) {
-	if playerName == "" {
-		return nil, "", ErrPlayerNameEmpty
+	room, err := s.CreateRoomAs(&engine.Player{ID: uuid.New().String(), Name: playerName})
+	if err != nil {
+		return nil, "", err
+	}
+	return room, room.GetHostID(), nil
+}
+
+// CreateRoomAs creates a new room with a unique code, hosted by the given player
+// Registered players keep their account's ID, so they may only sit at one table at a time.
+func (s *RoomService) CreateRoomAs(player *engine.Player) (*models.Room, error) {
+	if player.Name == "" {
+		return nil, ErrPlayerNameEmpty
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.maxRooms > 0 && len(s.rooms) >= s.maxRooms {
+		metrics.RoomsRefused.Inc("")
+		return nil, ErrTooManyRooms
+	}
+	if player.Registered && s.seated(player.ID) {
+		return nil, ErrAlreadySeated
+	}
+
 	// Generate unique room code
 	var code string
 	for {
@@ -53,30 +84,31 @@ func (s *RoomService) CreateRoom(playerName string) (*models.Room, string, error
 		}
 	}
 
-	// Generate player ID
-	playerID := uuid.New().String()
-
-	// Create room
+	// Create room with the creator as first player (host)
 	roomID := uuid.New().String()
-	room := models.NewRoom(roomID, code, playerID)
-
-	// Add creator as first player (host)
-	player := &models.Player{
-		ID:   playerID,
-		Name: playerName,
-	}
+	room := models.NewRoom(roomID, code, player.ID)
 	room.AddPlayer(player)
 
 	// Store room
 	s.rooms[code] = room
+	metrics.RoomsCreated.Inc("")
 
-	return room, playerID, nil
+	return room, nil
 }
 
-// JoinRoom adds a player to an existing room
+// JoinRoom adds a guest to an existing room
 func (s *RoomService) JoinRoom(roomCode, playerName string) (string, error) {
-	if playerName == "" {
-		return "", ErrPlayerNameEmpty
+	playerID := uuid.New().String()
+	if err := s.JoinRoomAs(roomCode, &engine.Player{ID: playerID, Name: playerName}); err != nil {
+		return "", err
+	}
+	return playerID, nil
+}
+
+// JoinRoomAs adds the given player to an existing room
+func (s *RoomService) JoinRoomAs(roomCode string, player *engine.Player) error {
+	if player.Name == "" {
+		return ErrPlayerNameEmpty
 	}
 
 	s.mu.Lock()
@@ -84,28 +116,35 @@ func (s *RoomService) JoinRoom(roomCode, playerName string) (string, error) {
 
 	room, exists := s.rooms[roomCode]
 	if !exists {
-		return "", ErrRoomNotFound
+		return ErrRoomNotFound
 	}
 
 	// Check if room is full
 	if room.GetPlayerCount() >= MaxPlayers {
-		return "", ErrRoomFull
+		return ErrRoomFull
 	}
 
 	// Check for duplicate name
-	if room.HasPlayerWithName(playerName) {
-		return "", ErrPlayerNameExists
+	if room.HasPlayerWithName(player.Name) {
+		return ErrPlayerNameExists
 	}
 
-	// Generate player ID and add to room
-	playerID := uuid.New().String()
-	player := &models.Player{
-		ID:   playerID,
-		Name: playerName,
+	if player.Registered && s.seated(player.ID) {
+		return ErrAlreadySeated
 	}
+
 	room.AddPlayer(player)
+	return nil
+}
 
-	return playerID, nil
+// seated reports whether the player has a seat in any room that a bot has not taken over; callers hold the lock
+func (s *RoomService) seated(playerID string) bool {
+	for _, room := range s.rooms {
+		if p, ok := room.GetPlayer(playerID); ok && !p.IsBot {
+			return true
+		}
+	}
+	return false
 }
 
 // LeaveRoom removes a player from a room
@@ -137,6 +176,35 @@ func (s *RoomService) LeaveRoom(roomCode, playerID string) error {
 	return nil
 }
 
+// AddRoom registers an existing room, such as one restored after a restart
+func (s *RoomService) AddRoom(room *models.Room) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.rooms[room.Code]; exists {
+		return fmt.Errorf("room code %s is already in use", room.Code)
+	}
+	s.rooms[room.Code] = room
+	return nil
+}
+
+// DeleteRoom removes a room regardless of who is still in it
+func (s *RoomService) DeleteRoom(roomCode string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.rooms, roomCode)
+}
+
+// RoomCodes lists the codes of all open rooms
+func (s *RoomService) RoomCodes() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	codes := make([]string, 0, len(s.rooms))
+	for code := range s.rooms {
+		codes = append(codes, code)
+	}
+	return codes
+}
+
 // GetRoom retrieves a room by code
 func (s *RoomService) GetRoom(roomCode string) *models.Room {
 	s.mu.RLock()
@@ -43,6 +43,22 @@ func TestCreateRoom(t *testing.T) {
 			codes[room.Code] = true
 		}
 	})
+
+	t.Run("should refuse rooms beyond the limit", func(t *testing.T) {
+		service := NewRoomService()
+		service.SetMaxRooms(2)
+
+		for i := 0; i < 2; i++ {
+			_, _, err := service.CreateRoom("Player")
+			require.NoError(t, err)
+		}
+		_, _, err := service.CreateRoom("Player")
+		assert.ErrorIs(t, err, ErrTooManyRooms)
+
+		service.DeleteRoom(service.RoomCodes()[0])
+		_, _, err = service.CreateRoom("Player")
+		assert.NoError(t, err)
+	})
 }
 
 func TestJoinRoom(t *testing.T) {
@@ -96,14 +112,14 @@ func TestJoinRoom(t *testing.T) {
 		room, _, err := service.CreateRoom("Host")
 		require.NoError(t, err)
 
-		// Add 9 more players to reach max of 10
-		for i := 2; i <= 10; i++ {
+		// Add 15 more players to reach max of 16
+		for i := 2; i <= 16; i++ {
 			_, err := service.JoinRoom(room.Code, "Player"+string(rune(i)))
 			require.NoError(t, err)
 		}
 
-		// Try to add 11th player
-		playerID, err := service.JoinRoom(room.Code, "Player11")
+		// Try to add 17th player
+		playerID, err := service.JoinRoom(room.Code, "Player17")
 
 		assert.Error(t, err)
 		assert.Empty(t, playerID)
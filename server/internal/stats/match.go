@@ -0,0 +1,227 @@
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/notation"
+)
+
+// MatchPlayer is one seat in a match summary
+type MatchPlayer struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Registered bool   `json:"registered"`
+	Bot        bool   `json:"bot"`
+	TotalScore int    `json:"totalScore"`
+	Rank       int    `json:"rank"` // Standing by total score; tied players share a rank and forfeited players come last
+	RoundsWon  int    `json:"roundsWon"`
+	Forfeited  bool   `json:"forfeited,omitempty"` // Left the game, or handed their seat to a bot, before it ended
+}
+
+// MatchRecord is a match as it is saved and listed in histories
+type MatchRecord struct {
+	ID        string        `json:"id"`
+	RoomCode  string        `json:"roomCode"`
+	Rules     string        `json:"rules"`
+	StartedAt time.Time     `json:"startedAt"`
+	EndedAt   time.Time     `json:"endedAt,omitempty"` // Zero while the match is being played
+	Rounds    int           `json:"rounds"`            // Rounds scored
+	Finished  bool          `json:"finished"`          // Played to the end
+	Abandoned bool          `json:"abandoned"`         // Stopped before the game ended, as when the room closed or a new game was dealt
+	Players   []MatchPlayer `json:"players"`
+	Notation  string        `json:"notation,omitempty"` // The match in notation, for replays
+}
+
+// Counts are the tallies taken from a round's events
+type Counts struct {
+	SetsCleared  int `json:"setsCleared"`
+	WildTensUsed int `json:"wildTensUsed"` // Wild cards played, tens under the usual rules
+	Pickups      int `json:"pickups"`
+}
+
+// mark is what one move added to a player's counts, kept so undone moves can be taken back out
+type mark struct {
+	round    int
+	move     int
+	playerID string
+	counts   Counts
+}
+
+// Match follows a game as it is played: the moves for its replay and the events its statistics come from
+type Match struct {
+	record   MatchRecord
+	recorder *notation.Recorder
+	marks    []mark          // Counts from the current round's moves
+	counted  map[string]bool // Players whose game has been added to their statistics
+	saved    int             // Rounds already added to statistics
+	humans   map[string]bool // Seats people sat down in, so a bot taking one over counts as them leaving
+}
+
+// NewMatch starts following a game from the deal of its current round
+func NewMatch(state engine.State, started time.Time) *Match {
+	game := state.Game()
+	humans := make(map[string]bool)
+	for _, p := range game.Players {
+		if !p.IsBot {
+			humans[p.ID] = true
+		}
+	}
+	return &Match{
+		record: MatchRecord{
+			ID:        uuid.New().String(),
+			RoomCode:  game.RoomCode,
+			Rules:     game.ActiveRules().Name,
+			StartedAt: started.UTC(),
+		},
+		recorder: notation.NewRecorder(state),
+		counted:  make(map[string]bool),
+		humans:   humans,
+	}
+}
+
+// ID returns the match's ID, which its replay is found by
+func (m *Match) ID() string {
+	return m.record.ID
+}
+
+// Add takes what one action did; state is the position after it
+func (m *Match) Add(state engine.State, events []engine.Event) {
+	m.recorder.Add(state, events)
+
+	game := state.Game()
+	rules := game.ActiveRules()
+	move := game.Progress.Actions
+	var wild bool
+	for _, event := range events {
+		var counts Counts
+		switch event.Type {
+		case engine.EventCardsPlayed:
+			for _, card := range event.Cards {
+				if rules.IsWild(card.Value) {
+					counts.WildTensUsed++
+					wild = true
+				}
+			}
+		case engine.EventPileCleared:
+			// A clear by a play with no wild in it was a set
+			if !wild {
+				counts.SetsCleared++
+			}
+		case engine.EventPilePickedUp:
+			counts.Pickups++
+		case engine.EventRoundStarted:
+			m.marks = nil
+		}
+		if counts != (Counts{}) {
+			m.marks = append(m.marks, mark{round: game.Round, move: move, playerID: event.PlayerID, counts: counts})
+		}
+	}
+}
+
+// Rewind forgets the moves the position has not seen, as when actions are undone
+func (m *Match) Rewind(state engine.State) {
+	m.recorder.Rewind(state)
+	game := state.Game()
+	kept := m.marks[:0]
+	for _, mk := range m.marks {
+		if mk.round != game.Round || mk.move <= game.Progress.Actions {
+			kept = append(kept, mk)
+		}
+	}
+	m.marks = kept
+}
+
+// roundCounts adds up the current round's counts by player
+func (m *Match) roundCounts() map[string]Counts {
+	totals := make(map[string]Counts)
+	for _, mk := range m.marks {
+		c := totals[mk.playerID]
+		c.SetsCleared += mk.counts.SetsCleared
+		c.WildTensUsed += mk.counts.WildTensUsed
+		c.Pickups += mk.counts.Pickups
+		totals[mk.playerID] = c
+	}
+	return totals
+}
+
+// update refreshes the summary from the game
+func (m *Match) update(game *engine.Game) {
+	m.record.Rounds = len(game.History)
+	m.record.Notation = m.recorder.String()
+
+	won := make(map[string]int)
+	for _, result := range game.History {
+		if result.WinnerID != "" {
+			won[result.WinnerID]++
+		}
+	}
+	ranks := make(map[string]int)
+	if last := game.LastResult(); last != nil {
+		for _, p := range last.Players {
+			ranks[p.ID] = p.Rank
+		}
+	}
+
+	// Players who left, whether their seat went to a bot or not, rank below everyone who stayed,
+	// whatever their score when they left
+	m.record.Players = m.record.Players[:0]
+	var left []*engine.Player
+	for _, p := range game.Players {
+		if p.IsBot && m.humans[p.ID] {
+			left = append(left, p)
+			continue
+		}
+		m.record.Players = append(m.record.Players, MatchPlayer{
+			ID:         p.ID,
+			Name:       p.Name,
+			Registered: p.Registered,
+			Bot:        p.IsBot,
+			TotalScore: p.TotalScore,
+			Rank:       ranks[p.ID],
+			RoundsWon:  won[p.ID],
+		})
+	}
+	forfeitRank := 0 // Nobody is ranked before a round is scored
+	if len(ranks) > 0 {
+		rerank(m.record.Players)
+		forfeitRank = len(m.record.Players) + 1
+	}
+	for _, p := range append(left, game.Forfeited...) {
+		m.record.Players = append(m.record.Players, MatchPlayer{
+			ID:         p.ID,
+			Name:       p.Name,
+			Registered: p.Registered,
+			Bot:        p.IsBot && !m.humans[p.ID],
+			TotalScore: p.TotalScore,
+			Rank:       forfeitRank,
+			RoundsWon:  won[p.ID],
+			Forfeited:  true,
+		})
+	}
+}
+
+// rerank numbers ranks from 1 in the order the players already stand, keeping ties
+// The score sheet ranks forfeited players too, which leaves gaps between the ranks of those who stayed.
+func rerank(players []MatchPlayer) {
+	order := make([]int, len(players))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return players[order[a]].Rank < players[order[b]].Rank
+	})
+	ranks := make([]int, len(players))
+	for n, i := range order {
+		if n > 0 && players[i].Rank == players[order[n-1]].Rank {
+			ranks[i] = ranks[order[n-1]]
+		} else {
+			ranks[i] = n + 1
+		}
+	}
+	for i := range players {
+		players[i].Rank = ranks[i]
+	}
+}
@@ -0,0 +1,156 @@
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/storage"
+)
+
+// Rating settings. Ratings are multiplayer Elo: a finished game counts as a head-to-head result
+// against every other player at the table, and the change is scaled by the number of opponents so
+// a game moves a rating about as much at any table size.
+const (
+	InitialRating    = 1500.0
+	ProvisionalGames = 10   // Games until a rating is no longer provisional
+	MinRatedPlayers  = 3    // Smallest table whose games are rated
+	MaxRatedPlayers  = 10   // Largest table whose games are rated
+	MaxRatingHistory = 100  // Rating changes a player's history remembers
+	ratingK          = 32.0 // Most a game can move an established rating
+	provisionalK     = 64.0 // Provisional ratings move faster to find their level
+	ratingsKind      = "ratings"
+)
+
+// Rating is a player's skill rating
+type Rating struct {
+	PlayerID    string         `json:"playerId"`
+	Rating      float64        `json:"rating"`
+	Games       int            `json:"games"`       // Rated games played
+	Provisional bool           `json:"provisional"` // Fewer than ProvisionalGames rated games
+	History     []RatingChange `json:"history"`     // Most recent first
+}
+
+// RatingChange is what one rated game did to a player's rating
+type RatingChange struct {
+	MatchID string    `json:"matchId"`
+	At      time.Time `json:"at"`
+	Rank    int       `json:"rank"`
+	Players int       `json:"players"`
+	Before  float64   `json:"before"`
+	After   float64   `json:"after"`
+}
+
+// Rating returns a player's rating; players with no rated games get the initial, provisional one
+func (s *Store) Rating(playerID string) (Rating, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loadRating(playerID)
+}
+
+// rated reports whether a finished match counts for ratings: standard rules, between
+// MinRatedPlayers and MaxRatedPlayers seats, and every seat a signed-in human
+func rated(record MatchRecord, game *engine.Game) bool {
+	if !game.ActiveRules().IsStandard() {
+		return false
+	}
+	if len(record.Players) < MinRatedPlayers || len(record.Players) > MaxRatedPlayers {
+		return false
+	}
+	for _, p := range record.Players {
+		if p.Bot || !p.Registered || p.Rank == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// rate updates the ratings of the match's players from their final standings
+func (s *Store) rate(record MatchRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := make([]Rating, len(record.Players))
+	for i, p := range record.Players {
+		r, err := s.loadRating(p.ID)
+		if err != nil {
+			return err
+		}
+		before[i] = r
+	}
+
+	var errs []error
+	opponents := float64(len(record.Players) - 1)
+	for i, p := range record.Players {
+		var score, expected float64
+		for j, q := range record.Players {
+			if i == j {
+				continue
+			}
+			switch {
+			case p.Rank < q.Rank:
+				score++
+			case p.Rank == q.Rank:
+				score += 0.5
+			}
+			expected += 1 / (1 + math.Pow(10, (before[j].Rating-before[i].Rating)/400))
+		}
+		r := before[i]
+		k := ratingK
+		if r.Provisional {
+			k = provisionalK
+		}
+		r.Rating += k * (score - expected) / opponents
+		r.Games++
+		r.Provisional = r.Games < ProvisionalGames
+		r.History = append([]RatingChange{{
+			MatchID: record.ID,
+			At:      record.EndedAt,
+			Rank:    p.Rank,
+			Players: len(record.Players),
+			Before:  before[i].Rating,
+			After:   r.Rating,
+		}}, r.History...)
+		if len(r.History) > MaxRatingHistory {
+			r.History = r.History[:MaxRatingHistory]
+		}
+		if err := s.saveRating(r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Store) loadRating(playerID string) (Rating, error) {
+	if r, ok := s.ratings[playerID]; ok {
+		return r, nil
+	}
+	data, err := s.store.Load(ratingsKind, playerID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return Rating{PlayerID: playerID, Rating: InitialRating, Provisional: true, History: []RatingChange{}}, nil
+	}
+	if err != nil {
+		return Rating{}, err
+	}
+	var r Rating
+	if err := json.Unmarshal(data, &r); err != nil {
+		return Rating{}, fmt.Errorf("stats: rating %s: %w", playerID, err)
+	}
+	s.ratings[playerID] = r
+	return r, nil
+}
+
+func (s *Store) saveRating(r Rating) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Save(ratingsKind, r.PlayerID, data); err != nil {
+		return err
+	}
+	s.ratings[r.PlayerID] = r
+	return nil
+}
@@ -0,0 +1,221 @@
+// Package stats keeps lifetime statistics, match histories and skill ratings for signed-in players
+//
+// A Match follows a game's events while it is played. Each scored round is added to the
+// statistics of the signed-in players at the table, and the match itself, with its moves in
+// notation for replays, is saved alongside them. Guests and bots have no lasting identity, so
+// they appear in match records but get no statistics of their own. When a match between signed-in
+// players ends, their ratings are updated from the final standings.
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/storage"
+)
+
+// Storage kinds
+const (
+	playersKind = "stats"
+	matchesKind = "matches"
+)
+
+// MaxHistory is how many matches a player's history remembers
+const MaxHistory = 100
+
+// ErrMatchNotFound is returned for a match that was never saved
+var ErrMatchNotFound = errors.New("match not found")
+
+// PlayerStats are a player's lifetime statistics
+type PlayerStats struct {
+	PlayerID          string  `json:"playerId"`
+	GamesPlayed       int     `json:"gamesPlayed"`
+	RoundsPlayed      int     `json:"roundsPlayed"`
+	RoundsWon         int     `json:"roundsWon"` // Rounds the player went out first
+	TotalRoundScore   int     `json:"totalRoundScore"`
+	AverageRoundScore float64 `json:"averageRoundScore"`
+	TensPenalty       int     `json:"tensPenalty"` // Points from tens left in the player's cards
+	Counts
+	Matches []string `json:"matches"` // Match IDs, most recent first
+}
+
+// Store saves statistics and matches
+type Store struct {
+	store storage.Store
+	now   func() time.Time
+	// Serializes read-modify-write of player records
+	mu sync.Mutex
+	// Ratings already loaded, kept for lobbies that show them on every update
+	ratings map[string]Rating
+}
+
+// NewStore creates a statistics store saving to the store
+func NewStore(store storage.Store) *Store {
+	return &Store{store: store, now: time.Now, ratings: make(map[string]Rating)}
+}
+
+// Player returns a player's statistics; players with none yet get zeroes
+func (s *Store) Player(playerID string) (PlayerStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(playerID)
+}
+
+// Match returns a saved match
+func (s *Store) Match(id string) (*MatchRecord, error) {
+	data, err := s.store.Load(matchesKind, id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrMatchNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var record MatchRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("stats: match %s: %w", id, err)
+	}
+	return &record, nil
+}
+
+// History returns up to limit of a player's matches, most recent first, without their notation
+func (s *Store) History(playerID string, limit int) ([]MatchRecord, error) {
+	player, err := s.Player(playerID)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]MatchRecord, 0, len(player.Matches))
+	for _, id := range player.Matches {
+		if limit > 0 && len(history) >= limit {
+			break
+		}
+		record, err := s.Match(id)
+		if errors.Is(err, ErrMatchNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		record.Notation = ""
+		history = append(history, *record)
+	}
+	return history, nil
+}
+
+// RecordRound adds the game's rounds scored since the last call to the statistics of the signed-in
+// players still at the table, and saves the match
+func (s *Store) RecordRound(m *Match, game *engine.Game) error {
+	m.update(game)
+	counts := m.roundCounts()
+	m.marks = nil
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for _, result := range game.History[m.saved:] {
+		for _, line := range result.Players {
+			seat := playerByID(game, line.ID)
+			if seat == nil || !seat.Registered || seat.IsBot {
+				continue
+			}
+			player, err := s.load(line.ID)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if !m.counted[line.ID] {
+				m.counted[line.ID] = true
+				player.GamesPlayed++
+				player.Matches = append([]string{m.record.ID}, player.Matches...)
+				if len(player.Matches) > MaxHistory {
+					player.Matches = player.Matches[:MaxHistory]
+				}
+			}
+			player.RoundsPlayed++
+			if result.WinnerID == line.ID {
+				player.RoundsWon++
+			}
+			player.TotalRoundScore += line.RoundScore
+			player.AverageRoundScore = float64(player.TotalRoundScore) / float64(player.RoundsPlayed)
+			player.TensPenalty += line.TensPenalty
+			// Counts come from the events of the round just scored
+			if result.Round == game.Round {
+				c := counts[line.ID]
+				player.SetsCleared += c.SetsCleared
+				player.WildTensUsed += c.WildTensUsed
+				player.Pickups += c.Pickups
+			}
+			if err := s.save(player); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	m.saved = len(game.History)
+
+	if err := s.saveMatch(m); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// FinishMatch stops following the match and saves it, as finished if the game has ended, by its
+// round limit, the host or the last player standing, and as abandoned otherwise; only finished
+// matches update the players' ratings, and only if they count for them. Matches with no scored
+// round are not kept
+func (s *Store) FinishMatch(m *Match, game *engine.Game) error {
+	if m.saved == 0 {
+		return nil
+	}
+	m.update(game)
+	m.record.Finished = game.IsFinished
+	m.record.Abandoned = !game.IsFinished
+	m.record.EndedAt = s.now().UTC()
+	if err := s.saveMatch(m); err != nil {
+		return err
+	}
+	if !game.IsFinished || !rated(m.record, game) {
+		return nil
+	}
+	return s.rate(m.record)
+}
+
+func (s *Store) saveMatch(m *Match) error {
+	data, err := json.Marshal(m.record)
+	if err != nil {
+		return err
+	}
+	return s.store.Save(matchesKind, m.record.ID, data)
+}
+
+func (s *Store) load(playerID string) (PlayerStats, error) {
+	data, err := s.store.Load(playersKind, playerID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return PlayerStats{PlayerID: playerID, Matches: []string{}}, nil
+	}
+	if err != nil {
+		return PlayerStats{}, err
+	}
+	var player PlayerStats
+	if err := json.Unmarshal(data, &player); err != nil {
+		return PlayerStats{}, fmt.Errorf("stats: player %s: %w", playerID, err)
+	}
+	return player, nil
+}
+
+func (s *Store) save(player PlayerStats) error {
+	data, err := json.Marshal(player)
+	if err != nil {
+		return err
+	}
+	return s.store.Save(playersKind, player.PlayerID, data)
+}
+
+func playerByID(game *engine.Game, id string) *engine.Player {
+	if i := game.PlayerIndex(id); i >= 0 {
+		return game.Players[i]
+	}
+	return nil
+}
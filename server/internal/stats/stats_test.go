@@ -0,0 +1,358 @@
+package stats
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thben/clearthedeck/engine"
+	"github.com/thben/clearthedeck/internal/models"
+	"github.com/thben/clearthedeck/internal/notation"
+	"github.com/thben/clearthedeck/internal/services"
+	"github.com/thben/clearthedeck/internal/storage"
+)
+
+// newTable deals a game in which Alice is signed in and Bob is a guest
+func newTable(t *testing.T) engine.State {
+	t.Helper()
+	state, err := engine.NewState([]engine.Seat{{ID: "alice", Name: "Alice"}, {ID: "bob", Name: "Bob"}}, engine.Config{Seed: 3})
+	require.NoError(t, err)
+	game := state.Game()
+	game.RoomCode = "ROOM01"
+	game.Players[0].Registered = true
+	return engine.FromGame(game)
+}
+
+// playRound plays random legal moves until the round is scored, counting Alice's pickups
+func playRound(t *testing.T, state engine.State, m *Match, rng *rand.Rand) (engine.State, int) {
+	t.Helper()
+	pickups := 0
+	for moves := 0; moves < 2000 && !state.RoundOver(); moves++ {
+		legal := engine.LegalActions(state, state.CurrentPlayerID())
+		require.NotEmpty(t, legal)
+		next, events, err := engine.Apply(state, legal[rng.Intn(len(legal))])
+		require.NoError(t, err)
+		m.Add(next, events)
+		for _, e := range events {
+			if e.Type == engine.EventPilePickedUp && e.PlayerID == "alice" {
+				pickups++
+			}
+		}
+		state = next
+	}
+	require.True(t, state.RoundOver())
+	return state, pickups
+}
+
+func TestRecordRound(t *testing.T) {
+	store := NewStore(storage.NewMemoryStore())
+	rng := rand.New(rand.NewSource(1))
+	state := newTable(t)
+	m := NewMatch(state, time.Now())
+
+	state, pickups := playRound(t, state, m, rng)
+	game := state.Game()
+	require.NoError(t, store.RecordRound(m, game))
+
+	var line engine.PlayerResult
+	for _, p := range game.LastResult().Players {
+		if p.ID == "alice" {
+			line = p
+		}
+	}
+	alice, err := store.Player("alice")
+	require.NoError(t, err)
+	assert.Equal(t, 1, alice.GamesPlayed)
+	assert.Equal(t, 1, alice.RoundsPlayed)
+	assert.Equal(t, line.RoundScore, alice.TotalRoundScore)
+	assert.Equal(t, line.TensPenalty, alice.TensPenalty)
+	assert.Equal(t, pickups, alice.Pickups)
+	if game.LastResult().WinnerID == "alice" {
+		assert.Equal(t, 1, alice.RoundsWon)
+	}
+	assert.Equal(t, []string{m.ID()}, alice.Matches)
+
+	bob, err := store.Player("bob")
+	require.NoError(t, err)
+	assert.Zero(t, bob.RoundsPlayed, "guests get no statistics")
+
+	t.Run("a second round adds to the same game", func(t *testing.T) {
+		next, events, err := engine.Apply(state, engine.Action{Type: engine.ActionNextRound, Seed: 9})
+		require.NoError(t, err)
+		m.Add(next, events)
+		next, _ = playRound(t, next, m, rng)
+		require.NoError(t, store.RecordRound(m, next.Game()))
+
+		alice, err := store.Player("alice")
+		require.NoError(t, err)
+		assert.Equal(t, 1, alice.GamesPlayed)
+		assert.Equal(t, 2, alice.RoundsPlayed)
+		assert.InDelta(t, float64(alice.TotalRoundScore)/2, alice.AverageRoundScore, 1e-9)
+
+		// The game is stopped before it is over
+		require.NoError(t, store.FinishMatch(m, next.Game()))
+		history, err := store.History("alice", 10)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.False(t, history[0].Finished)
+		assert.True(t, history[0].Abandoned)
+		assert.False(t, history[0].EndedAt.IsZero())
+		assert.Equal(t, 2, history[0].Rounds)
+		assert.Empty(t, history[0].Notation, "histories leave the moves out")
+	})
+
+	t.Run("the saved match replays", func(t *testing.T) {
+		record, err := store.Match(m.ID())
+		require.NoError(t, err)
+		parsed, err := notation.Parse(record.Notation)
+		require.NoError(t, err)
+		_, err = notation.Replay(parsed)
+		assert.NoError(t, err, record.Notation)
+	})
+}
+
+func TestMatchRewind(t *testing.T) {
+	state := newTable(t)
+	m := NewMatch(state, time.Now())
+
+	// Find a move that picks up the pile, then take it back
+	rng := rand.New(rand.NewSource(2))
+	for moves := 0; moves < 2000 && !state.RoundOver(); moves++ {
+		legal := engine.LegalActions(state, state.CurrentPlayerID())
+		next, events, err := engine.Apply(state, legal[rng.Intn(len(legal))])
+		require.NoError(t, err)
+		m.Add(next, events)
+		if m.roundCounts()[state.CurrentPlayerID()].Pickups > 0 {
+			m.Rewind(state)
+			assert.Zero(t, m.roundCounts()[state.CurrentPlayerID()].Pickups)
+			return
+		}
+		state = next
+	}
+	t.Fatal("no pickup happened")
+}
+
+func TestUnknownMatch(t *testing.T) {
+	_, err := NewStore(storage.NewMemoryStore()).Match("nope")
+	assert.ErrorIs(t, err, ErrMatchNotFound)
+}
+
+func TestRatings(t *testing.T) {
+	// Three signed-in players finish a one-round game
+	seats := []engine.Seat{{ID: "alice", Name: "Alice"}, {ID: "bob", Name: "Bob"}, {ID: "carol", Name: "Carol"}}
+	state, err := engine.NewState(seats, engine.Config{Seed: 5})
+	require.NoError(t, err)
+	game := state.Game()
+	for _, p := range game.Players {
+		p.Registered = true
+	}
+	state = engine.FromGame(game)
+
+	store := NewStore(storage.NewMemoryStore())
+	m := NewMatch(state, time.Now())
+	state, _ = playRound(t, state, m, rand.New(rand.NewSource(4)))
+	game = state.Game()
+	game.Finish()
+	require.NoError(t, store.RecordRound(m, game))
+	require.NoError(t, store.FinishMatch(m, game))
+	record, err := store.Match(m.ID())
+	require.NoError(t, err)
+	assert.True(t, record.Finished)
+	assert.False(t, record.Abandoned)
+
+	var total float64
+	for _, line := range game.LastResult().Players {
+		r, err := store.Rating(line.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, r.Games)
+		assert.True(t, r.Provisional)
+		require.Len(t, r.History, 1)
+		assert.Equal(t, m.ID(), r.History[0].MatchID)
+		assert.Equal(t, line.Rank, r.History[0].Rank)
+		assert.Equal(t, InitialRating, r.History[0].Before)
+		switch line.Rank {
+		case 1:
+			assert.Greater(t, r.Rating, InitialRating, "the leader gains")
+		case 3:
+			assert.Less(t, r.Rating, InitialRating, "the last player loses")
+		}
+		total += r.Rating - InitialRating
+	}
+	assert.InDelta(t, 0, total, 1e-9, "equal ratings trade points")
+
+	t.Run("ratings are saved with the store", func(t *testing.T) {
+		fresh := NewStore(store.store)
+		r, err := fresh.Rating("alice")
+		require.NoError(t, err)
+		cached, _ := store.Rating("alice")
+		assert.Equal(t, cached.Rating, r.Rating)
+	})
+
+	t.Run("a rating stops being provisional", func(t *testing.T) {
+		record := MatchRecord{ID: "m", Players: []MatchPlayer{
+			{ID: "x", Registered: true, Rank: 1},
+			{ID: "y", Registered: true, Rank: 2},
+			{ID: "z", Registered: true, Rank: 2},
+		}}
+		for i := 0; i < ProvisionalGames; i++ {
+			require.NoError(t, store.rate(record))
+		}
+		x, _ := store.Rating("x")
+		y, _ := store.Rating("y")
+		z, _ := store.Rating("z")
+		assert.False(t, x.Provisional)
+		assert.Greater(t, x.Rating, y.Rating)
+		assert.Equal(t, y.Rating, z.Rating, "tied players move alike")
+	})
+}
+
+func TestRatedGames(t *testing.T) {
+	players := func(n int) []MatchPlayer {
+		seats := make([]MatchPlayer, n)
+		for i := range seats {
+			seats[i] = MatchPlayer{ID: string(rune('a' + i)), Registered: true, Rank: i + 1}
+		}
+		return seats
+	}
+	standard := &engine.Game{}
+	strict, ok := engine.LookupRuleSet(engine.RulesStrict)
+	require.True(t, ok)
+
+	withBot := players(4)
+	withBot[2].Bot = true
+	withGuest := players(4)
+	withGuest[1].Registered = false
+
+	tests := []struct {
+		name    string
+		players []MatchPlayer
+		game    *engine.Game
+		want    bool
+	}{
+		{"three signed-in players", players(3), standard, true},
+		{"ten signed-in players", players(10), standard, true},
+		{"two players", players(2), standard, false},
+		{"eleven players", players(11), standard, false},
+		{"a bot at the table", withBot, standard, false},
+		{"a guest at the table", withGuest, standard, false},
+		{"house rules", players(4), &engine.Game{Rules: strict}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rated(MatchRecord{Players: tt.players}, tt.game))
+		})
+	}
+}
+
+// ratedRound scores one round between signed-in players
+func ratedRound(t *testing.T, ids ...string) (*Store, *Match, *engine.Game) {
+	t.Helper()
+	seats := make([]engine.Seat, len(ids))
+	for i, id := range ids {
+		seats[i] = engine.Seat{ID: id, Name: id}
+	}
+	state, err := engine.NewState(seats, engine.Config{Seed: 5})
+	require.NoError(t, err)
+	game := state.Game()
+	for _, p := range game.Players {
+		p.Registered = true
+	}
+	state = engine.FromGame(game)
+
+	store := NewStore(storage.NewMemoryStore())
+	m := NewMatch(state, time.Now())
+	state, _ = playRound(t, state, m, rand.New(rand.NewSource(4)))
+	game = state.Game()
+	require.NoError(t, store.RecordRound(m, game))
+	return store, m, game
+}
+
+func TestAbandonedGamesAreNotRated(t *testing.T) {
+	store, m, game := ratedRound(t, "alice", "bob", "carol")
+	require.NoError(t, store.FinishMatch(m, game))
+
+	for _, p := range game.Players {
+		r, err := store.Rating(p.ID)
+		require.NoError(t, err)
+		assert.Zero(t, r.Games)
+		assert.Equal(t, InitialRating, r.Rating)
+	}
+}
+
+func TestForfeitedPlayersRankLast(t *testing.T) {
+	store, m, game := ratedRound(t, "alice", "bob", "carol", "dave")
+
+	// The leader forfeits and the others play the game out
+	leader := game.LastResult().Players[0]
+	require.Equal(t, 1, leader.Rank)
+	i := game.PlayerIndex(leader.ID)
+	game.Forfeited = append(game.Forfeited, game.Players[i])
+	game.Players = append(game.Players[:i], game.Players[i+1:]...)
+	game.Finish()
+	require.NoError(t, store.FinishMatch(m, game))
+
+	record, err := store.Match(m.ID())
+	require.NoError(t, err)
+	require.Len(t, record.Players, 4)
+	for _, p := range record.Players {
+		if p.ID == leader.ID {
+			assert.True(t, p.Forfeited)
+			assert.Equal(t, 4, p.Rank)
+		} else {
+			assert.False(t, p.Forfeited)
+			assert.LessOrEqual(t, p.Rank, 3)
+		}
+	}
+
+	r, err := store.Rating(leader.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, r.Games)
+	assert.Less(t, r.Rating, InitialRating, "forfeiting costs rating")
+}
+
+func TestDepartedPlayersRankLast(t *testing.T) {
+	departures := map[string]func(game *engine.Game, playerID string) error{
+		"seat taken over by a bot": services.ReplaceWithBot,
+		"cards redistributed": func(game *engine.Game, playerID string) error {
+			return services.RemovePlayer(game, playerID, models.DepartureRedistribute)
+		},
+	}
+	for name, depart := range departures {
+		t.Run(name, func(t *testing.T) {
+			store, m, game := ratedRound(t, "alice", "bob", "carol", "dave")
+
+			// The leader leaves and the game is played out without them
+			leader := game.LastResult().Players[0]
+			require.Equal(t, 1, leader.Rank)
+			require.NoError(t, depart(game, leader.ID))
+			game.Finish()
+			require.NoError(t, store.FinishMatch(m, game))
+
+			record, err := store.Match(m.ID())
+			require.NoError(t, err)
+			require.Len(t, record.Players, 4)
+			for _, p := range record.Players {
+				assert.False(t, p.Bot, p.ID)
+				if p.ID == leader.ID {
+					assert.True(t, p.Forfeited)
+					assert.Equal(t, 4, p.Rank)
+				} else {
+					assert.False(t, p.Forfeited)
+					assert.LessOrEqual(t, p.Rank, 3)
+				}
+			}
+
+			// Leaving does not stop the game being rated for anyone
+			for _, p := range record.Players {
+				r, err := store.Rating(p.ID)
+				require.NoError(t, err)
+				assert.Equal(t, 1, r.Games, p.ID)
+			}
+			r, err := store.Rating(leader.ID)
+			require.NoError(t, err)
+			assert.Less(t, r.Rating, InitialRating, "leaving costs rating")
+		})
+	}
+}
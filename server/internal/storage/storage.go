@@ -0,0 +1,195 @@
+// Package storage keeps server data that should outlive the process
+//
+// Records are opaque bytes filed under a kind (such as "tables") and a key. Callers own the encoding.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned when a record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// Store saves and loads records
+type Store interface {
+	Save(kind, key string, data []byte) error
+	Load(kind, key string) ([]byte, error)
+	// List returns the keys of every record of a kind, sorted
+	List(kind string) ([]string, error)
+	Delete(kind, key string) error
+}
+
+// FileStore keeps each record in its own file under a directory
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore creates a store in dir, creating the directory if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("storage: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Save writes the record, replacing any earlier one; a crash mid-save leaves the old record intact
+func (s *FileStore) Save(kind, key string, data []byte) error {
+	path, err := s.path(kind, key)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("storage: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+key+".*")
+	if err != nil {
+		return fmt.Errorf("storage: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("storage: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("storage: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("storage: %w", err)
+	}
+	return nil
+}
+
+// Load reads a record
+func (s *FileStore) Load(kind, key string) ([]byte, error) {
+	path, err := s.path(kind, key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: %w", err)
+	}
+	return data, nil
+}
+
+// List returns the keys of every record of a kind
+func (s *FileStore) List(kind string) ([]string, error) {
+	if err := checkName(kind); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(s.dir, kind))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: %w", err)
+	}
+
+	keys := make([]string, 0, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		keys = append(keys, strings.TrimSuffix(name, ".json"))
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete removes a record; deleting a missing record is not an error
+func (s *FileStore) Delete(kind, key string) error {
+	path, err := s.path(kind, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("storage: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) path(kind, key string) (string, error) {
+	if err := checkName(kind); err != nil {
+		return "", err
+	}
+	if err := checkName(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, kind, key+".json"), nil
+}
+
+// checkName keeps kinds and keys to plain file names
+func checkName(name string) error {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("storage: invalid name %q", name)
+	}
+	return nil
+}
+
+// MemoryStore keeps records in memory; it is meant for tests and for running without a data directory
+type MemoryStore struct {
+	records map[string]map[string][]byte
+	mu      sync.Mutex
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]map[string][]byte)}
+}
+
+// Save stores a copy of the record
+func (s *MemoryStore) Save(kind, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.records[kind] == nil {
+		s.records[kind] = make(map[string][]byte)
+	}
+	s.records[kind][key] = append([]byte(nil), data...)
+	return nil
+}
+
+// Load returns a copy of the record
+func (s *MemoryStore) Load(kind, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.records[kind][key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// List returns the keys of every record of a kind, sorted
+func (s *MemoryStore) List(kind string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.records[kind]))
+	for key := range s.records[kind] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete removes a record
+func (s *MemoryStore) Delete(kind, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records[kind], key)
+	return nil
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStore(t *testing.T, store Store) {
+	keys, err := store.List("tables")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	require.NoError(t, store.Save("tables", "BBBBBB", []byte(`{"round":2}`)))
+	require.NoError(t, store.Save("tables", "AAAAAA", []byte(`{"round":1}`)))
+	require.NoError(t, store.Save("tables", "AAAAAA", []byte(`{"round":3}`)))
+
+	data, err := store.Load("tables", "AAAAAA")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"round":3}`, string(data))
+
+	keys, err = store.List("tables")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAAAAA", "BBBBBB"}, keys)
+
+	require.NoError(t, store.Delete("tables", "AAAAAA"))
+	require.NoError(t, store.Delete("tables", "AAAAAA"))
+	_, err = store.Load("tables", "AAAAAA")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	testStore(t, store)
+
+	t.Run("rejects names that leave the directory", func(t *testing.T) {
+		assert.Error(t, store.Save("tables", "../escape", []byte("{}")))
+		assert.Error(t, store.Save("..", "key", []byte("{}")))
+		_, err := store.List("a/b")
+		assert.Error(t, err)
+	})
+
+	t.Run("records survive a new store on the same directory", func(t *testing.T) {
+		dir := t.TempDir()
+		first, err := NewFileStore(dir)
+		require.NoError(t, err)
+		require.NoError(t, first.Save("tables", "ABC123", []byte(`{}`)))
+
+		second, err := NewFileStore(dir)
+		require.NoError(t, err)
+		keys, err := second.List("tables")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ABC123"}, keys)
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
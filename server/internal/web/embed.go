@@ -0,0 +1,22 @@
+//go:build embedclient
+
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// dist holds the client build, copied here by scripts/build-embedded.sh
+//
+//go:embed all:dist
+var dist embed.FS
+
+// Client returns the embedded client build
+func Client() fs.FS {
+	files, err := fs.Sub(dist, "dist")
+	if err != nil {
+		return nil
+	}
+	return files
+}
@@ -0,0 +1,10 @@
+//go:build !embedclient
+
+package web
+
+import "io/fs"
+
+// Client returns nil; build with -tags embedclient to embed the client build
+func Client() fs.FS {
+	return nil
+}
@@ -0,0 +1,161 @@
+// Package web serves the production build of the React client
+package web
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConfigGlobal is the window property the client reads its runtime settings from
+const ConfigGlobal = "__CLEAR_THE_DECK_CONFIG__"
+
+// Options control how the client is served
+type Options struct {
+	// WebSocketURL is injected into index.html; empty lets the client connect to /ws on the host it was loaded from
+	WebSocketURL string
+}
+
+// runtimeConfig is what the client is told when index.html is served
+type runtimeConfig struct {
+	WSURL string `json:"wsUrl"`
+}
+
+// Handler serves the client build in files
+// Unknown paths without a file extension get index.html so client-side routes work on reload
+func Handler(files fs.FS, opts Options) (http.Handler, error) {
+	index, err := fs.ReadFile(files, "index.html")
+	if err != nil {
+		return nil, err
+	}
+	index, err = injectConfig(index, runtimeConfig{WSURL: opts.WebSocketURL})
+	if err != nil {
+		return nil, err
+	}
+
+	return &handler{files: files, index: index, modTime: time.Now()}, nil
+}
+
+type handler struct {
+	files   fs.FS
+	index   []byte
+	modTime time.Time
+	gzipped sync.Map // File name to compressed contents; the files never change while serving
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" || name == "index.html" {
+		h.serveIndex(w, r)
+		return
+	}
+
+	data, err := fs.ReadFile(h.files, name)
+	if err != nil {
+		// Missing assets are real 404s; anything else is a client-side route
+		if path.Ext(name) != "" {
+			http.NotFound(w, r)
+			return
+		}
+		h.serveIndex(w, r)
+		return
+	}
+
+	w.Header().Set("Cache-Control", cacheControl(name))
+	h.serveContent(w, r, name, data)
+}
+
+// serveIndex serves index.html, which must never be cached since it names the current asset hashes
+func (h *handler) serveIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+	h.serveContent(w, r, "index.html", h.index)
+}
+
+// cacheControl lets browsers keep content-hashed build output forever and revalidate everything else
+func cacheControl(name string) string {
+	if strings.HasPrefix(name, "static/") {
+		return "public, max-age=31536000, immutable"
+	}
+	return "no-cache"
+}
+
+// serveContent writes a file, gzipped when the client accepts it and the type compresses well
+func (h *handler) serveContent(w http.ResponseWriter, r *http.Request, name string, data []byte) {
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	if compressible(contentType) && acceptsGzip(r) {
+		w.Header().Set("Content-Encoding", "gzip")
+		data = h.compressed(name, data)
+	}
+	http.ServeContent(w, r, name, h.modTime, bytes.NewReader(data))
+}
+
+// compressed gzips a file once and reuses the result
+func (h *handler) compressed(name string, data []byte) []byte {
+	if gz, ok := h.gzipped.Load(name); ok {
+		return gz.([]byte)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(data)
+	gz.Close()
+
+	h.gzipped.Store(name, buf.Bytes())
+	return buf.Bytes()
+}
+
+func compressible(contentType string) bool {
+	for _, prefix := range []string{"text/", "application/javascript", "application/json", "application/wasm", "image/svg+xml"} {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		if strings.TrimSpace(strings.SplitN(enc, ";", 2)[0]) == "gzip" {
+			return true
+		}
+	}
+	return false
+}
+
+// injectConfig puts the runtime config into index.html ahead of the client's scripts
+func injectConfig(index []byte, cfg runtimeConfig) ([]byte, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// json.Marshal escapes <, > and &, so the config cannot close the script tag
+	script := []byte("<script>window." + ConfigGlobal + "=" + string(data) + ";</script>")
+
+	if i := bytes.Index(index, []byte("</head>")); i >= 0 {
+		var out bytes.Buffer
+		out.Write(index[:i])
+		out.Write(script)
+		out.Write(index[i:])
+		return out.Bytes(), nil
+	}
+	return append(script, index...), nil
+}
@@ -0,0 +1,100 @@
+package web
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var build = fstest.MapFS{
+	"index.html":             {Data: []byte(`<html><head><title>Clear the Deck</title></head><body></body></html>`)},
+	"static/js/main.1a2b.js": {Data: []byte(`console.log("clear the deck")`)},
+	"rules.wasm":             {Data: []byte{0, 'a', 's', 'm'}},
+}
+
+func serve(t *testing.T, opts Options, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	h, err := Handler(build, opts)
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("injects the WebSocket URL into index.html", func(t *testing.T) {
+		rec := serve(t, Options{WebSocketURL: "wss://cards.example/ws"}, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+		assert.Contains(t, rec.Body.String(), `<script>window.__CLEAR_THE_DECK_CONFIG__={"wsUrl":"wss://cards.example/ws"};</script></head>`)
+	})
+
+	t.Run("escapes the injected config", func(t *testing.T) {
+		rec := serve(t, Options{WebSocketURL: "</script><script>alert(1)"}, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.NotContains(t, rec.Body.String(), "</script><script>alert")
+	})
+
+	t.Run("falls back to index.html for client routes", func(t *testing.T) {
+		rec := serve(t, Options{}, httptest.NewRequest(http.MethodGet, "/rooms/ABC123", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "<title>Clear the Deck</title>")
+		assert.Contains(t, rec.Body.String(), `{"wsUrl":""}`)
+	})
+
+	t.Run("missing assets are not found", func(t *testing.T) {
+		rec := serve(t, Options{}, httptest.NewRequest(http.MethodGet, "/static/js/missing.js", nil))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("hashed assets are cached for good", func(t *testing.T) {
+		rec := serve(t, Options{}, httptest.NewRequest(http.MethodGet, "/static/js/main.1a2b.js", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+		assert.Contains(t, rec.Header().Get("Content-Type"), "javascript")
+		assert.Equal(t, `console.log("clear the deck")`, rec.Body.String())
+	})
+
+	t.Run("unhashed files are revalidated", func(t *testing.T) {
+		rec := serve(t, Options{}, httptest.NewRequest(http.MethodGet, "/rules.wasm", nil))
+
+		assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+		assert.Equal(t, "application/wasm", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("gzips when the client accepts it", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/static/js/main.1a2b.js", nil)
+		req.Header.Set("Accept-Encoding", "br, gzip;q=0.8")
+
+		rec := serve(t, Options{}, req)
+
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+		gz, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(gz)
+		require.NoError(t, err)
+		assert.Equal(t, `console.log("clear the deck")`, string(body))
+	})
+
+	t.Run("rejects writes", func(t *testing.T) {
+		rec := serve(t, Options{}, httptest.NewRequest(http.MethodPost, "/", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+
+	t.Run("needs an index.html", func(t *testing.T) {
+		_, err := Handler(fstest.MapFS{}, Options{})
+		assert.Error(t, err)
+	})
+}
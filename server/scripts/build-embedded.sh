@@ -0,0 +1,15 @@
+#!/bin/sh
+# Builds a single server binary with the production client embedded
+set -e
+
+SERVER="$(cd "$(dirname "$0")/.." && pwd)"
+CLIENT="$SERVER/../client"
+
+(cd "$CLIENT" && npm run build:wasm && npm run build)
+
+rm -rf "$SERVER/internal/web/dist"
+cp -R "$CLIENT/build" "$SERVER/internal/web/dist"
+
+cd "$SERVER"
+go build -tags embedclient -o bin/clearthedeck ./cmd
+echo "Built $SERVER/bin/clearthedeck"